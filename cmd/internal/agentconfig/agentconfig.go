@@ -0,0 +1,165 @@
+// Package agentconfig has the command line flags for the SNMP agent that the
+// commands talk to. The agent is a dynamicRegistration entry in the plugin
+// configuration, and a flag overrides each key of the entry, so the commands
+// use the same credentials as the plugin.
+package agentconfig
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	logger "github.com/Sirupsen/logrus"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/smi"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultConfigFile is the plugin configuration file.
+const DefaultConfigFile = "config.yml"
+
+// DefaultTimeout is the timeout of each SNMP request if neither the flag nor
+// the configuration has one.
+const DefaultTimeout = 5 * time.Second
+
+// overrides are the flags for the dynamicRegistration keys.
+var overrides = []struct {
+	flag  string
+	key   string
+	usage string
+}{
+	{"version", "version", "SNMP version, v3."},
+	{"endpoint", "endpoint", "SNMP agent host."},
+	{"port", "port", "SNMP agent UDP port."},
+	{"user", "userName", "SNMPv3 user name."},
+	{"auth-protocol", "authenticationProtocol", "SNMPv3 authentication protocol, MD5 or SHA."},
+	{"auth-passphrase", "authenticationPassphrase", "SNMPv3 authentication passphrase."},
+	{"priv-protocol", "privacyProtocol", "SNMPv3 privacy protocol, DES or AES."},
+	{"priv-passphrase", "privacyPassphrase", "SNMPv3 privacy passphrase."},
+	{"context", "contextName", "SNMPv3 context name."},
+	{"model", "model", "Device model, for example PXGMS UPS + EATON 93PM."},
+}
+
+// Flags are the agent flags of a command.
+type Flags struct {
+	configFile *string
+	device     *int
+	timeout    *time.Duration
+	mibDir     *string
+	values     map[string]*string // By dynamicRegistration key.
+}
+
+// Register registers the agent flags.
+func Register(flags *flag.FlagSet) *Flags {
+	f := &Flags{
+		configFile: flags.String("config", DefaultConfigFile, "Plugin configuration file with the agent in dynamicRegistration."),
+		device:     flags.Int("device", 0, "Index of the dynamicRegistration entry of the agent."),
+		timeout:    flags.Duration("timeout", 0, fmt.Sprintf("Timeout of each SNMP request. Overrides the configuration. Default %v.", DefaultTimeout)),
+		mibDir:     flags.String("mibs", "", "Directory of MIB files for symbolic OIDs."),
+		values:     map[string]*string{},
+	}
+	for _, override := range overrides {
+		f.values[override.key] = flags.String(override.flag, "", override.usage+" Overrides the configuration.")
+	}
+	return f
+}
+
+// Data is the dynamicRegistration entry of the agent with the flags applied.
+// A missing default configuration file is not an error, so the agent can be
+// given with flags only.
+func (f *Flags) Data() (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+
+	entries, err := LoadDynamicRegistration(*f.configFile)
+	if err != nil && !(os.IsNotExist(err) && *f.configFile == DefaultConfigFile) {
+		return nil, err
+	}
+	if err == nil {
+		if *f.device < 0 || *f.device >= len(entries) {
+			return nil, fmt.Errorf("%v has %d dynamicRegistration entries, no entry %d",
+				*f.configFile, len(entries), *f.device)
+		}
+		for key, value := range entries[*f.device] {
+			data[key] = value
+		}
+	}
+
+	for key, value := range f.values {
+		if *value == "" {
+			continue
+		}
+		if key == "port" {
+			port, err := strconv.ParseUint(*value, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("Bad port %v: %v", *value, err)
+			}
+			data[key] = int(port)
+			continue
+		}
+		data[key] = *value
+	}
+
+	if *f.timeout != 0 {
+		data[core.TimeoutKey] = f.timeout.String()
+	} else if _, ok := data[core.TimeoutKey]; !ok {
+		data[core.TimeoutKey] = DefaultTimeout.String()
+	}
+	return data, nil
+}
+
+// DeviceConfig is the configuration of the agent.
+func (f *Flags) DeviceConfig() (*core.DeviceConfig, error) {
+	data, err := f.Data()
+	if err != nil {
+		return nil, err
+	}
+	return core.GetDeviceConfig(data)
+}
+
+// Client creates an SnmpClient for the agent and loads the MIBs if there are
+// any.
+func (f *Flags) Client() (*core.SnmpClient, error) {
+	f.LoadMibs()
+	config, err := f.DeviceConfig()
+	if err != nil {
+		return nil, err
+	}
+	return core.NewSnmpClient(config)
+}
+
+// LoadMibs loads the MIBs in the MIB directory flag, if it is set, for
+// symbolic OIDs.
+func (f *Flags) LoadMibs() {
+	if *f.mibDir == "" {
+		return
+	}
+	mibs := smi.NewMibs()
+	if err := mibs.LoadDir(*f.mibDir); err != nil {
+		logger.Warnf("Unable to load all MIBs: %v", err)
+	}
+	core.SetOidResolver(mibs)
+}
+
+// pluginConfig is the part of the plugin configuration with the agents.
+type pluginConfig struct {
+	DynamicRegistration struct {
+		Config []map[string]interface{} `yaml:"config"`
+	} `yaml:"dynamicRegistration"`
+}
+
+// LoadDynamicRegistration loads the dynamicRegistration entries of a plugin
+// configuration file.
+func LoadDynamicRegistration(path string) ([]map[string]interface{}, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := pluginConfig{}
+	if err = yaml.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("Failed to parse %v: %v", path, err)
+	}
+	return config.DynamicRegistration.Config, nil
+}
@@ -0,0 +1,84 @@
+// snmp-record records the data of a live SNMP agent as snmpwalk output that
+// the emulator, the simulator and the tests replay. It uses the plugin's
+// configuration for the agent and credentials.
+//
+//	snmp-record -config config.yml -output emulator/data/customer.snmpwalk \
+//	    -mibs mibs -root UPS-MIB::upsMIB -root SNMPv2-MIB::system -redact-all
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	logger "github.com/Sirupsen/logrus"
+
+	"github.com/vapor-ware/synse-snmp-plugin/cmd/internal/agentconfig"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/record"
+)
+
+// listFlag is a flag that may be given more than once or comma separated.
+type listFlag []string
+
+func (list *listFlag) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *listFlag) Set(value string) error {
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			*list = append(*list, entry)
+		}
+	}
+	return nil
+}
+
+func main() {
+	agent := agentconfig.Register(flag.CommandLine)
+	output := flag.String("output", "-", "snmpwalk file to write. - is standard output.")
+	var roots, redactOids listFlag
+	flag.Var(&roots, "root", "OID to record the tree under. May be repeated. The default is the whole tree, "+record.DefaultRoot+".")
+	flag.Var(&redactOids, "redact-oid", "OID whose values are redacted. May be repeated.")
+	redactSerials := flag.Bool("redact-serials", false, "Redact serial numbers and asset IDs.")
+	redactNames := flag.Bool("redact-names", false, "Redact system, UPS and entity names, contacts and locations.")
+	redactAddresses := flag.Bool("redact-addresses", false, "Redact IPv4 and MAC addresses, including in table indexes and text.")
+	redactAll := flag.Bool("redact-all", false, "Redact serial numbers, names and addresses.")
+	flag.Parse()
+
+	client, err := agent.Client()
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	results, err := record.Walk(client, roots)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	redaction := record.Redaction{
+		Serials:   *redactSerials || *redactAll,
+		Names:     *redactNames || *redactAll,
+		Addresses: *redactAddresses || *redactAll,
+		Oids:      redactOids,
+	}
+	if redaction.Serials || redaction.Names || redaction.Addresses || len(redaction.Oids) > 0 {
+		if results, err = record.Redact(results, redaction); err != nil {
+			logger.Fatal(err)
+		}
+	}
+
+	var writer io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		defer file.Close() // nolint: errcheck
+		writer = file
+	}
+	if err = record.Write(writer, results); err != nil {
+		logger.Fatal(err)
+	}
+	logger.Infof("Recorded %d OIDs to %v", len(results), *output)
+}
@@ -0,0 +1,119 @@
+// snmp-sim is an SNMP agent simulator for testing the plugin end to end. It
+// serves snmpwalk and snmprec files over SNMPv1, SNMPv2c and SNMPv3, accepts
+// SET on writable objects and runs scenarios such as a utility power loss.
+//
+//	snmp-sim -data emulator/data -listen 127.0.0.1:1024 \
+//	    -mibs mibs -scenario emulator/scenarios/power-loss.yml \
+//	    -trap-targets 127.0.0.1:1162
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/simulator"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/smi"
+)
+
+func main() {
+	dataDir := flag.String("data", "emulator/data", "Directory of snmpwalk and snmprec files. The file name is the context name and community.")
+	listen := flag.String("listen", "0.0.0.0:1024", "UDP address to listen on.")
+	usersFile := flag.String("users", "", "YAML file of SNMPv3 users. The default is the emulator user.")
+	scenarioFile := flag.String("scenario", "", "YAML scenario to run.")
+	mibDir := flag.String("mibs", "", "Directory of MIB files. Enables symbolic OIDs and makes read-write objects writable.")
+	writable := flag.String("writable", "", "Comma separated OIDs that accept SET, in addition to the MIB read-write objects.")
+	trapTargets := flag.String("trap-targets", "", "Comma separated host:port addresses that get traps.")
+	trapCommunity := flag.String("trap-community", simulator.DefaultCommunity, "Community of the traps.")
+	debug := flag.Bool("debug", false, "Log dropped requests.")
+	flag.Parse()
+
+	if *debug {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	data, err := core.LoadDataDir(*dataDir)
+	if err != nil {
+		log.Fatalf("Failed to load data: %v", err)
+	}
+
+	config := simulator.Config{
+		Data:          data,
+		Users:         []simulator.User{simulator.DefaultUser},
+		Writable:      splitList(*writable),
+		TrapTargets:   splitList(*trapTargets),
+		TrapCommunity: *trapCommunity,
+	}
+	if *usersFile != "" {
+		if config.Users, err = simulator.LoadUsers(*usersFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *mibDir != "" {
+		config.Writable = append(config.Writable, loadMibs(*mibDir)...)
+	}
+
+	agent, err := simulator.NewAgent(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = agent.Listen(*listen); err != nil {
+		log.Fatal(err)
+	}
+
+	if *scenarioFile != "" {
+		scenario, err := simulator.LoadScenario(*scenarioFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go func() {
+			if err := scenario.Run(agent); err != nil {
+				log.Error(err)
+				return
+			}
+			log.Infof("Scenario %v done", scenario.Name)
+		}()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+	if err = agent.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadMibs loads the MIBs for symbolic OIDs and returns the OIDs of the
+// read-write and read-create objects.
+func loadMibs(dir string) (writable []string) {
+	mibs := smi.NewMibs()
+	if err := mibs.LoadDir(dir); err != nil {
+		log.Warnf("Unable to load all MIBs: %v", err)
+	}
+	core.SetOidResolver(mibs)
+
+	for _, moduleName := range mibs.ModuleNames() {
+		for _, node := range mibs.Module(moduleName).Nodes {
+			if node.Oid != nil && (node.Access == "read-write" || node.Access == "read-create") {
+				writable = append(writable, node.Oid.ToString)
+			}
+		}
+	}
+	log.Infof("Loaded MIB modules %v, %d writable objects", mibs.ModuleNames(), len(writable))
+	return writable
+}
+
+// splitList splits a comma separated list, dropping empty entries.
+func splitList(list string) (result []string) {
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
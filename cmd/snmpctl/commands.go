@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/vapor-ware/synse-snmp-plugin/cmd/internal/agentconfig"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/mibs/ups_mib"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/servers"
+)
+
+// resultColumns are the columns of get and walk.
+var resultColumns = []string{"oid", "type", "value"}
+
+// resultRow is the get and walk row for a result.
+func resultRow(result core.ReadResult) []interface{} {
+	return []interface{}{core.OidName(result.Oid), result.TypeName(), value(&result)}
+}
+
+// get gets each OID.
+func get(agent *agentconfig.Flags, format string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("No OIDs to get")
+	}
+	client, err := agent.Client()
+	if err != nil {
+		return err
+	}
+
+	var rows [][]interface{}
+	for _, oid := range args {
+		result, err := client.Get(oid)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, resultRow(result))
+	}
+	return writeTable(os.Stdout, format, resultColumns, rows)
+}
+
+// walk walks the tree under an OID.
+func walk(agent *agentconfig.Flags, format string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Expected one OID to walk, got %d", len(args))
+	}
+	client, err := agent.Client()
+	if err != nil {
+		return err
+	}
+
+	results, err := client.Walk(args[0])
+	if err != nil {
+		return err
+	}
+	rows := make([][]interface{}, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, resultRow(result))
+	}
+	return writeTable(os.Stdout, format, resultColumns, rows)
+}
+
+// table prints an UPS-MIB table by name, or lists the tables without a name.
+func table(agent *agentconfig.Flags, format string, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("Expected one table, got %d", len(args))
+	}
+	client, err := agent.Client()
+	if err != nil {
+		return err
+	}
+	server, err := core.NewSnmpServerBase(client, client.DeviceConfig)
+	if err != nil {
+		return err
+	}
+	upsMib, err := mibs.NewUpsMib(server)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		var rows [][]interface{}
+		for _, snmpTable := range upsMib.Tables {
+			rows = append(rows, []interface{}{
+				snmpTable.Name, core.OidName(snmpTable.WalkOid), len(snmpTable.Rows)})
+		}
+		return writeTable(os.Stdout, format, []string{"table", "oid", "rows"}, rows)
+	}
+
+	for _, snmpTable := range upsMib.Tables {
+		if !strings.EqualFold(snmpTable.Name, args[0]) {
+			continue
+		}
+		rows := make([][]interface{}, 0, len(snmpTable.Rows))
+		for _, row := range snmpTable.Rows {
+			cells := make([]interface{}, len(row.RowData))
+			for i, result := range row.RowData {
+				cells[i] = value(result)
+			}
+			rows = append(rows, cells)
+		}
+		return writeTable(os.Stdout, format, snmpTable.ColumnList, rows)
+	}
+	return fmt.Errorf("No table %v in %v. Run snmpctl table to list the tables", args[0], upsMib.Name)
+}
+
+// enumerate prints the devices the plugin would register, in sort order.
+func enumerate(agent *agentconfig.Flags, format string, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("Unexpected arguments %v", args)
+	}
+	agent.LoadMibs()
+	data, err := agent.Data()
+	if err != nil {
+		return err
+	}
+
+	deviceConfigs, err := servers.EnumerateDevices(data)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]interface{}
+	for _, deviceConfig := range deviceConfigs {
+		for _, kind := range deviceConfig.Devices {
+			for _, instance := range kind.Instances {
+				oid, ok := instance.Data["oid"]
+				if !ok {
+					oid, ok = instance.Data["alarm_oid"] // An alarm is on the device with this OID.
+				}
+				if !ok {
+					oid, ok = instance.Data["expression"] // A computed device.
+				}
+				if !ok {
+					oid, _ = core.RowSortOid(instance.Data) // A row device sorts by its first column.
+				}
+				rows = append(rows, []interface{}{
+					instance.SortOrdinal,
+					kind.Name,
+					instance.Info,
+					core.OidName(fmt.Sprint(oid)),
+					instance.Data["multiplier"],
+					instance.Data["enumeration"],
+				})
+			}
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i][0].(int32) < rows[j][0].(int32) })
+	return writeTable(os.Stdout, format,
+		[]string{"ordinal", "kind", "info", "oid", "multiplier", "enumeration"}, rows)
+}
+
+// export writes the devices the plugin would register as a device config file.
+// The devices refer to the agent instead of holding its credentials.
+func export(agent *agentconfig.Flags, format string, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("Expected one device config file, got %d", len(args))
+	}
+	agent.LoadMibs()
+	data, err := agent.Data()
+	if err != nil {
+		return err
+	}
+
+	deviceConfigs, err := servers.EnumerateDevices(data)
+	if err != nil {
+		return err
+	}
+	agentID := core.AgentID(data)
+
+	writer := os.Stdout
+	if len(args) == 1 {
+		writer, err = os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		defer writer.Close() // nolint: errcheck
+	}
+	_, err = fmt.Fprintf(writer, exportHeader, agentID, core.AgentKey, agentID, core.EnumerateKey)
+	if err != nil {
+		return err
+	}
+	return core.WriteDeviceConfig(writer, core.ExportDeviceConfigs(deviceConfigs, agentID))
+}
+
+// exportHeader is the comment at the top of exported device config files.
+const exportHeader = `# SNMP devices of agent %v, exported by snmpctl export.
+# The agent is in dynamicRegistration in the plugin configuration, with
+#   %v: %v
+#   %v: false
+# so that the plugin registers the agent but does not enumerate these devices again.
+`
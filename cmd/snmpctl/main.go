@@ -0,0 +1,82 @@
+// snmpctl exercises the plugin's SNMP code against an agent without running
+// the plugin. It takes the agent from a dynamicRegistration entry in the
+// plugin configuration, or from flags.
+//
+//	snmpctl get -mibs mibs UPS-MIB::upsBatteryStatus.0
+//	snmpctl walk -mibs mibs UPS-MIB::upsInput
+//	snmpctl table -format csv UPS-MIB-UPS-Output-Table
+//	snmpctl enumerate -config config.yml -device 0
+//	snmpctl export -config config.yml config/device/ups.yml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	logger "github.com/Sirupsen/logrus"
+
+	"github.com/vapor-ware/synse-snmp-plugin/cmd/internal/agentconfig"
+)
+
+// command is an snmpctl subcommand.
+type command struct {
+	name        string
+	args        string
+	description string
+	run         func(agent *agentconfig.Flags, format string, args []string) error
+}
+
+var commands = []command{
+	{"get", "OID...", "Get OIDs.", get},
+	{"walk", "OID", "Walk the tree under an OID.", walk},
+	{"table", "[TABLE]", "Print an SNMP table of the UPS-MIB, or list the tables.", table},
+	{"enumerate", "", "Enumerate the synse devices like the plugin, without registering them.", enumerate},
+	{"export", "[FILE]", "Write the enumerated devices as a device config file, to standard output without FILE.", export},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: snmpctl COMMAND [FLAGS] [ARGS]\n\nCommands:\n") // nolint: errcheck
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10v %v\n", c.name, c.description) // nolint: errcheck
+	}
+	fmt.Fprintf(os.Stderr, "\nRun snmpctl COMMAND -h for the flags of a command.\n") // nolint: errcheck
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+		flags := flag.NewFlagSet(c.name, flag.ExitOnError)
+		agent := agentconfig.Register(flags)
+		format := flags.String("format", formatText, "Output format: text, csv or json.")
+		debug := flags.Bool("debug", false, "Log debug messages.")
+		flags.Usage = func() {
+			fmt.Fprintf(os.Stderr, "Usage: snmpctl %v [FLAGS] %v\n\n%v\n\n", c.name, c.args, c.description) // nolint: errcheck
+			flags.PrintDefaults()
+		}
+		flags.Parse(os.Args[2:]) // nolint: errcheck
+
+		logger.SetOutput(os.Stderr)
+		logger.SetLevel(logger.WarnLevel)
+		if *debug {
+			logger.SetLevel(logger.DebugLevel)
+		}
+		if err := checkFormat(*format); err != nil {
+			logger.Fatal(err)
+		}
+		if err := c.run(agent, *format, flags.Args()); err != nil {
+			logger.Fatal(err)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
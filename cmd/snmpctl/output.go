@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/soniah/gosnmp"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// Output formats.
+const (
+	formatText = "text"
+	formatCSV  = "csv"
+	formatJSON = "json"
+)
+
+// checkFormat checks that the output format is supported.
+func checkFormat(format string) error {
+	switch format {
+	case formatText, formatCSV, formatJSON:
+		return nil
+	}
+	return fmt.Errorf("Unsupported format %v, expected text, csv or json", format)
+}
+
+// writeTable writes rows under the column names. text is aligned columns, csv
+// has a header line and json is a list of objects keyed by column name.
+func writeTable(writer io.Writer, format string, columns []string, rows [][]interface{}) error {
+	switch format {
+	case formatCSV:
+		w := csv.NewWriter(writer)
+		if err := w.Write(columns); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := w.Write(cellTexts(row)); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+
+	case formatJSON:
+		objects := make([]map[string]interface{}, 0, len(rows))
+		for _, row := range rows {
+			object := map[string]interface{}{}
+			for i, column := range columns {
+				object[column] = row[i]
+			}
+			objects = append(objects, object)
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(objects)
+
+	default:
+		w := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(columns, "\t")) // nolint: errcheck
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(cellTexts(row), "\t")) // nolint: errcheck
+		}
+		return w.Flush()
+	}
+}
+
+// cellTexts formats the cells of a row as text. Missing values are empty.
+func cellTexts(row []interface{}) []string {
+	texts := make([]string, len(row))
+	for i, cell := range row {
+		if cell != nil {
+			texts[i] = fmt.Sprint(cell)
+		}
+	}
+	return texts
+}
+
+// value is a cell for a ReadResult. Numbers stay numbers so that they are
+// numbers in json. Null values are nil.
+func value(result *core.ReadResult) interface{} {
+	if result == nil || result.IsNull() {
+		return nil
+	}
+	switch result.Type {
+	case gosnmp.Integer:
+		if i, err := result.Int64(); err == nil {
+			return i
+		}
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.Counter64, gosnmp.TimeTicks, gosnmp.Uinteger32:
+		if u, err := result.Uint64(); err == nil {
+			return u
+		}
+	}
+	return result.Format()
+}
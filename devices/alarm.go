@@ -0,0 +1,313 @@
+package devices
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// This file contains support for alarm devices. An alarm device has a
+// threshold rule on the numeric value of another device, and reports ok,
+// warning or critical. It does not read the agent. The numeric handlers
+// record each value they read, and the alarm evaluates the latest one. A
+// device may have several alarms with different names.
+//
+// Alarm device data:
+//
+//	alarm_oid: .1.3.6.1.2.1.33.1.2.4.0 # OID of the device with the value.
+//	alarm_name: batteryLow             # Name of the alarm. Default the device info.
+//	condition: below                   # above (default) or below.
+//	warning: 40                        # Warning threshold. Optional.
+//	critical: 20                       # Critical threshold. Optional.
+//	hysteresis: 2                      # Margin to clear a status. Default 0.
+//	hold: 30s                          # Time a new status must last. Default 0.
+//	stale: 1m                          # Maximum age of the value. Default 30s.
+
+const (
+	// alarmOk is the status of a value within the thresholds.
+	alarmOk = "ok"
+	// alarmWarning is the status of a value beyond the warning threshold.
+	alarmWarning = "warning"
+	// alarmCritical is the status of a value beyond the critical threshold.
+	alarmCritical = "critical"
+
+	// defaultAlarmStale is the maximum age of the value of an alarm, ten read
+	// intervals of the 3s in config.yml. Alarms need a longer stale setting
+	// for a longer read interval.
+	defaultAlarmStale = 30 * time.Second
+)
+
+// SnmpAlarm is the handler for alarm devices.
+var SnmpAlarm = sdk.DeviceHandler{
+	Name: "alarm",
+	Read: SnmpAlarmRead,
+}
+
+// SnmpAlarmRead is the read handler function for alarm devices. There is no
+// reading until the device with the value has been read, and an error once
+// its latest value is older than the stale limit of the alarm, such as when
+// the agent stops responding.
+func SnmpAlarmRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
+
+	// Arg checks.
+	if device == nil {
+		return nil, fmt.Errorf("device is nil")
+	}
+
+	data := device.Data
+	rule, err := parseAlarmRule(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The client identifies the agent. It makes no request.
+	snmpClient, err := core.GetClient(data)
+	if err != nil {
+		return nil, err
+	}
+	key := valueKey(snmpClient, fmt.Sprint(data["alarm_oid"]))
+	sample, ok := values.Latest(key)
+	if !ok {
+		return []*sdk.Reading{}, nil
+	}
+	if age := time.Since(sample.Time); age > rule.Stale {
+		return nil, fmt.Errorf("alarm value of %v is stale, read %v ago",
+			core.OidDisplay(fmt.Sprint(data["alarm_oid"])), age.Round(time.Second))
+	}
+	name := AlarmName(data)
+	if name == "" {
+		name = device.Info
+	}
+	status := alarms.Update(key+"/"+name, rule, sample)
+
+	// Create the reading.
+	reading, err := device.GetOutput("status").MakeReading(status)
+	if err != nil {
+		return nil, err
+	}
+	readings = []*sdk.Reading{reading}
+	return readings, nil
+}
+
+// CheckAlarm checks the threshold rule in alarm device data, so that a bad
+// rule fails enumeration rather than every read.
+func CheckAlarm(data map[string]interface{}) error {
+	_, err := parseAlarmRule(data)
+	return err
+}
+
+// AlarmName returns the name of the alarm in alarm device data, or an empty
+// string if it has none.
+func AlarmName(data map[string]interface{}) string {
+	if name, ok := data["alarm_name"]; ok {
+		return fmt.Sprint(name)
+	}
+	return ""
+}
+
+// alarmRule is the threshold rule of an alarm device.
+type alarmRule struct {
+	Below      bool          // Alarm below the thresholds rather than above.
+	Warning    *float64      // Warning threshold, or nil.
+	Critical   *float64      // Critical threshold, or nil.
+	Hysteresis float64       // Margin past a threshold to clear its status.
+	Hold       time.Duration // Time a new status must last before it is reported.
+	Stale      time.Duration // Maximum age of the value the alarm evaluates.
+}
+
+// parseAlarmRule parses the threshold rule in alarm device data.
+func parseAlarmRule(data map[string]interface{}) (rule alarmRule, err error) {
+	switch condition := fmt.Sprint(data["condition"]); condition {
+	case "above", "<nil>":
+	case "below":
+		rule.Below = true
+	default:
+		return rule, fmt.Errorf("alarm condition should be above or below, got %v", condition)
+	}
+
+	for key, threshold := range map[string]**float64{"warning": &rule.Warning, "critical": &rule.Critical} {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		f, err := toFloat64(value)
+		if err != nil {
+			return rule, fmt.Errorf("alarm %v: %v", key, err)
+		}
+		*threshold = &f
+	}
+	if rule.Warning == nil && rule.Critical == nil {
+		return rule, fmt.Errorf("alarm needs a warning or critical threshold")
+	}
+
+	if value, ok := data["hysteresis"]; ok {
+		if rule.Hysteresis, err = toFloat64(value); err != nil {
+			return rule, fmt.Errorf("alarm hysteresis: %v", err)
+		}
+		if rule.Hysteresis < 0 {
+			return rule, fmt.Errorf("alarm hysteresis should not be negative, got %v", rule.Hysteresis)
+		}
+	}
+	if value, ok := data["hold"]; ok {
+		if rule.Hold, err = time.ParseDuration(fmt.Sprint(value)); err != nil {
+			return rule, fmt.Errorf("alarm hold: %v", err)
+		}
+	}
+	rule.Stale = defaultAlarmStale
+	if value, ok := data["stale"]; ok {
+		if rule.Stale, err = time.ParseDuration(fmt.Sprint(value)); err != nil {
+			return rule, fmt.Errorf("alarm stale: %v", err)
+		}
+		if rule.Stale <= 0 {
+			return rule, fmt.Errorf("alarm stale should be positive, got %v", rule.Stale)
+		}
+	}
+	return rule, nil
+}
+
+// beyond returns true if the value is past the threshold. A status that is
+// active clears only once the value is back past the threshold by the
+// hysteresis, so that a value near the threshold does not flap.
+func (rule alarmRule) beyond(value float64, threshold float64, active bool) bool {
+	margin := 0.0
+	if active {
+		margin = rule.Hysteresis
+	}
+	if rule.Below {
+		return value < threshold+margin
+	}
+	return value > threshold-margin
+}
+
+// Status returns the status of a value given the current status.
+func (rule alarmRule) Status(value float64, current string) string {
+	if rule.Critical != nil && rule.beyond(value, *rule.Critical, current == alarmCritical) {
+		return alarmCritical
+	}
+	if rule.Warning != nil && rule.beyond(value, *rule.Warning, current != alarmOk) {
+		return alarmWarning
+	}
+	return alarmOk
+}
+
+// valueSample is a numeric value read by a device handler.
+type valueSample struct {
+	Value float64   // Value after any counter translation and multiplier.
+	Time  time.Time // Local time the value was read.
+}
+
+// valueTracker keeps the latest value of each numeric device.
+type valueTracker struct {
+	mutex   sync.Mutex
+	samples map[string]valueSample // Keyed by agent and numeric OID.
+}
+
+// values is the process wide valueTracker updated by the read handlers.
+var values = newValueTracker()
+
+// newValueTracker creates an empty valueTracker.
+func newValueTracker() *valueTracker {
+	return &valueTracker{
+		samples: map[string]valueSample{},
+	}
+}
+
+// Record stores the latest value at key.
+func (tracker *valueTracker) Record(key string, sample valueSample) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.samples[key] = sample
+}
+
+// Latest returns the latest value at key. ok is false if there is none.
+func (tracker *valueTracker) Latest(key string) (sample valueSample, ok bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	sample, ok = tracker.samples[key]
+	return sample, ok
+}
+
+// valueKey uniquely identifies the value of an OID across agents. Symbolic
+// and numeric forms of the same OID have the same key.
+func valueKey(client *core.SnmpClient, oid string) string {
+	if numericOid, err := core.ResolveOid(oid); err == nil {
+		oid = numericOid
+	}
+	config := client.DeviceConfig
+	return fmt.Sprintf("%v:%d/%v%v", config.Endpoint, config.Port, config.ContextName, oid)
+}
+
+// recordValue records a numeric value read for the device data, for the
+// alarms on it.
+func recordValue(client *core.SnmpClient, data map[string]interface{}, value float64) {
+	values.Record(valueKey(client, fmt.Sprint(data["oid"])), valueSample{Value: value, Time: time.Now()})
+}
+
+// alarmState is the status of an alarm and the status it is changing to.
+type alarmState struct {
+	Status  string    // Reported status.
+	Pending string    // Status waiting out the hold time, or empty.
+	Since   time.Time // Time of the first sample with the pending status.
+}
+
+// alarmTracker keeps the state of each alarm.
+type alarmTracker struct {
+	mutex  sync.Mutex
+	states map[string]*alarmState // Keyed by agent, numeric OID and alarm name.
+}
+
+// alarms is the process wide alarmTracker used by the alarm handler.
+var alarms = newAlarmTracker()
+
+// newAlarmTracker creates an empty alarmTracker.
+func newAlarmTracker() *alarmTracker {
+	return &alarmTracker{
+		states: map[string]*alarmState{},
+	}
+}
+
+// Update evaluates the rule of the alarm at key on a sample and returns the
+// status to report. A new status, raised or cleared, is reported once the
+// samples have had it for the hold time of the rule.
+func (tracker *alarmTracker) Update(key string, rule alarmRule, sample valueSample) string {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	state, ok := tracker.states[key]
+	if !ok {
+		state = &alarmState{Status: alarmOk}
+		tracker.states[key] = state
+	}
+
+	status := rule.Status(sample.Value, state.Status)
+	if status == state.Status {
+		state.Pending = ""
+		return state.Status
+	}
+	if status != state.Pending {
+		state.Pending = status
+		state.Since = sample.Time
+	}
+	if sample.Time.Sub(state.Since) >= rule.Hold {
+		state.Status = status
+		state.Pending = ""
+	}
+	return state.Status
+}
+
+// toFloat64 converts a number from YAML or enumerated device data to a
+// float64.
+func toFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	}
+	return 0, fmt.Errorf("expected a number, got %T %v", value, value)
+}
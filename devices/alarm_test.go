@@ -0,0 +1,301 @@
+package devices
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/outputs"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestAlarmRuleStatus checks thresholds above and below with hysteresis.
+func TestAlarmRuleStatus(t *testing.T) {
+	above, err := parseAlarmRule(map[string]interface{}{"warning": 80, "critical": 90.0, "hysteresis": 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	below, err := parseAlarmRule(map[string]interface{}{"condition": "below", "warning": 40, "hysteresis": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		rule     alarmRule
+		value    float64
+		current  string
+		expected string
+	}{
+		{above, 50, alarmOk, alarmOk},
+		{above, 85, alarmOk, alarmWarning},
+		{above, 95, alarmOk, alarmCritical},
+		{above, 88, alarmCritical, alarmCritical}, // Within the hysteresis.
+		{above, 84, alarmCritical, alarmWarning},
+		{above, 76, alarmWarning, alarmWarning},
+		{above, 75, alarmWarning, alarmOk},
+		{below, 39, alarmOk, alarmWarning},
+		{below, 41, alarmWarning, alarmWarning},
+		{below, 42, alarmWarning, alarmOk},
+	}
+	for i, c := range cases {
+		if status := c.rule.Status(c.value, c.current); status != c.expected {
+			t.Fatalf("case %d: expected %v, got %v", i, c.expected, status)
+		}
+	}
+}
+
+// TestAlarmRuleErrors checks bad rules.
+func TestAlarmRuleErrors(t *testing.T) {
+	cases := []map[string]interface{}{
+		{},
+		{"warning": "high"},
+		{"warning": 1, "condition": "equal"},
+		{"warning": 1, "hysteresis": -1},
+		{"warning": 1, "hold": "soon"},
+		{"warning": 1, "stale": "later"},
+		{"warning": 1, "stale": "0s"},
+	}
+	for i, data := range cases {
+		if err := CheckAlarm(data); err == nil {
+			t.Fatalf("case %d: expected an error for %v", i, data)
+		}
+	}
+}
+
+// TestAlarmHold checks that a new status is reported after the hold time.
+func TestAlarmHold(t *testing.T) {
+	rule, err := parseAlarmRule(map[string]interface{}{"critical": 35, "hold": "30s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := newAlarmTracker()
+	start := time.Now()
+	update := func(value float64, seconds int) string {
+		return tracker.Update("a", rule, valueSample{Value: value, Time: start.Add(time.Duration(seconds) * time.Second)})
+	}
+
+	if status := update(40, 0); status != alarmOk {
+		t.Fatalf("Expected ok before the hold time, got %v", status)
+	}
+	if status := update(36, 20); status != alarmOk {
+		t.Fatalf("Expected ok before the hold time, got %v", status)
+	}
+	if status := update(37, 30); status != alarmCritical {
+		t.Fatalf("Expected critical after the hold time, got %v", status)
+	}
+	// A short dip does not clear it.
+	update(30, 40)
+	if status := update(38, 50); status != alarmCritical {
+		t.Fatalf("Expected critical after a short dip, got %v", status)
+	}
+	update(30, 60)
+	if status := update(30, 90); status != alarmOk {
+		t.Fatalf("Expected ok after the hold time, got %v", status)
+	}
+}
+
+// TestAlarmRead evaluates an alarm on the value that a raw device read.
+func TestAlarmRead(t *testing.T) {
+	agent := emulator.AgentData()
+	alarmData, err := core.MergeMapStringInterface(agent, map[string]interface{}{
+		"alarm_oid": ".1.3.6.1.4.1.534.1.6.1.0",
+		"warning":   15,
+		"critical":  25,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alarm := &sdk.Device{
+		Kind:    "alarm",
+		Info:    "alarm",
+		Data:    alarmData,
+		Outputs: []*sdk.Output{{OutputType: outputs.Status}},
+		Handler: &SnmpAlarm,
+	}
+
+	// No reading until the value has been read.
+	readings, err := SnmpAlarmRead(alarm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readings) != 0 {
+		t.Fatalf("Expected no reading, got %+v", readings)
+	}
+
+	rawData, err := core.MergeMapStringInterface(agent, map[string]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := &sdk.Device{
+		Kind:    "raw",
+		Info:    "raw",
+		Data:    rawData,
+		Outputs: []*sdk.Output{{OutputType: outputs.Raw}},
+		Handler: &SnmpRaw,
+	}
+	if _, err = SnmpRawRead(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	readings, err = SnmpAlarmRead(alarm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readings) != 1 || readings[0].Value != alarmWarning || readings[0].Type != "status" {
+		t.Fatalf("Expected status warning for 20, got %+v", readings)
+	}
+	// No status on a value older than the stale limit, such as when the agent
+	// stops responding.
+	client, err := core.GetClient(rawData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := valueKey(client, ".1.3.6.1.4.1.534.1.6.1.0")
+	sample, _ := values.Latest(key)
+	sample.Time = time.Now().Add(-time.Minute)
+	values.Record(key, sample)
+	readings, err = SnmpAlarmRead(alarm)
+	if err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Fatalf("Expected a stale value error, got %+v, %v", readings, err)
+	}
+
+	alarm.Data["stale"] = "2m"
+	readings, err = SnmpAlarmRead(alarm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readings) != 1 || readings[0].Value != alarmWarning {
+		t.Fatalf("Expected status warning within the stale limit, got %+v", readings)
+	}
+}
+
+// TestAlarmsOnOneDevice checks that alarms on the same value keep separate
+// states.
+func TestAlarmsOnOneDevice(t *testing.T) {
+	const oid = ".1.3.6.1.4.1.534.1.6.5.0"
+	agent := emulator.AgentData()
+	alarm := func(name string, rule map[string]interface{}) *sdk.Device {
+		rule["alarm_oid"] = oid
+		rule["alarm_name"] = name
+		data, err := core.MergeMapStringInterface(agent, rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &sdk.Device{
+			Kind:    "alarm",
+			Info:    name,
+			Data:    data,
+			Outputs: []*sdk.Output{{OutputType: outputs.Status}},
+			Handler: &SnmpAlarm,
+		}
+	}
+	warm := alarm("ambientWarm", map[string]interface{}{"warning": 30})
+	hot := alarm("ambientHot", map[string]interface{}{"critical": 30, "hold": "1h"})
+
+	client, err := core.GetClient(warm.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values.Record(valueKey(client, oid), valueSample{Value: 35, Time: time.Now()})
+
+	// The hold of the second alarm does not delay the first.
+	for _, c := range []struct {
+		device   *sdk.Device
+		expected string
+	}{{warm, alarmWarning}, {hot, alarmOk}, {warm, alarmWarning}} {
+		readings, err := SnmpAlarmRead(c.device)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(readings) != 1 || readings[0].Value != c.expected {
+			t.Fatalf("Expected %v status %v, got %+v", c.device.Info, c.expected, readings)
+		}
+	}
+}
+
+// TestLineBadsAlarm reads the upsInputLineBads count and its alarm, as
+// enumerated, while the counter changes. An increment warns, and the next
+// unchanged sample clears the warning.
+func TestLineBadsAlarm(t *testing.T) {
+	if replay == nil {
+		t.Skip("The emulator counter does not change")
+	}
+	const lineBadsOid = ".1.3.6.1.2.1.33.1.3.1.0"
+	load := func(lineBads int) {
+		pdus, err := core.ParseSnmpWalk(strings.NewReader(fmt.Sprintf(
+			".1.3.6.1.2.1.1.3.0 = Timeticks: (6930266) 19:15:02.66\n%v = Counter32: %d\n", lineBadsOid, lineBads)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = replay.Load("line-bads", pdus); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	agent := emulator.AgentData(map[string]interface{}{
+		"contextName": "line-bads",
+	})
+	countData, err := core.MergeMapStringInterface(agent, map[string]interface{}{"oid": lineBadsOid, "counter": "delta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := &sdk.Device{
+		Kind:    "count",
+		Info:    "upsInputLineBads",
+		Data:    countData,
+		Outputs: []*sdk.Output{{OutputType: outputs.Count}},
+		Handler: &SnmpCount,
+	}
+	alarmData, err := core.MergeMapStringInterface(agent, map[string]interface{}{"alarm_oid": lineBadsOid, "warning": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alarm := &sdk.Device{
+		Kind:    "alarm",
+		Info:    "upsInputLineBads alarm",
+		Data:    alarmData,
+		Outputs: []*sdk.Output{{OutputType: outputs.Status}},
+		Handler: &SnmpAlarm,
+	}
+
+	// read reads the count and then the alarm, as they are enumerated.
+	read := func() (countReadings []*sdk.Reading, alarmReadings []*sdk.Reading) {
+		if countReadings, err = SnmpCountRead(count); err != nil {
+			t.Fatal(err)
+		}
+		if alarmReadings, err = SnmpAlarmRead(alarm); err != nil {
+			t.Fatal(err)
+		}
+		return countReadings, alarmReadings
+	}
+
+	// The first sample has no delta, so neither has a reading.
+	load(2)
+	countReadings, alarmReadings := read()
+	if len(countReadings) != 0 || len(alarmReadings) != 0 {
+		t.Fatalf("Expected no readings on the first sample, got %+v and %+v", countReadings, alarmReadings)
+	}
+
+	cases := []struct {
+		lineBads int
+		delta    int64
+		status   string
+	}{
+		{2, 0, alarmOk},
+		{3, 1, alarmWarning},
+		{3, 0, alarmOk},
+	}
+	for i, c := range cases {
+		load(c.lineBads)
+		countReadings, alarmReadings = read()
+		if len(countReadings) != 1 || countReadings[0].Value != c.delta {
+			t.Fatalf("case %d: expected delta %d, got %+v", i, c.delta, countReadings)
+		}
+		if len(alarmReadings) != 1 || alarmReadings[0].Value != c.status {
+			t.Fatalf("case %d: expected status %v, got %+v", i, c.status, alarmReadings)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package devices
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// SnmpComputed is the handler for computed devices. A computed device has an
+// expression over other values of its agent instead of an OID, for example
+// the total output power of a UPS with several output lines:
+//
+//	expression: sum({UPS-MIB::upsOutputPower})
+//
+// The reading is a float with the device's one output type. See
+// core.Expression.
+var SnmpComputed = sdk.DeviceHandler{
+	Name: "computed",
+	Read: SnmpComputedRead,
+}
+
+// SnmpComputedRead is the read handler function for computed devices. There
+// is no reading when the expression divides by zero, such as the efficiency
+// of a UPS without input power.
+func SnmpComputedRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
+
+	// Arg checks.
+	if device == nil {
+		return nil, fmt.Errorf("device is nil")
+	}
+	if len(device.Outputs) == 0 {
+		return nil, fmt.Errorf("device %v has no outputs", device.Info)
+	}
+
+	data := device.Data
+	expression, err := expressions.Get(fmt.Sprint(data["expression"]))
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the SnmpClient of the agent the device refers to.
+	snmpClient, err := core.GetClient(data)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := expression.Evaluate(snmpClient)
+	if err == core.ErrDivisionByZero {
+		return []*sdk.Reading{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the reading.
+	reading, err := device.Outputs[0].MakeReading(float32(value))
+	if err != nil {
+		return nil, err
+	}
+	readings = []*sdk.Reading{reading}
+	return readings, nil
+}
+
+// expressionCache keeps parsed expressions so that a read does not parse its
+// expression again.
+type expressionCache struct {
+	mutex       sync.Mutex
+	expressions map[string]*core.Expression // Keyed by expression text.
+}
+
+// expressions is the process wide expressionCache used by the computed
+// handler.
+var expressions = &expressionCache{
+	expressions: map[string]*core.Expression{},
+}
+
+// Get returns the parsed expression.
+func (cache *expressionCache) Get(text string) (*core.Expression, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if expression, ok := cache.expressions[text]; ok {
+		return expression, nil
+	}
+	expression, err := core.ParseExpression(text)
+	if err != nil {
+		return nil, err
+	}
+	cache.expressions[text] = expression
+	return expression, nil
+}
@@ -0,0 +1,48 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/outputs"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestComputedRead reads computed devices over the emulator UPS lines.
+func TestComputedRead(t *testing.T) {
+	agent := emulator.AgentData()
+
+	cases := []struct {
+		expression string
+		readings   int
+	}{
+		{"sum({.1.3.6.1.2.1.33.1.3.3.1.5})", 1},
+		// No output power, so no efficiency.
+		{"sum({.1.3.6.1.2.1.33.1.4.4.1.4}) / sum({.1.3.6.1.2.1.33.1.4.4.1.2} * {.1.3.6.1.2.1.33.1.4.4.1.3})", 0},
+	}
+	for _, c := range cases {
+		data, err := core.MergeMapStringInterface(agent, map[string]interface{}{"expression": c.expression})
+		if err != nil {
+			t.Fatal(err)
+		}
+		device := &sdk.Device{
+			Kind:    "computed",
+			Info:    "computed",
+			Data:    data,
+			Outputs: []*sdk.Output{{OutputType: outputs.WattsPower}},
+			Handler: &SnmpComputed,
+		}
+
+		readings, err := SnmpComputedRead(device)
+		if err != nil {
+			t.Fatalf("[%v]: %v", c.expression, err)
+		}
+		if len(readings) != c.readings {
+			t.Fatalf("[%v]: expected %d readings, got %+v", c.expression, c.readings, readings)
+		}
+		if c.readings == 1 && (readings[0].Value != float32(1598) || readings[0].Type != "watts.power") {
+			t.Fatalf("[%v]: expected 1598 W, got %+v", c.expression, readings[0])
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package devices
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// SnmpCount is the handler for the SNMP OIDs that count things, such as the
+// number of lines of a UPS.
+var SnmpCount = sdk.DeviceHandler{
+	Name: "count",
+	Read: SnmpCountRead,
+}
+
+// SnmpCountRead is the read handler function for synse SNMP devices that
+// count things. The reading is an integer. A counter is read as a total unless
+// the device data has a counter key for a rate or delta. A rate is rounded to
+// the nearest integer.
+func SnmpCountRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
+
+	// Arg checks.
+	if device == nil {
+		return nil, fmt.Errorf("device is nil")
+	}
+
+	// Get the SnmpClient of the agent the device refers to.
+	data := device.Data
+	snmpClient, err := core.GetClient(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the SNMP OID in the device config.
+	result, err := GetReading(snmpClient, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if IsCounter(data) {
+		resultFloat, ok, err := ScaleReading(snmpClient, result, data)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// A counter needs a previous sample before it has a reading.
+			return []*sdk.Reading{}, nil
+		}
+		count = int64(math.Round(float64(resultFloat)))
+	} else {
+		count, err = result.Int64()
+		if err != nil {
+			return nil, err
+		}
+		recordValue(snmpClient, data, float64(count))
+	}
+
+	// Create the reading.
+	reading, err := device.GetOutput("count").MakeReading(count)
+	if err != nil {
+		return nil, err
+	}
+
+	readings = []*sdk.Reading{reading}
+	return readings, nil
+}
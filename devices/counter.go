@@ -0,0 +1,210 @@
+package devices
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	logger "github.com/Sirupsen/logrus"
+	"github.com/soniah/gosnmp"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// This file contains support for reading SNMP Counter32 and Counter64 objects
+// as a rate or a delta. The raw value of a counter is meaningless on its own,
+// so we keep the previous sample for each counter and report the difference.
+
+// sysUpTimeOid is SNMPv2-MIB::sysUpTime.0, the time in hundredths of a second
+// since the agent was last (re)initialized.
+const sysUpTimeOid = ".1.3.6.1.2.1.1.3.0"
+
+const (
+	// counterRate reports the per-second rate of change of a counter.
+	counterRate = "rate"
+	// counterDelta reports the change of a counter since the previous reading.
+	counterDelta = "delta"
+)
+
+// counterSample is one reading of an SNMP counter.
+type counterSample struct {
+	Value     uint64    // Raw counter value.
+	Bits      uint      // Width of the counter, 32 or 64.
+	Uptime    uint32    // sysUpTime of the agent when the counter was read.
+	HasUptime bool      // False if sysUpTime could not be read.
+	Time      time.Time // Local time the counter was read.
+}
+
+// counterTracker keeps the previous sample for each counter, and the
+// sysUpTime read with a counter by GetReading until CounterReading uses it.
+type counterTracker struct {
+	mutex   sync.Mutex
+	samples map[string]counterSample // Keyed by agent and OID.
+	uptimes map[string]counterSample // Keyed by agent and OID. Only the uptime is set.
+}
+
+// counters is the process wide counterTracker used by the read handlers.
+var counters = newCounterTracker()
+
+// newCounterTracker creates an empty counterTracker.
+func newCounterTracker() *counterTracker {
+	return &counterTracker{
+		samples: map[string]counterSample{},
+		uptimes: map[string]counterSample{},
+	}
+}
+
+// SetUptime stores the sysUpTime of the agent read with the counter at key.
+func (tracker *counterTracker) SetUptime(key string, uptime uint32, hasUptime bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.uptimes[key] = counterSample{Uptime: uptime, HasUptime: hasUptime}
+}
+
+// TakeUptime returns and forgets the sysUpTime stored for the counter at key.
+// found is false if none was stored.
+func (tracker *counterTracker) TakeUptime(key string) (uptime uint32, hasUptime bool, found bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	sample, found := tracker.uptimes[key]
+	delete(tracker.uptimes, key)
+	return sample.Uptime, sample.HasUptime, found
+}
+
+// Update stores the current sample for the counter at key and returns the
+// change since the previous sample along with the elapsed time in seconds.
+// ok is false when there is nothing to compare against, either because this
+// is the first sample or because the agent restarted since the last sample.
+func (tracker *counterTracker) Update(key string, current counterSample) (
+	delta uint64, elapsed float64, ok bool) {
+
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	previous, found := tracker.samples[key]
+	tracker.samples[key] = current
+	if !found || previous.Bits != current.Bits {
+		return 0, 0, false
+	}
+
+	if previous.HasUptime && current.HasUptime {
+		// sysUpTime going backwards means the agent restarted and the counter
+		// started over from an arbitrary value. Discard the previous sample.
+		if current.Uptime < previous.Uptime {
+			logger.Infof("Agent restart detected for counter %v, sysUpTime %d -> %d",
+				key, previous.Uptime, current.Uptime)
+			return 0, 0, false
+		}
+		elapsed = float64(current.Uptime-previous.Uptime) / 100.0
+	} else {
+		elapsed = current.Time.Sub(previous.Time).Seconds()
+	}
+
+	return counterDifference(previous.Value, current.Value, current.Bits), elapsed, true
+}
+
+// counterDifference returns current - previous for a counter of the given
+// width, accounting for a single wraparound.
+func counterDifference(previous uint64, current uint64, bits uint) uint64 {
+	if current >= previous {
+		return current - previous
+	}
+	if bits == 64 {
+		return (math.MaxUint64 - previous) + current + 1
+	}
+	return (math.MaxUint32 - previous) + current + 1
+}
+
+// IsCounter returns whether or not the SNMP device reading is a counter that
+// should be reported as a rate or delta.
+// data is the map associated with a synse device.
+func IsCounter(data map[string]interface{}) (yes bool) {
+	setting, ok := data["counter"]
+	if !ok {
+		return false
+	}
+	return setting == counterRate || setting == counterDelta
+}
+
+// counterValue returns the value of a raw counter reading and the width of the
+// counter. Counter64 is 64 bits wide. Anything else is treated as 32 bits.
+func counterValue(result core.ReadResult) (value uint64, bits uint, err error) {
+	value, err = result.Uint64()
+	if err != nil {
+		return 0, 0, err
+	}
+	if result.Type == gosnmp.Counter64 {
+		return value, 64, nil
+	}
+	return value, 32, nil
+}
+
+// counterKey uniquely identifies a counter across agents.
+func counterKey(client *core.SnmpClient, oid string) string {
+	config := client.DeviceConfig
+	return fmt.Sprintf("%v:%d/%v%v", config.Endpoint, config.Port, config.ContextName, oid)
+}
+
+// GetReading gets the OID of a device. For a counter, the sysUpTime of the
+// agent is read in the same request and kept for CounterReading, so that a
+// counter is one request like any other device.
+func GetReading(client *core.SnmpClient, data map[string]interface{}) (result core.ReadResult, err error) {
+	oid := fmt.Sprint(data["oid"])
+	if !IsCounter(data) {
+		return client.Get(oid)
+	}
+
+	results, err := client.GetMultiple([]string{oid, sysUpTimeOid})
+	if err != nil {
+		return result, err
+	}
+	result = results[0]
+	ticks, err := results[1].Uint64()
+	counters.SetUptime(counterKey(client, result.Oid), uint32(ticks), err == nil)
+	return result, nil
+}
+
+// CounterReading translates a raw counter reading to a rate per second or a
+// delta depending on the counter setting in data. ok is false when there is
+// no previous sample to compare against. The caller should call IsCounter
+// first for this translation to make sense.
+func CounterReading(client *core.SnmpClient, result core.ReadResult, data map[string]interface{}) (
+	value float64, ok bool, err error) {
+
+	current := counterSample{Time: time.Now()}
+	current.Value, current.Bits, err = counterValue(result)
+	if err != nil {
+		return 0, false, err
+	}
+
+	// sysUpTime gives us both restart detection and the agent's idea of the
+	// elapsed time. Fall back to local time if the agent does not provide it.
+	// GetReading has read it already, unless the counter was read otherwise.
+	key := counterKey(client, result.Oid)
+	var found bool
+	current.Uptime, current.HasUptime, found = counters.TakeUptime(key)
+	if !found {
+		uptime, err := client.Get(sysUpTimeOid)
+		if err != nil {
+			logger.Debugf("Unable to read sysUpTime for counter %v: %v", result.Oid, err)
+		} else {
+			ticks, err := uptime.Uint64()
+			current.Uptime, current.HasUptime = uint32(ticks), err == nil
+		}
+	}
+
+	delta, elapsed, ok := counters.Update(key, current)
+	if !ok {
+		return 0, false, nil
+	}
+
+	if data["counter"] == counterDelta {
+		return float64(delta), true, nil
+	}
+	if elapsed <= 0 {
+		// Two samples in the same tick. Wait for the next reading.
+		return 0, false, nil
+	}
+	return float64(delta) / elapsed, true, nil
+}
@@ -0,0 +1,183 @@
+package devices
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/outputs"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/metrics"
+)
+
+// TestCounterDifference checks counter deltas including wraparound.
+func TestCounterDifference(t *testing.T) {
+	cases := []struct {
+		previous uint64
+		current  uint64
+		bits     uint
+		expected uint64
+	}{
+		{previous: 10, current: 25, bits: 32, expected: 15},
+		{previous: 7, current: 7, bits: 64, expected: 0},
+		{previous: math.MaxUint32 - 4, current: 5, bits: 32, expected: 10},
+		{previous: math.MaxUint64 - 1, current: 3, bits: 64, expected: 5},
+	}
+
+	for i, c := range cases {
+		actual := counterDifference(c.previous, c.current, c.bits)
+		if actual != c.expected {
+			t.Fatalf("case %d: expected %d, got %d", i, c.expected, actual)
+		}
+	}
+}
+
+// TestCounterTracker checks first samples, rates over sysUpTime and restarts.
+func TestCounterTracker(t *testing.T) {
+	tracker := newCounterTracker()
+	start := time.Now()
+
+	// The first sample has nothing to compare against.
+	_, _, ok := tracker.Update("a", counterSample{
+		Value: 100, Bits: 32, Uptime: 1000, HasUptime: true, Time: start})
+	if ok {
+		t.Fatal("Expected no delta on the first sample")
+	}
+
+	// 10 seconds of sysUpTime later.
+	delta, elapsed, ok := tracker.Update("a", counterSample{
+		Value: 600, Bits: 32, Uptime: 2000, HasUptime: true, Time: start.Add(time.Second)})
+	if !ok {
+		t.Fatal("Expected a delta on the second sample")
+	}
+	if delta != 500 {
+		t.Fatalf("Expected delta 500, got %d", delta)
+	}
+	if elapsed != 10.0 {
+		t.Fatalf("Expected elapsed 10s from sysUpTime, got %v", elapsed)
+	}
+
+	// sysUpTime went backwards. The agent restarted, so skip this sample.
+	_, _, ok = tracker.Update("a", counterSample{
+		Value: 5, Bits: 32, Uptime: 50, HasUptime: true, Time: start.Add(2 * time.Second)})
+	if ok {
+		t.Fatal("Expected no delta after an agent restart")
+	}
+
+	// Without sysUpTime the local clock is used.
+	tracker.Update("b", counterSample{Value: 1, Bits: 64, Time: start})
+	delta, elapsed, ok = tracker.Update("b", counterSample{
+		Value: 9, Bits: 64, Time: start.Add(4 * time.Second)})
+	if !ok || delta != 8 || elapsed != 4.0 {
+		t.Fatalf("Expected delta 8 over 4s, got ok %v, delta %d, elapsed %v", ok, delta, elapsed)
+	}
+}
+
+// TestIsCounter checks the counter setting in device data.
+func TestIsCounter(t *testing.T) {
+	if IsCounter(map[string]interface{}{}) {
+		t.Fatal("Expected no counter without the counter key")
+	}
+	if !IsCounter(map[string]interface{}{"counter": "rate"}) {
+		t.Fatal("Expected rate counter")
+	}
+	if !IsCounter(map[string]interface{}{"counter": "delta"}) {
+		t.Fatal("Expected delta counter")
+	}
+	if IsCounter(map[string]interface{}{"counter": "bogus"}) {
+		t.Fatal("Expected no counter for an unknown setting")
+	}
+}
+
+// TestCounterRequests checks that a counter device reads its counter and the
+// sysUpTime of its agent in one request.
+func TestCounterRequests(t *testing.T) {
+	if _, err := core.RegisterAgent(emulator.AgentData(map[string]interface{}{
+		core.AgentKey: "counter-test",
+	})); err != nil {
+		t.Fatal(err)
+	}
+	count := &sdk.Device{
+		Kind: "count",
+		Info: "upsInputLineBads",
+		Data: map[string]interface{}{
+			core.AgentKey: "counter-test",
+			"oid":         ".1.3.6.1.2.1.33.1.3.1.0",
+			"counter":     "delta",
+		},
+		Outputs: []*sdk.Output{{OutputType: outputs.Count}},
+		Handler: &SnmpCount,
+	}
+
+	// The first read has no reading and the second has the delta.
+	for i, expected := range []int{0, 1} {
+		readings, err := SnmpCountRead(count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(readings) != expected {
+			t.Fatalf("read %d: expected %d readings, got %+v", i, expected, readings)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := metrics.Default.Write(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	line := `snmp_requests_total{agent="counter-test",operation="get"} 2`
+	if !strings.Contains(buffer.String(), line+"\n") {
+		t.Fatalf("Expected %v in:\n%v", line, buffer.String())
+	}
+}
+
+// TestCountRate checks that a count of a rate is rounded rather than
+// truncated.
+func TestCountRate(t *testing.T) {
+	if replay == nil {
+		t.Skip("The emulator counter does not change")
+	}
+	const lineBadsOid = ".1.3.6.1.2.1.33.1.3.1.0"
+	load := func(ticks int, lineBads int) {
+		pdus, err := core.ParseSnmpWalk(strings.NewReader(fmt.Sprintf(
+			".1.3.6.1.2.1.1.3.0 = Timeticks: (%d)\n%v = Counter32: %d\n", ticks, lineBadsOid, lineBads)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = replay.Load("count-rate", pdus); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := core.MergeMapStringInterface(emulator.AgentData(map[string]interface{}{
+		"contextName": "count-rate",
+	}), map[string]interface{}{"oid": lineBadsOid, "counter": "rate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := &sdk.Device{
+		Kind:    "count",
+		Info:    "upsInputLineBads",
+		Data:    data,
+		Outputs: []*sdk.Output{{OutputType: outputs.Count}},
+		Handler: &SnmpCount,
+	}
+
+	load(1000, 10)
+	if _, err = SnmpCountRead(count); err != nil {
+		t.Fatal(err)
+	}
+	// 7 over 4 seconds is 1.75 per second.
+	load(1400, 17)
+	readings, err := SnmpCountRead(count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readings) != 1 || readings[0].Value != int64(2) {
+		t.Fatalf("Expected a rate of 2, got %+v", readings)
+	}
+}
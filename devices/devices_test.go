@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
 	"github.com/vapor-ware/synse-snmp-plugin/outputs"
 	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
 	"github.com/vapor-ware/synse-snmp-plugin/snmp/mibs/ups_mib"
@@ -47,6 +48,34 @@ func CreateDevices(config *sdk.DeviceConfig, handler *sdk.DeviceHandler) ([]*sdk
 			deviceOutputs = []*sdk.Output{
 				{OutputType: outputs.Voltage},
 			}
+		case "percentage":
+			deviceOutputs = []*sdk.Output{
+				{OutputType: outputs.Percentage},
+			}
+		case "duration":
+			deviceOutputs = []*sdk.Output{
+				{OutputType: outputs.SecondsDuration},
+			}
+			if device.Outputs[0].Type == outputs.MinutesDuration.Name {
+				deviceOutputs = []*sdk.Output{
+					{OutputType: outputs.MinutesDuration},
+				}
+			}
+		case "count":
+			deviceOutputs = []*sdk.Output{
+				{OutputType: outputs.Count},
+			}
+		case "alarm":
+			deviceOutputs = []*sdk.Output{
+				{OutputType: outputs.Status},
+			}
+		case "setting":
+			for _, outputType := range []sdk.OutputType{
+				outputs.Voltage, outputs.Status, outputs.SecondsDuration, outputs.MinutesDuration} {
+				if device.Outputs[0].Type == outputType.Name {
+					deviceOutputs = []*sdk.Output{{OutputType: outputType}}
+				}
+			}
 		default:
 			return nil, fmt.Errorf("device kind not supported in output list creation (must be added): %v", device.Name)
 		}
@@ -71,26 +100,8 @@ func CreateDevices(config *sdk.DeviceConfig, handler *sdk.DeviceHandler) ([]*sdk
 func TestDevices(t *testing.T) { // nolint: gocyclo
 	t.Logf("TestDevices")
 
-	// Create SecurityParameters for the config that should connect to the emulator.
-	securityParameters, err := core.NewSecurityParameters(
-		"simulator",  // User Name
-		core.SHA,     // Authentication Protocol
-		"auctoritas", // Authentication Passphrase
-		core.AES,     // Privacy Protocol
-		"privatus")   // Privacy Passphrase
-	if err != nil {
-		t.Fatal(err) // Fail the test.
-	}
-
-	t.Logf("securityParameters: %+v", securityParameters)
-
-	// Create a snmp config.
-	snmpConfig, err := core.NewDeviceConfig(
-		"v3",        // SNMP v3
-		"127.0.0.1", // Endpoint
-		1024,        // Port
-		securityParameters,
-		"public") //  Context name
+	// Create a config that connects to the emulator.
+	snmpConfig, err := core.GetDeviceConfig(emulator.AgentData())
 	if err != nil {
 		t.Fatal(err) // Fail the test.
 	}
@@ -172,8 +183,8 @@ func TestDevices(t *testing.T) { // nolint: gocyclo
 
 	DumpDeviceConfigs(snmpDevices, "Devices from UPS-MIB")
 	// Check the number of snmp device configs
-	if len(snmpDevices) != 6 {
-		t.Fatalf("Expected 6 snmp device configs, got %d.", len(snmpDevices))
+	if len(snmpDevices) != 8 {
+		t.Fatalf("Expected 8 snmp device configs, got %d.", len(snmpDevices))
 	}
 	// Get the number of snmp device kinds and instances across all configs
 	kinds := map[string]*sdk.DeviceKind{}
@@ -185,14 +196,14 @@ func TestDevices(t *testing.T) { // nolint: gocyclo
 		}
 	}
 	// Check the total number of unique number of device kinds
-	if len(kinds) != 7 {
+	if len(kinds) != 11 {
 		t.Logf("found kinds: %v", kinds)
-		t.Fatalf("Expected 7 device kinds, got %d", len(kinds))
+		t.Fatalf("Expected 11 device kinds, got %d", len(kinds))
 	}
 
 	// Check the total number of device instances
-	if instanceCount != 40 {
-		t.Fatalf("Expected 40 instances, got %d", instanceCount)
+	if instanceCount != 52 {
+		t.Fatalf("Expected 52 instances, got %d", instanceCount)
 	}
 
 	// Check the number of power instances
@@ -231,6 +242,16 @@ func TestDevices(t *testing.T) { // nolint: gocyclo
 				deviceHandler = &SnmpTemperature
 			case "voltage":
 				deviceHandler = &SnmpVoltage
+			case "percentage":
+				deviceHandler = &SnmpPercentage
+			case "duration":
+				deviceHandler = &SnmpDuration
+			case "count":
+				deviceHandler = &SnmpCount
+			case "alarm":
+				deviceHandler = &SnmpAlarm
+			case "setting":
+				deviceHandler = &SnmpSetting
 			default:
 				t.Fatalf("Unknown type: %v", typ)
 			}
@@ -256,6 +277,16 @@ func TestDevices(t *testing.T) { // nolint: gocyclo
 		fmt.Printf("device[%d]: %+v\n", i, devices[i])
 	}
 
+	// A counter has no reading until it has a previous sample, and neither
+	// does the alarm on it.
+	for i := 0; i < len(devices); i++ {
+		if IsCounter(devices[i].Data) {
+			if _, err := devices[i].Read(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
 	// Read each device
 	fmt.Printf("Reading each device.\n")
 	for i := 0; i < len(devices); i++ {
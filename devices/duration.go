@@ -0,0 +1,61 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// SnmpDuration is the handler for the SNMP OIDs that report a duration. The
+// output of the device is seconds.duration or minutes.duration, the units of
+// the OID, such as UPS-MIB::upsSecondsOnBattery and
+// UPS-MIB::upsEstimatedMinutesRemaining.
+var SnmpDuration = sdk.DeviceHandler{
+	Name: "duration",
+	Read: SnmpDurationRead,
+}
+
+// SnmpDurationRead is the read handler function for synse SNMP devices that report a duration.
+func SnmpDurationRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
+
+	// Arg checks.
+	if device == nil {
+		return nil, fmt.Errorf("device is nil")
+	}
+	if len(device.Outputs) == 0 {
+		return nil, fmt.Errorf("device %v has no outputs", device.Info)
+	}
+
+	// Get the SnmpClient of the agent the device refers to.
+	data := device.Data
+	snmpClient, err := core.GetClient(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the SNMP OID in the device config.
+	result, err := GetReading(snmpClient, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Account for a counter and a multiplier if any and convert to float.
+	resultFloat, ok, err := ScaleReading(snmpClient, result, data)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// A counter needs a previous sample before it has a reading.
+		return []*sdk.Reading{}, nil
+	}
+
+	// Create the reading in the units of the device output.
+	reading, err := device.Outputs[0].MakeReading(resultFloat)
+	if err != nil {
+		return nil, err
+	}
+
+	readings = []*sdk.Reading{reading}
+	return readings, nil
+}
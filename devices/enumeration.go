@@ -6,38 +6,56 @@ import (
 	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
 )
 
+// The enumeration key in synse device data names an enumeration in the core
+// enumeration catalog. Example: "enumeration": "UPS-MIB::upsBatteryStatus"
+//
+// Device configs written before the catalog existed set "enumeration": "true"
+// and carry the labels as "enumerationN" keys, where N is the value read. These
+// are still supported.
+
 // IsEnumeration returns whether or not the SNMP device reading represents an
 // enumeration for a sysne device.
 // data is the map associated with a synse device.
 func IsEnumeration(data map[string]interface{}) (yes bool) {
-	// Find the enumeration key in the map and check that it is set to true.
+	// Find the enumeration key in the map and check that it is set.
 	setting, ok := data["enumeration"]
 	if !ok {
 		return false
 	}
 
-	if setting == "true" {
-		return true
-	}
-	return false
+	name := fmt.Sprint(setting)
+	return name != "" && name != "false"
 }
 
 // TranslateEnumeration translates a read result to a string based on the
 // enumeration. The caller should call IsEnumeration first for this translation
 // to make sense.
 func TranslateEnumeration(result core.ReadResult, data map[string]interface{}) (string, error) {
-	// Raw SNMP reading should be an int.
-	resultInt, ok := result.Data.(int)
-	if !ok {
-		return "", fmt.Errorf(
-			"Expected int reading, got type: %T, value: %v",
-			result.Data, result.Data)
+	name := fmt.Sprint(data["enumeration"])
+	if name == "true" {
+		return translateLegacyEnumeration(result, data)
+	}
+
+	enumeration := core.LookupEnumeration(name)
+	if enumeration == nil {
+		return "", fmt.Errorf("Unknown enumeration %v", name)
+	}
+	return enumeration.Translate(result)
+}
+
+// translateLegacyEnumeration translates a read result with the "enumerationN"
+// keys in the device data.
+func translateLegacyEnumeration(result core.ReadResult, data map[string]interface{}) (string, error) {
+	// Raw SNMP reading should be an integer.
+	resultInt, err := result.Int64()
+	if err != nil {
+		return "", err
 	}
 
 	key := fmt.Sprintf("enumeration%d", resultInt)
 	translation, ok := data[key]
 	if !ok {
-		translation = "undefined" // No translation found. unknown is acually used in SNMP.
+		translation = core.DefaultUnknownLabel // No translation found. unknown is acually used in SNMP.
 	}
 	return fmt.Sprint(translation), nil
 }
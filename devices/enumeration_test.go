@@ -0,0 +1,52 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/soniah/gosnmp"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestTranslateEnumeration checks catalog and legacy enumerations in device data.
+func TestTranslateEnumeration(t *testing.T) {
+	err := core.RegisterEnumeration(&core.Enumeration{
+		Name:   "TEST-MIB::testStatus",
+		Labels: map[int64]string{1: "ok", 2: "failed"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := core.NewReadResult(gosnmp.SnmpPDU{Name: ".1", Type: gosnmp.Integer, Value: 2})
+
+	cases := []struct {
+		data     map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"enumeration": "TEST-MIB::testStatus"}, "failed"},
+		{map[string]interface{}{"enumeration": "true", "enumeration2": "legacyFailed"}, "legacyFailed"},
+		{map[string]interface{}{"enumeration": "true", "enumeration1": "legacyOk"}, core.DefaultUnknownLabel},
+	}
+	for i, c := range cases {
+		if !IsEnumeration(c.data) {
+			t.Fatalf("case %d: expected enumeration", i)
+		}
+		actual, err := TranslateEnumeration(result, c.data)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if actual != c.expected {
+			t.Fatalf("case %d: expected %v, got %v", i, c.expected, actual)
+		}
+	}
+
+	for _, data := range []map[string]interface{}{{}, {"enumeration": "false"}, {"enumeration": ""}} {
+		if IsEnumeration(data) {
+			t.Fatalf("Expected no enumeration for %v", data)
+		}
+	}
+
+	_, err = TranslateEnumeration(result, map[string]interface{}{"enumeration": "TEST-MIB::nothing"})
+	if err == nil {
+		t.Fatal("Expected error for an unknown enumeration")
+	}
+}
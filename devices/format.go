@@ -0,0 +1,54 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// FormatReading is a helper method to format a string reading using the
+// textual convention or display hint in data, if any.
+// data is the map associated with a synse device. The optional keys are
+// textual_convention (example: DateAndTime) and display_hint (example: d-1).
+// An InetAddress is formatted for its InetAddressType, address_type (example:
+// dns) or the value of address_type_oid, the InetAddressType object of the
+// address. With neither, the type is inferred from the length.
+func FormatReading(client *core.SnmpClient, result core.ReadResult, data map[string]interface{}) (string, error) {
+	textualConvention := ""
+	if tc, ok := data["textual_convention"]; ok {
+		textualConvention = fmt.Sprint(tc)
+	}
+	if textualConvention == core.InetAddress {
+		addressType, err := inetAddressType(client, data)
+		if err != nil {
+			return "", err
+		}
+		return result.FormatInetAddress(addressType)
+	}
+	displayHint := ""
+	if hint, ok := data["display_hint"]; ok {
+		displayHint = fmt.Sprint(hint)
+	}
+	return result.FormatTextualConvention(textualConvention, displayHint)
+}
+
+// inetAddressType returns the InetAddressType of an InetAddress device: the
+// address_type in data, or the value of the address_type_oid object.
+func inetAddressType(client *core.SnmpClient, data map[string]interface{}) (int, error) {
+	if addressType, ok := data["address_type"]; ok {
+		return core.ParseInetAddressType(addressType)
+	}
+	oid, ok := data["address_type_oid"]
+	if !ok {
+		return core.InetAddressTypeUnknown, nil
+	}
+	result, err := client.Get(fmt.Sprint(oid))
+	if err != nil {
+		return 0, err
+	}
+	addressType, err := result.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("InetAddressType %v: %v", core.OidDisplay(fmt.Sprint(oid)), err)
+	}
+	return core.ParseInetAddressType(addressType)
+}
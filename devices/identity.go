@@ -21,15 +21,9 @@ func SnmpIdentityRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
 		return nil, fmt.Errorf("device is nil")
 	}
 
-	// Get the SNMP device config from the strings in data.
+	// Get the SnmpClient of the agent the device refers to.
 	data := device.Data
-	snmpConfig, err := core.GetDeviceConfig(data)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create SnmpClient.
-	snmpClient, err := core.NewSnmpClient(snmpConfig)
+	snmpClient, err := core.GetClient(data)
 	if err != nil {
 		return nil, err
 	}
@@ -40,12 +34,10 @@ func SnmpIdentityRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
 		return nil, err
 	}
 
-	// Should be a string.
-	resultString, ok := result.Data.(string)
-	if !ok {
-		return nil, fmt.Errorf(
-			"Expected int identity reading, got type: %T, value: %v",
-			result.Data, result.Data)
+	// Should be a string, formatted by its textual convention if known.
+	resultString, err := FormatReading(snmpClient, result, data)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create the reading.
@@ -0,0 +1,43 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+)
+
+// InventoryKey is the device data key of the inventory record of an agent, as
+// JSON. See core.Inventory.
+const InventoryKey = "inventory"
+
+// SnmpInventory is the handler for the inventory device of an agent. The
+// inventory is read from the agent when its devices are enumerated, so a
+// reading makes no request and is refreshed on re-enumeration.
+var SnmpInventory = sdk.DeviceHandler{
+	Name: "inventory",
+	Read: SnmpInventoryRead,
+}
+
+// SnmpInventoryRead is the read handler function for inventory devices. The
+// reading is the JSON inventory record.
+func SnmpInventoryRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
+
+	// Arg checks.
+	if device == nil {
+		return nil, fmt.Errorf("device is nil")
+	}
+
+	inventory, ok := device.Data[InventoryKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("device %v has no inventory", device.Info)
+	}
+
+	// Create the reading.
+	reading, err := device.GetOutput("inventory").MakeReading(inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	readings = []*sdk.Reading{reading}
+	return readings, nil
+}
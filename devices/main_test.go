@@ -0,0 +1,20 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// replay is the transport of the tests, or nil when they run against the
+// emulator.
+var replay *core.ReplayTransport
+
+// TestMain runs the tests against the emulator. See emulator.Main.
+func TestMain(m *testing.M) {
+	emulator.Main(m, func(dir string) (err error) {
+		replay, err = core.UseReplayTransport(dir)
+		return err
+	})
+}
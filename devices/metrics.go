@@ -0,0 +1,71 @@
+package devices
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/metrics"
+)
+
+// Metrics of the device reads, in metrics.Default. The handler label is the
+// device handler name, such as voltage, and the result label is ok or error.
+// The PDUs of a read are those the agent returned while it ran, so they are
+// exact when the plugin reads devices in serial mode, and include the PDUs of
+// concurrent reads of the agent otherwise.
+var (
+	deviceReadsTotal = metrics.Default.NewCounter(
+		"snmp_device_reads_total",
+		"Device reads of an agent, by device handler and result.",
+		"agent", "handler", "result")
+	deviceReadDuration = metrics.Default.NewHistogram(
+		"snmp_device_read_duration_seconds",
+		"Duration of the device reads of an agent, including all of their SNMP requests.",
+		metrics.DefaultBuckets,
+		"agent", "handler")
+	deviceReadPdus = metrics.Default.NewHistogram(
+		"snmp_device_read_pdus",
+		"PDUs returned by an agent for each device read, over all of its SNMP requests.",
+		[]float64{0, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+		"agent", "handler")
+)
+
+// Instrument wraps the read functions of device handlers with the device read
+// metrics. It returns the handlers for RegisterDeviceHandlers.
+func Instrument(handlers ...*sdk.DeviceHandler) []*sdk.DeviceHandler {
+	for _, handler := range handlers {
+		if handler.Read == nil {
+			continue
+		}
+		read := handler.Read
+		name := handler.Name
+		handler.Read = func(device *sdk.Device) ([]*sdk.Reading, error) {
+			agent := metricsAgent(device)
+			pdus := core.ResponsePdus(agent)
+			start := time.Now()
+			readings, err := read(device)
+			deviceReadDuration.Observe(time.Since(start).Seconds(), agent, name)
+			deviceReadPdus.Observe(float64(core.ResponsePdus(agent)-pdus), agent, name)
+			if err != nil {
+				deviceReadsTotal.Inc(agent, name, "error")
+			} else {
+				deviceReadsTotal.Inc(agent, name, "ok")
+			}
+			return readings, err
+		}
+	}
+	return handlers
+}
+
+// metricsAgent returns the agent label of a device: its agent reference, or
+// the endpoint and port of a device with connection settings of its own.
+func metricsAgent(device *sdk.Device) string {
+	if device == nil {
+		return ""
+	}
+	if id, ok := device.Data[core.AgentKey].(string); ok {
+		return id
+	}
+	return fmt.Sprintf("%v:%v", device.Data["endpoint"], device.Data["port"])
+}
@@ -0,0 +1,96 @@
+package devices
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/metrics"
+)
+
+// TestInstrument checks that instrumented handlers count device reads by
+// result.
+func TestInstrument(t *testing.T) {
+	fail := false
+	handler := &sdk.DeviceHandler{
+		Name: "instrument-test",
+		Read: func(device *sdk.Device) ([]*sdk.Reading, error) {
+			if fail {
+				return nil, fmt.Errorf("read failed")
+			}
+			return []*sdk.Reading{}, nil
+		},
+	}
+	Instrument(handler)
+
+	device := &sdk.Device{Data: map[string]interface{}{core.AgentKey: "instrument-agent"}}
+	if _, err := handler.Read(device); err != nil {
+		t.Fatal(err)
+	}
+	fail = true
+	if _, err := handler.Read(device); err == nil {
+		t.Fatal("Expected the error of the read")
+	}
+
+	var buffer bytes.Buffer
+	if err := metrics.Default.Write(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`snmp_device_reads_total{agent="instrument-agent",handler="instrument-test",result="error"} 1`,
+		`snmp_device_reads_total{agent="instrument-agent",handler="instrument-test",result="ok"} 1`,
+		`snmp_device_read_duration_seconds_count{agent="instrument-agent",handler="instrument-test"} 2`,
+		`snmp_device_read_pdus_sum{agent="instrument-agent",handler="instrument-test"} 0`,
+	} {
+		if !strings.Contains(buffer.String(), line+"\n") {
+			t.Errorf("Expected %v in:\n%v", line, buffer.String())
+		}
+	}
+}
+
+// TestInstrumentPdus checks the PDUs of a device read over its requests.
+func TestInstrumentPdus(t *testing.T) {
+	if _, err := core.RegisterAgent(emulator.AgentData(map[string]interface{}{
+		core.AgentKey: "pdu-test",
+	})); err != nil {
+		t.Fatal(err)
+	}
+	handler := &sdk.DeviceHandler{
+		Name: "pdu-test",
+		Read: func(device *sdk.Device) ([]*sdk.Reading, error) {
+			client, err := core.GetClient(device.Data)
+			if err != nil {
+				return nil, err
+			}
+			// Three PDUs in two requests.
+			if _, err = client.GetMultiple([]string{".1.3.6.1.2.1.1.3.0", ".1.3.6.1.2.1.1.5.0"}); err != nil {
+				return nil, err
+			}
+			_, err = client.Get(".1.3.6.1.2.1.1.3.0")
+			return []*sdk.Reading{}, err
+		},
+	}
+	Instrument(handler)
+
+	device := &sdk.Device{Data: map[string]interface{}{core.AgentKey: "pdu-test"}}
+	if _, err := handler.Read(device); err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	if err := metrics.Default.Write(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`snmp_device_read_pdus_sum{agent="pdu-test",handler="pdu-test"} 3`,
+		`snmp_device_read_pdus_count{agent="pdu-test",handler="pdu-test"} 1`,
+	} {
+		if !strings.Contains(buffer.String(), line+"\n") {
+			t.Errorf("Expected %v in:\n%v", line, buffer.String())
+		}
+	}
+}
@@ -10,27 +10,61 @@ import (
 // appropriately.
 func MultiplyReading(result core.ReadResult, data map[string]interface{}) (resultFloat float32, err error) {
 
-	// Raw SNMP reading should be an int.
-	resultInt, ok := result.Data.(int)
-	if !ok {
-		return 0.0, fmt.Errorf(
-			"Expected int reading, got type: %T, value: %v",
-			result.Data, result.Data)
+	// Raw SNMP reading should be numeric.
+	value, err := result.Float64()
+	if err != nil {
+		return 0.0, err
 	}
 
-	// Account for a multiplier if any, otherwise just convert to float32.
-	multiplier, ok := data["multiplier"]
-	if ok {
-		multiplierFloat, isOk := multiplier.(float32)
-		if !isOk {
-			return 0.0, fmt.Errorf(
-				"expected float multiplier, got type: %T, value: %v", multiplier, multiplier,
-			)
-		}
-		resultFloat = float32(resultInt) * multiplierFloat
+	return multiply(float32(value), data)
+}
+
+// ScaleReading is a helper method to convert a raw reading to a float for the
+// numeric device handlers. Counters are first translated to a rate or delta,
+// then any multiplier is applied. ok is false when there is no reading yet,
+// which happens on the first read of a counter. The value is recorded for the
+// alarms on the device.
+func ScaleReading(client *core.SnmpClient, result core.ReadResult, data map[string]interface{}) (
+	resultFloat float32, ok bool, err error) {
+
+	if !IsCounter(data) {
+		resultFloat, err = MultiplyReading(result, data)
 	} else {
-		resultFloat = float32(resultInt)
+		var value float64
+		value, ok, err = CounterReading(client, result, data)
+		if err != nil || !ok {
+			return 0.0, false, err
+		}
+		resultFloat, err = multiply(float32(value), data)
+	}
+	if err != nil {
+		return 0.0, false, err
+	}
+	recordValue(client, data, float64(resultFloat))
+	return resultFloat, true, nil
+}
+
+// multiply applies the multiplier in data, if any, to value.
+func multiply(value float32, data map[string]interface{}) (float32, error) {
+	multiplier, ok := data["multiplier"]
+	if !ok {
+		return value, nil
 	}
 
-	return resultFloat, err
+	// Enumerated devices have float32 multipliers. Device config files have
+	// float64, or int for whole numbers.
+	var multiplierFloat float32
+	switch m := multiplier.(type) {
+	case float32:
+		multiplierFloat = m
+	case float64:
+		multiplierFloat = float32(m)
+	case int:
+		multiplierFloat = float32(m)
+	default:
+		return 0.0, fmt.Errorf(
+			"expected float multiplier, got type: %T, value: %v", multiplier, multiplier,
+		)
+	}
+	return value * multiplierFloat, nil
 }
@@ -0,0 +1,55 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// SnmpPercentage is the handler for the SNMP OIDs that report a percentage.
+var SnmpPercentage = sdk.DeviceHandler{
+	Name: "percentage",
+	Read: SnmpPercentageRead,
+}
+
+// SnmpPercentageRead is the read handler function for synse SNMP devices that report a percentage.
+func SnmpPercentageRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
+
+	// Arg checks.
+	if device == nil {
+		return nil, fmt.Errorf("device is nil")
+	}
+
+	// Get the SnmpClient of the agent the device refers to.
+	data := device.Data
+	snmpClient, err := core.GetClient(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the SNMP OID in the device config.
+	result, err := GetReading(snmpClient, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Account for a counter and a multiplier if any and convert to float.
+	resultFloat, ok, err := ScaleReading(snmpClient, result, data)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// A counter needs a previous sample before it has a reading.
+		return []*sdk.Reading{}, nil
+	}
+
+	// Create the reading.
+	reading, err := device.GetOutput("percentage").MakeReading(resultFloat)
+	if err != nil {
+		return nil, err
+	}
+
+	readings = []*sdk.Reading{reading}
+	return readings, nil
+}
@@ -0,0 +1,107 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/soniah/gosnmp"
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// SnmpRaw is the handler for SNMP OIDs declared in the configuration without
+// a specific device kind.
+var SnmpRaw = sdk.DeviceHandler{
+	Name: "raw",
+	Read: SnmpRawRead,
+}
+
+// SnmpRawRead is the read handler function for raw synse SNMP devices. The
+// reading is numeric or a string depending on the decoded SNMP type.
+func SnmpRawRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
+
+	// Arg checks.
+	if device == nil {
+		return nil, fmt.Errorf("device is nil")
+	}
+	if len(device.Outputs) == 0 {
+		return nil, fmt.Errorf("device %v has no outputs", device.Info)
+	}
+
+	// Get the SnmpClient of the agent the device refers to.
+	data := device.Data
+	snmpClient, err := core.GetClient(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the SNMP OID in the device config.
+	result, err := GetReading(snmpClient, data)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok, err := RawReading(snmpClient, result, data)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// A counter needs a previous sample before it has a reading.
+		return []*sdk.Reading{}, nil
+	}
+
+	// Create the reading. A raw device has one output, raw unless the
+	// configuration gives another output type.
+	reading, err := device.Outputs[0].MakeReading(value)
+	if err != nil {
+		return nil, err
+	}
+	readings = []*sdk.Reading{reading}
+	return readings, nil
+}
+
+// RawReading converts a raw SNMP reading by its decoded type. Enumerations are
+// their labels. Numbers with a counter setting or a multiplier are scaled to a
+// float32. Other integers are int64 or uint64 and Opaque floats are float64.
+// Everything else is formatted as a string. ok is false when there is no
+// reading yet, which happens on the first read of a counter.
+func RawReading(client *core.SnmpClient, result core.ReadResult, data map[string]interface{}) (
+	value interface{}, ok bool, err error) {
+
+	if result.IsNull() {
+		return "", true, nil
+	}
+
+	switch result.Type {
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.Counter64,
+		gosnmp.TimeTicks, gosnmp.Uinteger32:
+		if IsEnumeration(data) {
+			value, err = TranslateEnumeration(result, data)
+			return value, err == nil, err
+		}
+		if _, scaled := data["multiplier"]; scaled || IsCounter(data) {
+			return ScaleReading(client, result, data)
+		}
+		if result.Type == gosnmp.Integer {
+			value, err = result.Int64()
+		} else {
+			value, err = result.Uint64()
+		}
+		if err == nil {
+			f, _ := result.Float64() // Numeric.
+			recordValue(client, data, f)
+		}
+		return value, err == nil, err
+
+	case gosnmp.Opaque:
+		if _, scaled := data["multiplier"]; scaled {
+			return ScaleReading(client, result, data)
+		}
+		if f, err := result.Float64(); err == nil {
+			recordValue(client, data, f)
+			return f, true, nil
+		}
+	}
+
+	value, err = FormatReading(client, result, data)
+	return value, err == nil, err
+}
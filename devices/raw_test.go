@@ -0,0 +1,112 @@
+package devices
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/outputs"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestRawRead reads raw devices of each SNMP type from the emulator data.
+func TestRawRead(t *testing.T) {
+	agent := emulator.AgentData()
+
+	cases := []struct {
+		data     map[string]interface{}
+		expected interface{}
+	}{
+		{map[string]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.1.0"}, int64(20)},
+		{map[string]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.1.0", "multiplier": 0.5}, float32(10)},
+		{map[string]interface{}{"oid": ".1.3.6.1.2.1.1.3.0"}, uint64(6930266)},
+		{map[string]interface{}{"oid": ".1.3.6.1.2.1.33.1.1.2.0"}, "PXGMS UPS + EATON 93PM"},
+		{map[string]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.7.0", "enumeration": "true", "enumeration2": "closed"}, "closed"},
+	}
+	for i, c := range cases {
+		data, err := core.MergeMapStringInterface(agent, c.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		device := &sdk.Device{
+			Kind:    "raw",
+			Info:    "raw",
+			Data:    data,
+			Outputs: []*sdk.Output{{OutputType: outputs.Raw}},
+			Handler: &SnmpRaw,
+		}
+
+		readings, err := SnmpRawRead(device)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if len(readings) != 1 {
+			t.Fatalf("case %d: expected one reading, got %+v", i, readings)
+		}
+		if readings[0].Value != c.expected || readings[0].Type != "raw" {
+			t.Fatalf("case %d: expected raw %T %v, got %+v", i, c.expected, c.expected, readings[0])
+		}
+	}
+}
+
+// TestRawInetAddress formats an InetAddress for its InetAddressType, which
+// may be in the device data or read from the agent.
+func TestRawInetAddress(t *testing.T) {
+	if replay == nil {
+		t.Skip("The emulator has no InetAddress")
+	}
+	const addressTypeOid = ".1.3.6.1.4.1.99999.1.1.0"
+	const addressOid = ".1.3.6.1.4.1.99999.1.2.0"
+	pdus, err := core.ParseSnmpWalk(strings.NewReader(
+		addressTypeOid + " = INTEGER: 16\n" + addressOid + " = Hex-STRING: 68 6F 73 74\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = replay.Load("inet-address", pdus); err != nil {
+		t.Fatal(err)
+	}
+	agent := emulator.AgentData(map[string]interface{}{"contextName": "inet-address"})
+
+	cases := []struct {
+		data     map[string]interface{}
+		expected string
+	}{
+		// Four octets without a type look like IPv4.
+		{map[string]interface{}{}, "104.111.115.116"},
+		{map[string]interface{}{"address_type": "dns"}, "host"},
+		{map[string]interface{}{"address_type": 1}, "104.111.115.116"},
+		{map[string]interface{}{"address_type_oid": addressTypeOid}, "host"},
+	}
+	for i, c := range cases {
+		data, err := core.MergeMapStringInterface(agent, c.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data["oid"] = addressOid
+		data["textual_convention"] = core.InetAddress
+		readings, err := SnmpRawRead(&sdk.Device{
+			Kind:    "raw",
+			Info:    "inet address",
+			Data:    data,
+			Outputs: []*sdk.Output{{OutputType: outputs.Raw}},
+			Handler: &SnmpRaw,
+		})
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if len(readings) != 1 || readings[0].Value != c.expected {
+			t.Fatalf("case %d: expected %v, got %+v", i, c.expected, readings)
+		}
+	}
+
+	// An unknown type is an error.
+	data, err := core.MergeMapStringInterface(agent, map[string]interface{}{
+		"oid": addressOid, "textual_convention": core.InetAddress, "address_type": "ipx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = SnmpRawRead(&sdk.Device{Data: data, Outputs: []*sdk.Output{{OutputType: outputs.Raw}}}); err == nil {
+		t.Fatal("Expected an error for an unknown InetAddressType")
+	}
+}
@@ -0,0 +1,81 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// SnmpRow is the handler for row devices. A row device has an output per
+// column of a table row, such as the voltage, current and power of a UPS
+// output line, and reads all of them in one SNMP get. See core.RowDevicesKey.
+var SnmpRow = sdk.DeviceHandler{
+	Name: "row",
+	Read: SnmpRowRead,
+}
+
+// SnmpRowRead is the read handler function for row devices. There is a
+// reading per output with a value. Each output has the multiplier in the
+// device data for its type.
+func SnmpRowRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
+
+	// Arg checks.
+	if device == nil {
+		return nil, fmt.Errorf("device is nil")
+	}
+	if len(device.Outputs) == 0 {
+		return nil, fmt.Errorf("device %v has no outputs", device.Info)
+	}
+
+	// Get the SnmpClient of the agent the device refers to.
+	data := device.Data
+	snmpClient, err := core.GetClient(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the OID of each output, in output order.
+	rowOids := core.RowOids(data)
+	var rowOutputs []*sdk.Output
+	var oids []string
+	for _, output := range device.Outputs {
+		oid, ok := rowOids[output.OutputType.Name]
+		if !ok {
+			return nil, fmt.Errorf("device %v has no OID for output %v", device.Info, output.OutputType.Name)
+		}
+		rowOutputs = append(rowOutputs, output)
+		oids = append(oids, oid)
+	}
+
+	// Read the columns of the row in one request.
+	results, err := snmpClient.GetMultiple(oids)
+	if err != nil {
+		return nil, err
+	}
+
+	readings = []*sdk.Reading{}
+	for i, result := range results {
+		if result.IsNull() {
+			continue // The agent does not have this column.
+		}
+
+		// Scale each column as a device for the column would.
+		outputName := rowOutputs[i].OutputType.Name
+		columnData := map[string]interface{}{"oid": oids[i]}
+		if multiplier, ok := data[core.RowMultiplierPrefix+outputName]; ok {
+			columnData["multiplier"] = multiplier
+		}
+		resultFloat, _, err := ScaleReading(snmpClient, result, columnData)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", core.OidDisplay(oids[i]), err)
+		}
+
+		reading, err := rowOutputs[i].MakeReading(resultFloat)
+		if err != nil {
+			return nil, err
+		}
+		readings = append(readings, reading)
+	}
+	return readings, nil
+}
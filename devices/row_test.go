@@ -0,0 +1,65 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/outputs"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestRowRead reads the first input line of the emulator UPS in one request.
+func TestRowRead(t *testing.T) {
+	data := emulator.AgentData(map[string]interface{}{
+		"oid.frequency":        ".1.3.6.1.2.1.33.1.3.3.1.2.1",
+		"multiplier.frequency": float32(0.1),
+		"oid.voltage":          ".1.3.6.1.2.1.33.1.3.3.1.3.1",
+		"oid.current":          ".1.3.6.1.2.1.33.1.3.3.1.4.1",
+		"multiplier.current":   0.1, // From a device config file.
+		"oid.watts.power":      ".1.3.6.1.2.1.33.1.3.3.1.5.1",
+	})
+	device := &sdk.Device{
+		Kind: "row",
+		Info: "upsInputLine0",
+		Data: data,
+		Outputs: []*sdk.Output{
+			{OutputType: outputs.Frequency},
+			{OutputType: outputs.Voltage},
+			{OutputType: outputs.Current},
+			{OutputType: outputs.WattsPower},
+		},
+		Handler: &SnmpRow,
+	}
+
+	readings, err := SnmpRowRead(device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		typ   string
+		value float32
+	}{
+		{"frequency", 60},
+		{"voltage", 288},
+		{"current", 10.3},
+		{"watts.power", 574},
+	}
+	if len(readings) != len(expected) {
+		t.Fatalf("Expected %d readings, got %+v", len(expected), readings)
+	}
+	for i, e := range expected {
+		if readings[i].Type != e.typ || readings[i].Value != e.value {
+			t.Fatalf("Expected %v %v, got %+v", e.typ, e.value, readings[i])
+		}
+	}
+
+	// An output without an OID is an error.
+	device.Outputs = append(device.Outputs, &sdk.Output{OutputType: outputs.Percentage})
+	if _, err = SnmpRowRead(device); err == nil {
+		t.Fatal("Expected an error for an output without an OID")
+	}
+	if sortOid, err := core.RowSortOid(data); err != nil || sortOid != ".1.3.6.1.2.1.33.1.3.3.1.2.1" {
+		t.Fatalf("Expected the frequency OID to sort the row, got %v, %v", sortOid, err)
+	}
+}
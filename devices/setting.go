@@ -0,0 +1,200 @@
+package devices
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// SettingAction is the write action that sets the value of a writable device.
+const SettingAction = "set"
+
+// Writable devices are setting devices with "writable": true in their device
+// data, such as UPS-MIB::upsConfigLowBattTime. The write data is the new value
+// in the units of the reading, or an enumeration label for an enumeration. The
+// value is checked against these keys of the device data before it is set:
+//
+//	minimum: The lowest value.
+//	maximum: The highest value.
+//	below_oid: An OID that the value must be below, such as the nominal input
+//	  voltage for the low voltage transfer point.
+//	above_oid: An OID that the value must be above.
+//
+// below_oid and above_oid are compared in the units of the agent, before the
+// multiplier of the device, so their OIDs must have the units of the device
+// OID.
+
+// SnmpSetting is the handler for the SNMP OIDs that are settings, such as the
+// writable columns of UPS-MIB::upsConfigTable. A setting reads as the handler
+// of its output type does, and only settings can be written.
+var SnmpSetting = sdk.DeviceHandler{
+	Name:  "setting",
+	Read:  SnmpSettingRead,
+	Write: SnmpSettingWrite,
+}
+
+// SnmpSettingRead is the read handler function for synse SNMP setting
+// devices. The output of the device is voltage, status, seconds.duration or
+// minutes.duration.
+func SnmpSettingRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
+
+	// Arg checks.
+	if device == nil {
+		return nil, fmt.Errorf("device is nil")
+	}
+	if len(device.Outputs) == 0 {
+		return nil, fmt.Errorf("device %v has no outputs", device.Info)
+	}
+
+	switch output := device.Outputs[0].OutputType.Name; output {
+	case "voltage":
+		return SnmpVoltageRead(device)
+	case "status":
+		return SnmpStatusRead(device)
+	case "seconds.duration", "minutes.duration":
+		return SnmpDurationRead(device)
+	default:
+		return nil, fmt.Errorf("device %v: unsupported setting output %v", device.Info, output)
+	}
+}
+
+// IsWritable returns whether or not the synse device can be written.
+// data is the map associated with a synse device.
+func IsWritable(data map[string]interface{}) bool {
+	writable, ok := data["writable"]
+	return ok && fmt.Sprint(writable) == "true"
+}
+
+// SnmpSettingWrite is the write handler function for writable synse SNMP
+// devices. It sets the OID of the device to the value of the write. The writes
+// of devices that are not writable, per IsWritable, fail.
+func SnmpSettingWrite(device *sdk.Device, data *sdk.WriteData) (err error) {
+
+	// Arg checks.
+	if device == nil {
+		return fmt.Errorf("device is nil")
+	}
+	if data == nil {
+		return fmt.Errorf("write data is nil")
+	}
+	deviceData := device.Data
+	if !IsWritable(deviceData) {
+		return fmt.Errorf("device %v is not writable", device.Info)
+	}
+	if data.Action != SettingAction {
+		return fmt.Errorf("Unsupported action [%v]. The action is [%v]", data.Action, SettingAction)
+	}
+
+	// Get the SnmpClient of the agent the device refers to.
+	snmpClient, err := core.GetClient(deviceData)
+	if err != nil {
+		return err
+	}
+
+	value, err := settingValue(snmpClient, strings.TrimSpace(string(data.Data)), deviceData)
+	if err != nil {
+		return fmt.Errorf("device %v: %v", device.Info, err)
+	}
+	return snmpClient.SetInteger(fmt.Sprint(deviceData["oid"]), value)
+}
+
+// settingValue converts the text of a write to the integer to set, checking
+// its range.
+func settingValue(client *core.SnmpClient, text string, data map[string]interface{}) (int, error) {
+	if IsEnumeration(data) {
+		name := fmt.Sprint(data["enumeration"])
+		enumeration := core.LookupEnumeration(name)
+		if enumeration == nil {
+			return 0, fmt.Errorf("Unknown enumeration %v", name)
+		}
+		value, ok := enumeration.Value(text)
+		if !ok {
+			return 0, fmt.Errorf("[%v] is not a label of %v", text, name)
+		}
+		return int(value), nil
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("[%v] is not a number", text)
+	}
+	if err = checkSettingRange(value, data); err != nil {
+		return 0, err
+	}
+
+	// Convert from the units of the reading to those of the agent.
+	multiplier := 1.0
+	if m, ok := data["multiplier"]; ok {
+		if multiplier, err = toFloat64(m); err != nil {
+			return 0, fmt.Errorf("multiplier: %v", err)
+		}
+		if multiplier == 0 {
+			return 0, fmt.Errorf("multiplier is zero")
+		}
+	}
+	raw := math.Round(value / multiplier)
+	if err = checkSettingBounds(client, value, raw, multiplier, data); err != nil {
+		return 0, err
+	}
+	return int(raw), nil
+}
+
+// checkSettingRange checks a value to set, in the units of the reading,
+// against the minimum and maximum in the device data.
+func checkSettingRange(value float64, data map[string]interface{}) error {
+	if m, ok := data["minimum"]; ok {
+		minimum, err := toFloat64(m)
+		if err != nil {
+			return fmt.Errorf("minimum: %v", err)
+		}
+		if value < minimum {
+			return fmt.Errorf("%v is below the minimum %v", value, minimum)
+		}
+	}
+	if m, ok := data["maximum"]; ok {
+		maximum, err := toFloat64(m)
+		if err != nil {
+			return fmt.Errorf("maximum: %v", err)
+		}
+		if value > maximum {
+			return fmt.Errorf("%v is above the maximum %v", value, maximum)
+		}
+	}
+	return nil
+}
+
+// checkSettingBounds checks a value to set against the bound OIDs in the
+// device data. raw is the value in the units of the agent, which are those of
+// the bounds, and the errors are in the units of the reading. A bound OID
+// without a value on the agent is not checked.
+func checkSettingBounds(client *core.SnmpClient, value float64, raw float64, multiplier float64,
+	data map[string]interface{}) error {
+	for _, key := range []string{"below_oid", "above_oid"} {
+		oid, ok := data[key]
+		if !ok {
+			continue
+		}
+		result, err := client.Get(fmt.Sprint(oid))
+		if err != nil {
+			return err
+		}
+		if result.IsNull() {
+			continue
+		}
+		bound, err := result.Float64()
+		if err != nil {
+			return err
+		}
+		if key == "below_oid" && raw >= bound {
+			return fmt.Errorf("%v is not below %v %v", value, core.OidName(fmt.Sprint(oid)), float32(bound*multiplier))
+		}
+		if key == "above_oid" && raw <= bound {
+			return fmt.Errorf("%v is not above %v %v", value, core.OidName(fmt.Sprint(oid)), float32(bound*multiplier))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package devices
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/outputs"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestSettingWrite writes the nominal output voltage and frequency of the
+// emulator UPS, which are 479 V and 600 in 0.1 Hertz, as are the nominal input
+// voltage and frequency.
+func TestSettingWrite(t *testing.T) {
+	agent := emulator.AgentData()
+	voltageData, err := core.MergeMapStringInterface(agent, map[string]interface{}{
+		"oid":       ".1.3.6.1.2.1.33.1.9.3.0", // upsConfigOutputVoltage
+		"writable":  true,
+		"minimum":   100,
+		"below_oid": ".1.3.6.1.2.1.33.1.9.1.0", // upsConfigInputVoltage, also 479 V.
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	voltage := &sdk.Device{
+		Kind:    "setting",
+		Info:    "upsConfigOutputVoltage",
+		Data:    voltageData,
+		Outputs: []*sdk.Output{{OutputType: outputs.Voltage}},
+		Handler: &SnmpSetting,
+	}
+	client, err := core.GetClient(voltageData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.SetInteger(".1.3.6.1.2.1.33.1.9.3.0", 479) // nolint: errcheck
+
+	cases := []struct {
+		data     *sdk.WriteData
+		expected string
+	}{
+		{&sdk.WriteData{Action: "on", Data: []byte("470")}, "Unsupported action"},
+		{&sdk.WriteData{Action: SettingAction, Data: []byte("high")}, "is not a number"},
+		{&sdk.WriteData{Action: SettingAction, Data: []byte("99")}, "below the minimum"},
+		{&sdk.WriteData{Action: SettingAction, Data: []byte("479")}, "is not below"},
+	}
+	for _, c := range cases {
+		err = SnmpSettingWrite(voltage, c.data)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Fatalf("Expected error %q for %+v, got %v", c.expected, c.data, err)
+		}
+	}
+
+	if err = SnmpSettingWrite(voltage, &sdk.WriteData{Action: SettingAction, Data: []byte(" 470\n")}); err != nil {
+		t.Fatal(err)
+	}
+	readings, err := SnmpSettingRead(voltage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readings[0].Value != float32(470) {
+		t.Fatalf("Expected 470 V after the write, got %+v", readings[0])
+	}
+
+	// Writes are in the units of the reading, and bounds in those of the
+	// agent.
+	frequencyData, err := core.MergeMapStringInterface(agent, map[string]interface{}{
+		"oid":        ".1.3.6.1.2.1.33.1.9.4.0", // upsConfigOutputFreq
+		"writable":   true,
+		"multiplier": float32(0.1),
+		"below_oid":  ".1.3.6.1.2.1.33.1.9.2.0", // upsConfigInputFreq
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frequency := &sdk.Device{Kind: "setting", Info: "upsConfigOutputFreq", Data: frequencyData}
+	defer client.SetInteger(".1.3.6.1.2.1.33.1.9.4.0", 600) // nolint: errcheck
+	err = SnmpSettingWrite(frequency, &sdk.WriteData{Action: SettingAction, Data: []byte("60")})
+	if err == nil || !strings.Contains(err.Error(), "60 is not below") || !strings.HasSuffix(err.Error(), " 60") {
+		t.Fatalf("Expected 60 Hertz not below the input frequency of 60, got %v", err)
+	}
+	if err = SnmpSettingWrite(frequency, &sdk.WriteData{Action: SettingAction, Data: []byte("50")}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.Get(".1.3.6.1.2.1.33.1.9.4.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := result.Int64(); value != 500 {
+		t.Fatalf("Expected 500 in 0.1 Hertz, got %v", result.Data)
+	}
+
+	// Devices without writable data are not written.
+	readOnlyData, err := core.MergeMapStringInterface(agent, map[string]interface{}{"oid": ".1.3.6.1.2.1.33.1.9.4.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	readOnly := &sdk.Device{Kind: "setting", Info: "upsConfigOutputFreq", Data: readOnlyData}
+	if err = SnmpSettingWrite(readOnly, &sdk.WriteData{Action: SettingAction, Data: []byte("50")}); err == nil {
+		t.Fatal("Expected an error for a device that is not writable")
+	}
+}
+
+// TestSettingValueEnumeration writes enumeration labels.
+func TestSettingValueEnumeration(t *testing.T) {
+	err := core.RegisterEnumeration(&core.Enumeration{
+		Name:   "TEST-MIB::audibleStatus",
+		Labels: map[int64]string{1: "disabled", 2: "enabled", 3: "muted"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{"enumeration": "TEST-MIB::audibleStatus"}
+
+	value, err := settingValue(nil, "muted", data)
+	if err != nil || value != 3 {
+		t.Fatalf("Expected 3 for muted, got %v, %v", value, err)
+	}
+	if _, err = settingValue(nil, "loud", data); err == nil {
+		t.Fatal("Expected an error for an unknown label")
+	}
+}
+
+// TestSettingHandlers checks that only the setting handler writes.
+func TestSettingHandlers(t *testing.T) {
+	for _, handler := range []*sdk.DeviceHandler{&SnmpVoltage, &SnmpStatus, &SnmpDuration} {
+		if handler.Write != nil {
+			t.Fatalf("Expected no write for the %v handler", handler.Name)
+		}
+	}
+	if SnmpSetting.Write == nil {
+		t.Fatal("Expected a write for the setting handler")
+	}
+
+	device := &sdk.Device{Kind: "setting", Info: "upsConfigOutputFreq", Outputs: []*sdk.Output{{OutputType: outputs.Frequency}}}
+	if _, err := SnmpSettingRead(device); err == nil || !strings.Contains(err.Error(), "unsupported setting output") {
+		t.Fatalf("Expected an error for a frequency setting, got %v", err)
+	}
+}
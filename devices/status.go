@@ -14,55 +14,36 @@ var SnmpStatus = sdk.DeviceHandler{
 }
 
 // SnmpStatusRead is the read handler function for snmp-status devices.
-func SnmpStatusRead(device *sdk.Device) (readings []*sdk.Reading, err error) { // nolint: gocyclo
+func SnmpStatusRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
 
 	// Arg checks.
 	if device == nil {
 		return nil, fmt.Errorf("device is nil")
 	}
 
-	// Get the SNMP device config from the strings in data.
+	// Get the SnmpClient of the agent the device refers to.
 	data := device.Data
-	snmpConfig, err := core.GetDeviceConfig(data)
+	snmpClient, err := core.GetClient(data)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create SnmpClient.
-	snmpClient, err := core.NewSnmpClient(snmpConfig)
+	// Read the SNMP OID in the device config.
+	result, err := snmpClient.Get(fmt.Sprint(data["oid"]))
 	if err != nil {
 		return nil, err
 	}
 
-	// Read the SNMP OID in the device config.
-	result, err := snmpClient.Get(fmt.Sprint(data["oid"]))
+	// Any type is reported as a string. Null is the empty string.
+	resultString, err := FormatReading(snmpClient, result, data)
 	if err != nil {
 		return nil, err
 	}
-
-	// Should be a string.
-	resultString := "" // Default reading for nil.
-	if result.Data != nil {
-		var ok bool
-		resultString, ok = result.Data.(string)
-		if !ok {
-			// Could be an int as well.
-			var resultInt int
-			resultInt, ok = result.Data.(int)
-			if !ok {
-				return nil, fmt.Errorf(
-					"Expected string or int status reading, got type: %T, value: %v",
-					result.Data, result.Data)
-			}
-			// An Int could be an enumeration.
-			if IsEnumeration(data) {
-				resultString, err = TranslateEnumeration(result, data)
-				if err != nil {
-					return nil, err
-				}
-			} else {
-				resultString = fmt.Sprintf("%d", resultInt)
-			}
+	if !result.IsNull() && IsEnumeration(data) {
+		// An integer could be an enumeration.
+		resultString, err = TranslateEnumeration(result, data)
+		if err != nil {
+			return nil, err
 		}
 	}
 	// Create the reading.
@@ -21,31 +21,28 @@ func SnmpVoltageRead(device *sdk.Device) (readings []*sdk.Reading, err error) {
 		return nil, fmt.Errorf("device is nil")
 	}
 
-	// Get the SNMP device config from the strings in data.
+	// Get the SnmpClient of the agent the device refers to.
 	data := device.Data
-	snmpConfig, err := core.GetDeviceConfig(data)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create SnmpClient.
-	snmpClient, err := core.NewSnmpClient(snmpConfig)
+	snmpClient, err := core.GetClient(data)
 	if err != nil {
 		return nil, err
 	}
 
 	// Read the SNMP OID in the device config.
-	result, err := snmpClient.Get(fmt.Sprint(data["oid"]))
+	result, err := GetReading(snmpClient, data)
 	if err != nil {
 		return nil, err
 	}
 
-	// Account for a multiplier if any and convert to float.
-	var resultFloat float32
-	resultFloat, err = MultiplyReading(result, data)
+	// Account for a counter and a multiplier if any and convert to float.
+	resultFloat, ok, err := ScaleReading(snmpClient, result, data)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		// A counter needs a previous sample before it has a reading.
+		return []*sdk.Reading{}, nil
+	}
 
 	// Create the reading.
 	reading, err := device.GetOutput("voltage").MakeReading(resultFloat)
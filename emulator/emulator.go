@@ -0,0 +1,68 @@
+// Package emulator is the emulator agent of the tests: its data files in
+// data/ and the settings to talk to it. The tests replay the data in memory,
+// or talk to the snmpsim emulator on 127.0.0.1:1024 with SNMP_TEST_EMULATOR
+// set. See make test-emulator.
+//
+// The package does not import the plugin packages, so that the tests of any
+// of them can use it.
+package emulator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// Settings of the emulator agent.
+const (
+	Endpoint                 = "127.0.0.1"
+	Port                     = 1024
+	ContextName              = "public"
+	UserName                 = "simulator"
+	AuthenticationPassphrase = "auctoritas"
+	PrivacyPassphrase        = "privatus"
+)
+
+// AgentData returns the dynamicRegistration entry of the emulator agent, SNMP
+// v3 with SHA and AES, with the keys of each map in keys added, such as a
+// model or the devices of the entry. Each call returns a new map.
+func AgentData(keys ...map[string]interface{}) map[string]interface{} {
+	data := map[string]interface{}{
+		"version":                  "v3",
+		"endpoint":                 Endpoint,
+		"port":                     Port,
+		"userName":                 UserName,
+		"authenticationProtocol":   "SHA",
+		"authenticationPassphrase": AuthenticationPassphrase,
+		"privacyProtocol":          "AES",
+		"privacyPassphrase":        PrivacyPassphrase,
+		"contextName":              ContextName,
+	}
+	for _, m := range keys {
+		for key, value := range m {
+			data[key] = value
+		}
+	}
+	return data
+}
+
+// DataDir returns the directory of the emulator data files.
+func DataDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "data")
+}
+
+// Main runs the tests of a package, for a TestMain. Unless SNMP_TEST_EMULATOR
+// is set, replay is first called with DataDir to replay the data in memory,
+// such as with core.UseReplayTransport.
+func Main(m *testing.M, replay func(dir string) error) {
+	if os.Getenv("SNMP_TEST_EMULATOR") == "" {
+		if err := replay(DataDir()); err != nil {
+			fmt.Printf("Failed to load emulator data: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(m.Run())
+}
@@ -72,4 +72,62 @@ var (
 			Symbol: "A",
 		},
 	}
+
+	// Percentage describes readings with percentage outputs, such as the
+	// load of a UPS output line or the charge remaining in its battery.
+	Percentage = sdk.OutputType{
+		Name:      "percentage",
+		Precision: 3,
+		Unit: sdk.Unit{
+			Name:   "percent",
+			Symbol: "%",
+		},
+	}
+
+	// SecondsDuration describes readings with duration (seconds) outputs.
+	SecondsDuration = sdk.OutputType{
+		Name:      "seconds.duration",
+		Precision: 3,
+		Unit: sdk.Unit{
+			Name:   "seconds",
+			Symbol: "s",
+		},
+	}
+
+	// MinutesDuration describes readings with duration (minutes) outputs.
+	MinutesDuration = sdk.OutputType{
+		Name:      "minutes.duration",
+		Precision: 3,
+		Unit: sdk.Unit{
+			Name:   "minutes",
+			Symbol: "min",
+		},
+	}
+
+	// Count describes readings that count things, such as the number of
+	// output lines or present alarms of a UPS.
+	Count = sdk.OutputType{
+		Name: "count",
+	}
+
+	// Ratio describes readings that are a ratio of two values without a unit,
+	// such as a power factor or an efficiency.
+	Ratio = sdk.OutputType{
+		Name:      "ratio",
+		Precision: 3,
+	}
+
+	// Inventory describes readings with the identity of an agent and its
+	// equipment as a JSON record, for an asset system.
+	Inventory = sdk.OutputType{
+		Name: "inventory",
+	}
+
+	// Raw describes readings of OIDs declared in the configuration without a
+	// specific device kind. The reading is a number or a string, depending on
+	// the SNMP type.
+	Raw = sdk.OutputType{
+		Name:      "raw",
+		Precision: 3,
+	}
 )
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+	"os"
 
 	logger "github.com/Sirupsen/logrus"
 
@@ -10,7 +12,10 @@ import (
 	"github.com/vapor-ware/synse-snmp-plugin/devices"
 	"github.com/vapor-ware/synse-snmp-plugin/outputs"
 	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/debug"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/metrics"
 	"github.com/vapor-ware/synse-snmp-plugin/snmp/servers"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/smi"
 )
 
 const (
@@ -20,42 +25,20 @@ const (
 	pluginVcs        = "https://github.com/vapor-ware/synse-snmp-plugin"
 )
 
-// mapOidsToInstances creates a map of SNMP OID to device instances and a list
-// of OIDs so that Synse can determine the sort order for SNMP devices in a
-// scan. In this case the OID is a string.
-func mapOidsToInstances(deviceConfigs []*sdk.DeviceConfig) (oidMap map[string]*sdk.DeviceInstance, oidList []string, err error) {
-
-	oidMap = map[string]*sdk.DeviceInstance{}
-	// Iterate from the device config to each device instance.
-	for i := 0; i < len(deviceConfigs); i++ {
-		devices := deviceConfigs[i].Devices
-		for j := 0; j < len(devices); j++ {
-			device := devices[j]
-			for k := 0; k < len(device.Instances); k++ {
-				instance := device.Instances[k]
-
-				// Check for errors and add the oid as a string and a pointer to the
-				// instance to the map value.
-				oidData, ok := instance.Data["oid"]
-				if !ok {
-					return nil, []string{}, fmt.Errorf(
-						"oid is not a key in instance data, instance.Data: %+v", instance.Data)
-				}
-				oidStr, ok := oidData.(string)
-				if !ok {
-					return nil, []string{}, fmt.Errorf("oid data is not a string, %T, %+v", oidData, oidData)
-				}
-				_, exists := oidMap[oidStr]
-				if exists {
-					return nil, []string{}, fmt.Errorf("oid %v already exists. Should not be duplicated", oidStr)
-				}
-				oidMap[oidStr] = instance
-				oidList = append(oidList, oidStr)
-			}
-		}
-	}
-	return oidMap, oidList, nil
-}
+const (
+	// mibPathEnv is the environment variable for the directory of MIB files.
+	mibPathEnv = "PLUGIN_MIB_PATH"
+	// defaultMibPath is the directory of MIB files if mibPathEnv is not set.
+	defaultMibPath = "mibs"
+	// metricsAddressEnv is the environment variable for the address of the
+	// /metrics endpoint, for example :2112. There is no endpoint if it is not
+	// set.
+	metricsAddressEnv = "PLUGIN_METRICS_ADDRESS"
+	// debugAddressEnv is the environment variable for the address of the
+	// /debug endpoints, which may be the metrics address. There are no
+	// endpoints if it is not set.
+	debugAddressEnv = "PLUGIN_DEBUG_ADDRESS"
+)
 
 // deviceIdentifier defines the SNMP-specific way of uniquely identifying a
 // device through its device configuration.
@@ -63,49 +46,119 @@ func mapOidsToInstances(deviceConfigs []*sdk.DeviceConfig) (oidMap map[string]*s
 // we need to support the entity mib and entity sensor mib where joins may be
 // required.
 func deviceIdentifier(data map[string]interface{}) string {
-	return fmt.Sprint(data["oid"])
-}
+	// An alarm is identified by the OID of the device it is on and its name.
+	if alarmOid, ok := data["alarm_oid"]; ok {
+		id := deviceIdentifier(map[string]interface{}{"oid": alarmOid}) + "/alarm"
+		if name := devices.AlarmName(data); name != "" {
+			id += "/" + name
+		}
+		return id
+	}
 
-// deviceEnumerator allows the sdk to enumerate devices.
-func deviceEnumerator(data map[string]interface{}) (deviceConfigs []*sdk.DeviceConfig, err error) {
-	// Load the MIB from the configuration still.
-	// Factory class for initializing servers via config is TODO:
-	logger.Info("SNMP Plugin initializing UPS.")
-	pxgmsUps, err := servers.NewPxgmsUps(data)
+	// A computed device is identified by its expression.
+	if expression, ok := data["expression"]; ok {
+		return fmt.Sprint(expression)
+	}
+
+	// The inventory device is identified by its agent.
+	if _, ok := data[devices.InventoryKey]; ok {
+		return fmt.Sprint(data[core.AgentKey]) + "/inventory"
+	}
+
+	// A row device is identified by its lowest OID.
+	if sortOid, err := core.RowSortOid(data); err == nil {
+		return sortOid + "/row"
+	}
+
+	// Symbolic and numeric forms of the same OID are the same device.
+	oid := fmt.Sprint(data["oid"])
+	numericOid, err := core.ResolveOid(oid)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to create NewPxgmUps: %v", err)
+		return oid
+	}
+	return numericOid
+}
+
+// loadMibs loads the MIB files in the MIB path so that OIDs can be given and
+// displayed by name, for example UPS-MIB::upsBatteryStatus.0.
+func loadMibs() {
+	mibPath := os.Getenv(mibPathEnv)
+	if mibPath == "" {
+		mibPath = defaultMibPath
 	}
-	logger.Infof("Initialized PxgmsUps: %+v\n", pxgmsUps)
 
-	// First get a map of each OID to each device instance.
-	oidMap, oidList, err := mapOidsToInstances(pxgmsUps.DeviceConfigs)
+	mibs := smi.NewMibs()
+	err := mibs.LoadDir(mibPath)
 	if err != nil {
-		return nil, err
+		// Not fatal. Numeric OIDs and whatever did load still work.
+		logger.Warnf("SNMP Plugin unable to load all MIBs: %v", err)
+	}
+	logger.Infof("SNMP Plugin loaded MIB modules from %v: %v", mibPath, mibs.ModuleNames())
+	core.SetOidResolver(mibs)
+}
+
+// serveHTTP serves the plugin metrics in the Prometheus format on /metrics
+// if metricsAddressEnv is set, and the debug endpoints on /debug if
+// debugAddressEnv is set. Both are served by one server if their addresses
+// are the same.
+func serveHTTP() {
+	muxes := map[string]*http.ServeMux{}
+	handle := func(env string, pattern string, handler http.Handler) {
+		address := os.Getenv(env)
+		if address == "" {
+			return
+		}
+		if _, ok := muxes[address]; !ok {
+			muxes[address] = http.NewServeMux()
+		}
+		muxes[address].Handle(pattern, handler)
+		logger.Infof("SNMP Plugin serving %v on %v", pattern, address)
+	}
+	handle(metricsAddressEnv, "/metrics", metrics.Default.Handler())
+	handle(debugAddressEnv, "/debug/", debug.Handler())
+
+	for address, mux := range muxes {
+		go func(address string, mux *http.ServeMux) {
+			// Not fatal. The plugin still reads devices without these.
+			if err := http.ListenAndServe(address, mux); err != nil {
+				logger.Errorf("SNMP Plugin unable to serve HTTP on %v: %v", address, err)
+			}
+		}(address, mux)
 	}
+}
 
-	// Create an OidTrie and sort it.
-	oidTrie, err := core.NewOidTrie(&oidList)
+// deviceEnumerator allows the sdk to enumerate devices.
+func deviceEnumerator(data map[string]interface{}) (deviceConfigs []*sdk.DeviceConfig, err error) {
+	// Register the agent so that devices refer to it by ID, including devices
+	// in device config files, for example devices exported with snmpctl export.
+	agent, err := core.RegisterAgent(data)
 	if err != nil {
 		return nil, err
 	}
-	sorted, err := oidTrie.Sort()
+	if enumerate, ok := data[core.EnumerateKey].(bool); ok && !enumerate {
+		logger.Infof("SNMP Plugin not enumerating agent %v. Its devices are in device config files", agent.ID)
+		return []*sdk.DeviceConfig{}, nil
+	}
+
+	deviceConfigs, err = servers.EnumerateDevices(data)
 	if err != nil {
 		return nil, err
 	}
-
-	// Shim in the sort ordinal to the DeviceInstance Data.
-	for ordinal := 0; ordinal < len(sorted); ordinal++ { // Zero based in list.
-		oidMap[sorted[ordinal].ToString].SortOrdinal = int32(ordinal + 1) // One based sort ordinal.
+	if err = servers.CheckDeviceIDs(agent.ID, deviceConfigs, deviceIdentifier); err != nil {
+		return nil, err
 	}
+	agent.SetDevices(deviceConfigs)
 
 	// Dump SNMP device configurations.
-	core.DumpDeviceConfigs(pxgmsUps.DeviceConfigs)
-	return pxgmsUps.DeviceConfigs, nil
+	core.DumpDeviceConfigs(deviceConfigs)
+	return deviceConfigs, nil
 }
 
 func main() {
 	logger.SetLevel(logger.DebugLevel)
 	logger.Info("SNMP Plugin start")
+	loadMibs()
+	serveHTTP()
 	// Set the plugin metadata
 	sdk.SetPluginMeta(
 		pluginName,
@@ -136,6 +189,13 @@ func main() {
 		&outputs.Status,
 		&outputs.Temperature,
 		&outputs.Voltage,
+		&outputs.Raw,
+		&outputs.Ratio,
+		&outputs.Percentage,
+		&outputs.SecondsDuration,
+		&outputs.MinutesDuration,
+		&outputs.Count,
+		&outputs.Inventory,
 	)
 	if err != nil {
 		logger.Fatal(err)
@@ -143,7 +203,7 @@ func main() {
 
 	// Register Device Handlers for all supported devices we interact with over SNMP.
 	logger.Info("SNMP Plugin registering device handlers")
-	plugin.RegisterDeviceHandlers(
+	plugin.RegisterDeviceHandlers(devices.Instrument(
 		&devices.SnmpCurrent,
 		&devices.SnmpFrequency,
 		&devices.SnmpIdentity,
@@ -151,7 +211,16 @@ func main() {
 		&devices.SnmpStatus,
 		&devices.SnmpTemperature,
 		&devices.SnmpVoltage,
-	)
+		&devices.SnmpRaw,
+		&devices.SnmpAlarm,
+		&devices.SnmpComputed,
+		&devices.SnmpPercentage,
+		&devices.SnmpDuration,
+		&devices.SnmpCount,
+		&devices.SnmpRow,
+		&devices.SnmpInventory,
+		&devices.SnmpSetting,
+	)...)
 
 	// Run the plugin.
 	logger.Info("SNMP Plugin running plugin")
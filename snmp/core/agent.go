@@ -0,0 +1,281 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+)
+
+// AgentKey is the device data key of an agent reference. Device data with an
+// agent reference, such as in exported device config files, has no connection
+// settings or credentials of its own. GetClient and GetDeviceConfig take them
+// from the registered agent.
+const AgentKey = "agent"
+
+// EnumerateKey is the dynamicRegistration key that turns device enumeration
+// off for an agent when false. The agent is still registered, so that device
+// config files can refer to it.
+const EnumerateKey = "enumerate"
+
+// TimeoutKey is the optional device data key of the timeout of each SNMP
+// request to an agent, a duration such as 5s.
+const TimeoutKey = "timeout"
+
+// AgentKeys are the device data keys of the connection settings and
+// credentials of an agent, including the credential files and environment
+// variables.
+var AgentKeys = append([]string{
+	"version",
+	"endpoint",
+	"port",
+	"contextName",
+	"userName",
+	"authenticationProtocol",
+	"authenticationPassphrase",
+	"privacyProtocol",
+	"privacyPassphrase",
+	TimeoutKey,
+}, credentialSourceKeys()...)
+
+// Agent is a registered SNMP agent. Devices hold the agent ID rather than its
+// connection settings, and reads use the agent's client, so the settings are
+// parsed once per agent. The agent also holds the state shared by its
+// devices: the MIBs it was enumerated with, the devices enumerated and its
+// health.
+type Agent struct {
+	ID           string                 // Agent ID. See AgentID.
+	Settings     map[string]interface{} // Connection settings as configured.
+	DeviceConfig *DeviceConfig          // Parsed connection settings.
+	Client       *SnmpClient            // Client for all devices of the agent.
+
+	mutex      sync.Mutex
+	mibs       []*SnmpMib
+	devices    []*sdk.DeviceConfig
+	enumerated time.Time
+	health     AgentHealth
+}
+
+// AgentHealth is the outcome of the SNMP requests to an agent.
+type AgentHealth struct {
+	Requests            uint64    // Requests made.
+	Failures            uint64    // Requests that failed.
+	ConsecutiveFailures uint64    // Failures since the last success.
+	LastSuccess         time.Time // Time of the last successful request.
+	LastFailure         time.Time // Time of the last failed request.
+	LastError           string    // Error of the last failed request.
+}
+
+// Healthy is true if the last request to the agent succeeded, or there were
+// none yet.
+func (health AgentHealth) Healthy() bool {
+	return health.ConsecutiveFailures == 0
+}
+
+// agents are the registered agents by agent ID.
+var agents = map[string]*Agent{}
+var agentsMutex sync.Mutex
+
+// AgentID returns the ID of the agent in a dynamicRegistration entry. This is
+// the agent key if the entry names the agent, and endpoint:port/contextName
+// otherwise.
+func AgentID(data map[string]interface{}) string {
+	if id, ok := data[AgentKey].(string); ok && id != "" {
+		return id
+	}
+	return fmt.Sprintf("%v:%v/%v", data["endpoint"], data["port"], data["contextName"])
+}
+
+// RegisterAgent registers the agent in a dynamicRegistration entry, parsing
+// its connection settings and creating its client. Registering an agent again
+// with the same settings returns the registered agent, so that its MIBs and
+// health are kept. Different settings replace it.
+func RegisterAgent(data map[string]interface{}) (*Agent, error) {
+	id := AgentID(data)
+	settings := map[string]interface{}{}
+	for _, key := range AgentKeys {
+		if value, ok := data[key]; ok {
+			settings[key] = value
+		}
+	}
+
+	agentsMutex.Lock()
+	registered, ok := agents[id]
+	agentsMutex.Unlock()
+	if ok && reflect.DeepEqual(registered.Settings, settings) {
+		return registered, nil
+	}
+
+	deviceConfig, err := GetDeviceConfig(settings)
+	if err != nil {
+		return nil, fmt.Errorf("SNMP agent %v: %v", id, err)
+	}
+	deviceConfig.AgentID = id
+	client, err := NewSnmpClient(deviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	agent := &Agent{
+		ID:           id,
+		Settings:     settings,
+		DeviceConfig: deviceConfig,
+		Client:       client,
+	}
+
+	agentsMutex.Lock()
+	defer agentsMutex.Unlock()
+	agents[id] = agent
+	return agent, nil
+}
+
+// LookupAgent returns the registered agent with the ID, or nil if there is
+// none.
+func LookupAgent(id string) *Agent {
+	agentsMutex.Lock()
+	defer agentsMutex.Unlock()
+	return agents[id]
+}
+
+// Agents returns the registered agents sorted by ID.
+func Agents() []*Agent {
+	agentsMutex.Lock()
+	defer agentsMutex.Unlock()
+	list := make([]*Agent, 0, len(agents))
+	for _, agent := range agents {
+		list = append(list, agent)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// AddMib adds a MIB the agent was enumerated with. It replaces a MIB with the
+// same name.
+func (agent *Agent) AddMib(mib *SnmpMib) {
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+	for i, existing := range agent.mibs {
+		if existing.Name == mib.Name {
+			agent.mibs[i] = mib
+			return
+		}
+	}
+	agent.mibs = append(agent.mibs, mib)
+}
+
+// Mibs returns the MIBs the agent was enumerated with.
+func (agent *Agent) Mibs() []*SnmpMib {
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+	return append([]*SnmpMib{}, agent.mibs...)
+}
+
+// SetDevices records the devices enumerated for the agent.
+func (agent *Agent) SetDevices(deviceConfigs []*sdk.DeviceConfig) {
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+	agent.devices = deviceConfigs
+	agent.enumerated = time.Now()
+}
+
+// Devices returns the devices last enumerated for the agent and the time of
+// the enumeration, which is zero if the agent was not enumerated.
+func (agent *Agent) Devices() ([]*sdk.DeviceConfig, time.Time) {
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+	return agent.devices, agent.enumerated
+}
+
+// Health returns the health of the agent.
+func (agent *Agent) Health() AgentHealth {
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+	return agent.health
+}
+
+// recordResult updates the health of the agent with the outcome of a request.
+func (agent *Agent) recordResult(err error) {
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+	agent.health.Requests++
+	if err == nil {
+		agent.health.ConsecutiveFailures = 0
+		agent.health.LastSuccess = time.Now()
+		return
+	}
+	agent.health.Failures++
+	agent.health.ConsecutiveFailures++
+	agent.health.LastFailure = time.Now()
+	agent.health.LastError = err.Error()
+}
+
+// recordAgentResult updates the health of the registered agent with the ID,
+// if there is one, with the outcome of a request.
+func recordAgentResult(id string, err error) {
+	if id == "" {
+		return
+	}
+	if agent := LookupAgent(id); agent != nil {
+		agent.recordResult(err)
+	}
+}
+
+// GetClient returns the SNMP client for device data. Device data that refers
+// to a registered agent gets the client of the agent without parsing any
+// settings. Device data with connection settings of its own gets a new
+// client.
+func GetClient(data map[string]interface{}) (*SnmpClient, error) {
+	if id, ok := data[AgentKey].(string); ok {
+		if _, ok = data["endpoint"]; !ok {
+			agent := LookupAgent(id)
+			if agent == nil {
+				return nil, unknownAgentError(id)
+			}
+			return agent.Client, nil
+		}
+	}
+
+	deviceConfig, err := GetDeviceConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewSnmpClient(deviceConfig)
+}
+
+// ReferenceAgent returns a copy of device data with the connection settings
+// and credentials replaced by a reference to the agent.
+func ReferenceAgent(data map[string]interface{}, id string) map[string]interface{} {
+	reference := CopyMapStringInterface(data)
+	for _, key := range AgentKeys {
+		delete(reference, key)
+	}
+	reference[AgentKey] = id
+	return reference
+}
+
+// resolveAgent returns device data with the connection settings of the agent
+// it refers to. Device data without an agent reference, or with connection
+// settings of its own, is returned as is.
+func resolveAgent(data map[string]interface{}) (map[string]interface{}, error) {
+	id, ok := data[AgentKey].(string)
+	if !ok {
+		return data, nil
+	}
+	if _, ok = data["endpoint"]; ok {
+		return data, nil
+	}
+
+	agent := LookupAgent(id)
+	if agent == nil {
+		return nil, unknownAgentError(id)
+	}
+	return MergeMapStringInterface(agent.Settings, data)
+}
+
+// unknownAgentError is the error for a reference to an agent that is not
+// registered.
+func unknownAgentError(id string) error {
+	return fmt.Errorf("Unknown SNMP agent %v. The agent must be in dynamicRegistration", id)
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+)
+
+// registryTestAgent is the emulator agent under its own agent ID.
+func registryTestAgent() map[string]interface{} {
+	data := emulator.AgentData()
+	data["agent"] = "registry-test"
+	return data
+}
+
+// TestRegisterAgent registers an agent and gets its client from an agent
+// reference.
+func TestRegisterAgent(t *testing.T) {
+	agent, err := RegisterAgent(registryTestAgent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent.ID != "registry-test" || agent.DeviceConfig.AgentID != "registry-test" {
+		t.Fatalf("Expected agent registry-test, got %v", agent.ID)
+	}
+	if LookupAgent("registry-test") != agent {
+		t.Fatal("Expected LookupAgent to return the registered agent")
+	}
+
+	// The same settings keep the agent, different settings replace it.
+	again, err := RegisterAgent(registryTestAgent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != agent {
+		t.Fatal("Expected registering the same settings to return the registered agent")
+	}
+	changed := registryTestAgent()
+	changed["port"] = 1161
+	replaced, err := RegisterAgent(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced == agent || LookupAgent("registry-test") != replaced {
+		t.Fatal("Expected registering different settings to replace the agent")
+	}
+	agent, err = RegisterAgent(registryTestAgent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := GetClient(map[string]interface{}{AgentKey: "registry-test", "oid": ".1.3.6.1.2.1.1.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client != agent.Client {
+		t.Fatal("Expected the client of the agent")
+	}
+	if _, err = GetClient(map[string]interface{}{AgentKey: "no-such-agent"}); err == nil {
+		t.Fatal("Expected an error for an unregistered agent")
+	}
+
+	bad := registryTestAgent()
+	bad["authenticationProtocol"] = "none"
+	if _, err = RegisterAgent(bad); err == nil {
+		t.Fatal("Expected an error for an unsupported authentication protocol")
+	}
+}
+
+// TestAgentHealth checks that requests update the health of the agent.
+func TestAgentHealth(t *testing.T) {
+	data := registryTestAgent()
+	data[AgentKey] = "health-test"
+	agent, err := RegisterAgent(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health := agent.Health(); !health.Healthy() || health.Requests != 0 {
+		t.Fatalf("Expected a healthy agent without requests, got %+v", health)
+	}
+
+	if _, err = agent.Client.Get(".1.3.6.1.2.1.1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	health := agent.Health()
+	if !health.Healthy() || health.Requests != 1 || health.LastSuccess.IsZero() {
+		t.Fatalf("Expected one successful request, got %+v", health)
+	}
+
+	agent.recordResult(fmt.Errorf("timeout"))
+	agent.recordResult(fmt.Errorf("timeout"))
+	health = agent.Health()
+	if health.Healthy() || health.ConsecutiveFailures != 2 || health.Failures != 2 ||
+		health.LastError != "timeout" {
+		t.Fatalf("Expected two failures, got %+v", health)
+	}
+	agent.recordResult(nil)
+	if health = agent.Health(); !health.Healthy() || health.Requests != 4 {
+		t.Fatalf("Expected a healthy agent after a success, got %+v", health)
+	}
+}
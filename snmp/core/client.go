@@ -5,8 +5,6 @@ import (
 	"strings"
 	"time"
 
-	log "github.com/Sirupsen/logrus"
-
 	"github.com/soniah/gosnmp"
 )
 
@@ -43,6 +41,26 @@ type SecurityParameters struct {
 	PrivacyPassphrase        string
 }
 
+// String formats the security parameters with the passphrases redacted, so
+// that logging them does not leak credentials.
+func (securityParameters *SecurityParameters) String() string {
+	if securityParameters == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf(
+		"&{AuthenticationProtocol:%v PrivacyProtocol:%v UserName:%v AuthenticationPassphrase:%v PrivacyPassphrase:%v}",
+		securityParameters.AuthenticationProtocol,
+		securityParameters.PrivacyProtocol,
+		securityParameters.UserName,
+		redactSecret(securityParameters.AuthenticationPassphrase),
+		redactSecret(securityParameters.PrivacyPassphrase))
+}
+
+// GoString is String for %#v.
+func (securityParameters *SecurityParameters) GoString() string {
+	return securityParameters.String()
+}
+
 // NewSecurityParameters constructs a SecurityParameters.
 func NewSecurityParameters(
 	userName string,
@@ -80,6 +98,23 @@ type DeviceConfig struct {
 	Timeout            time.Duration       // Timeout for the SNMP query.
 	SecurityParameters *SecurityParameters // SNMP V3 security parameters.
 	Port               uint16              // UDP port to connect to.
+	AgentID            string              // ID of the agent in device data. See AgentID.
+}
+
+// String formats the config with the passphrases redacted.
+func (deviceConfig *DeviceConfig) String() string {
+	if deviceConfig == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf(
+		"&{Version:%v Endpoint:%v ContextName:%v Timeout:%v SecurityParameters:%v Port:%v AgentID:%v}",
+		deviceConfig.Version,
+		deviceConfig.Endpoint,
+		deviceConfig.ContextName,
+		deviceConfig.Timeout,
+		deviceConfig.SecurityParameters,
+		deviceConfig.Port,
+		deviceConfig.AgentID)
 }
 
 // checkForEmptyString checks for an empty string variable and fails with an
@@ -130,8 +165,21 @@ func NewDeviceConfig(
 // that is missing and has a default value defined.
 // This is just a deserializer which creates a DeviceConfig from
 // map[string]string.
+// Instance data that refers to a registered agent gets the connection settings
+// of the agent. See RegisterAgent. Credentials may be given by file or
+// environment variable. See credentialKeys.
 func GetDeviceConfig(instanceData map[string]interface{}) (*DeviceConfig, error) { // nolint: gocyclo
 
+	agentID := AgentID(instanceData)
+	instanceData, err := resolveAgent(instanceData)
+	if err != nil {
+		return nil, err
+	}
+	instanceData, err = loadCredentials(instanceData)
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse out each field. The constructor call will check the parameters.
 	version, ok := instanceData["version"].(string)
 	if !ok {
@@ -228,12 +276,26 @@ func GetDeviceConfig(instanceData map[string]interface{}) (*DeviceConfig, error)
 	}
 
 	// Create the config.
-	return NewDeviceConfig(
+	deviceConfig, err := NewDeviceConfig(
 		version,
 		endpoint,
 		port,
 		securityParameters,
 		contextName)
+	if err != nil {
+		return nil, err
+	}
+	deviceConfig.AgentID = agentID
+
+	// The timeout is optional.
+	if t, ok := instanceData[TimeoutKey]; ok {
+		timeout, err := time.ParseDuration(fmt.Sprint(t))
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("%v should be a positive duration, such as 5s, got %v", TimeoutKey, t)
+		}
+		deviceConfig.Timeout = timeout
+	}
+	return deviceConfig, nil
 }
 
 // ToMap serializes DeviceConfig to map[string]interface{}.
@@ -266,6 +328,28 @@ func (deviceConfig *DeviceConfig) ToMap() (m map[string]interface{}, err error)
 	return m, nil
 }
 
+// AgentReference returns device data that refers to the agent of the config.
+// Device data of enumerated devices starts from it, so that the credentials
+// are held once per agent rather than copied into every device. The agent is
+// registered if it is not already.
+func (deviceConfig *DeviceConfig) AgentReference() (m map[string]interface{}, err error) {
+	settings, err := deviceConfig.ToMap()
+	if err != nil {
+		return nil, err
+	}
+	id := deviceConfig.AgentID
+	if id == "" {
+		id = AgentID(settings)
+	}
+	if LookupAgent(id) == nil {
+		settings[AgentKey] = id
+		if _, err = RegisterAgent(settings); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]interface{}{AgentKey: id}, nil
+}
+
 // SnmpClient is a thin wrapper around gosnmp.
 type SnmpClient struct {
 	DeviceConfig *DeviceConfig
@@ -283,141 +367,119 @@ func NewSnmpClient(deviceConfig *DeviceConfig) (*SnmpClient, error) {
 }
 
 // ReadResult is the result structure for any SNMP read.
+// Use the typed accessors in value.go rather than type asserting Data.
 type ReadResult struct {
-	Oid  string      // The SNMP OID read.
-	Data interface{} // The data for the OID. See gosnmp decodeValue() https://github.com/soniah/gosnmp/blob/master/helper.go#L67
+	Oid  string         // The SNMP OID read.
+	Type gosnmp.Asn1BER // The ASN.1 type of the data from the PDU.
+	Data interface{}    // The data for the OID. See gosnmp decodeValue() https://github.com/soniah/gosnmp/blob/master/helper.go#L67
 }
 
-// Get performs an SNMP get on the given OID.
+// Get performs an SNMP get on the given OID. The OID may be numeric or
+// symbolic, for example UPS-MIB::upsBatteryStatus.0.
 func (client *SnmpClient) Get(oid string) (result ReadResult, err error) {
 
-	goSnmp, err := client.createGoSNMP()
-	if err != nil {
-		return result, err
+	if client == nil {
+		return result, fmt.Errorf("client is nil")
 	}
 
-	oids := []string{oid}
-	snmpPacket, err := goSnmp.Get(oids)
-	err2 := goSnmp.Conn.Close() // Do not leak connection.
-
-	// Return first error.
+	numericOid, err := ResolveOid(oid)
 	if err != nil {
 		return result, err
 	}
-	if err2 != nil {
-		return result, err2
-	}
-
-	data := snmpPacket.Variables[0]
 
-	// If it looks like an ASCII string, try to translate it.
-	if data.Type == gosnmp.OctetString {
-		ascii, err := TranslatePrintableASCII(data.Value)
-		if err == nil {
-			data.Value = ascii
-		}
-		// err above is deliberately ignored here. SNMP does not differentiate
-		// between ASCII strings and byte array.
+	start := time.Now()
+	pdus, err := getTransport().Get(client.DeviceConfig, []string{numericOid})
+	recordRequest(client.DeviceConfig, "get", start, len(pdus), err)
+	if err != nil {
+		return result, fmt.Errorf("SNMP get %v failed: %v", OidDisplay(numericOid), err)
 	}
-
-	return ReadResult{
-		Oid:  data.Name,
-		Data: data.Value,
-	}, nil
+	if len(pdus) == 0 {
+		return result, fmt.Errorf("SNMP get %v failed: no variables in response", OidDisplay(numericOid))
+	}
+	return NewReadResult(pdus[0]), nil
 }
 
-// Walk performs an SNMP bulk walk on the given OID.
+// Walk performs an SNMP bulk walk on the given OID. The OID may be numeric or
+// symbolic, for example UPS-MIB::upsBattery.
 func (client *SnmpClient) Walk(rootOid string) (results []ReadResult, err error) {
 
-	goSnmp, err := client.createGoSNMP()
-	if err != nil {
-		return nil, err
+	if client == nil {
+		return nil, fmt.Errorf("client is nil")
 	}
 
-	resultSet, err := goSnmp.BulkWalkAll(rootOid)
-	err2 := goSnmp.Conn.Close() // Do not leak connection.
-
-	// Return first error.
+	numericOid, err := ResolveOid(rootOid)
 	if err != nil {
 		return nil, err
 	}
-	if err2 != nil {
-		return nil, err2
+
+	start := time.Now()
+	resultSet, err := getTransport().Walk(client.DeviceConfig, numericOid)
+	recordRequest(client.DeviceConfig, "walk", start, len(resultSet), err)
+	if err != nil {
+		return nil, fmt.Errorf("SNMP walk %v failed: %v", OidDisplay(numericOid), err)
 	}
 
 	// Package results.
 	for _, snmpPdu := range resultSet {
+		results = append(results, NewReadResult(snmpPdu))
+	}
+	return results, nil
+}
 
-		// If it looks like an ASCII string, try to translate it.
-		if snmpPdu.Type == gosnmp.OctetString {
-			ascii, err := TranslatePrintableASCII(snmpPdu.Value)
-			if err == nil {
-				snmpPdu.Value = ascii
-			}
-			// err above is deliberately ignored here. SNMP does not differentiate
-			// between ASCII strings and byte array.
-		}
+// SetInteger performs an SNMP set of an INTEGER on the given OID. The OID may
+// be numeric or symbolic, for example UPS-MIB::upsConfigLowBattTime.0.
+func (client *SnmpClient) SetInteger(oid string, value int) (err error) {
 
-		results = append(results, ReadResult{
-			Oid:  snmpPdu.Name,
-			Data: snmpPdu.Value,
-		})
+	if client == nil {
+		return fmt.Errorf("client is nil")
 	}
-	return results, nil
+
+	numericOid, err := ResolveOid(oid)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = getTransport().Set(client.DeviceConfig, []gosnmp.SnmpPDU{
+		{Name: numericOid, Type: gosnmp.Integer, Value: value},
+	})
+	recordRequest(client.DeviceConfig, "set", start, 1, err)
+	if err != nil {
+		return fmt.Errorf("SNMP set %v failed: %v", OidDisplay(numericOid), err)
+	}
+	return nil
 }
 
-// createGoSNMP is a helper to create gosnmp.GoSNMP from SnmpClient.
-// On success, the connection is open.
-func (client *SnmpClient) createGoSNMP() (*gosnmp.GoSNMP, error) {
+// GetMultiple performs an SNMP get on several OIDs in one request, such as the
+// columns of a table row. There is a result per OID, in order.
+func (client *SnmpClient) GetMultiple(oids []string) (results []ReadResult, err error) {
 
-	// Argument checks
 	if client == nil {
 		return nil, fmt.Errorf("client is nil")
 	}
+	if len(oids) == 0 {
+		return nil, fmt.Errorf("no OIDs to get")
+	}
 
-	// Map DeviceConfig parameters to gosnmp parameters.
-	securityParameters := client.DeviceConfig.SecurityParameters
-	var authProtocol gosnmp.SnmpV3AuthProtocol
-	var privProtocol gosnmp.SnmpV3PrivProtocol
-
-	if securityParameters.AuthenticationProtocol == MD5 {
-		authProtocol = gosnmp.MD5
-	} else if securityParameters.AuthenticationProtocol == SHA {
-		authProtocol = gosnmp.SHA
-	} else {
-		return nil, fmt.Errorf("Unsupported authentication protocol [%v]", securityParameters.AuthenticationProtocol)
+	numericOids := make([]string, len(oids))
+	for i, oid := range oids {
+		if numericOids[i], err = ResolveOid(oid); err != nil {
+			return nil, err
+		}
 	}
 
-	if securityParameters.PrivacyProtocol == DES {
-		privProtocol = gosnmp.DES
-	} else if securityParameters.PrivacyProtocol == AES {
-		privProtocol = gosnmp.AES
-	} else {
-		return nil, fmt.Errorf("Unsupported privacy protocol [%v]", securityParameters.PrivacyProtocol)
-	}
-
-	goSnmp := &gosnmp.GoSNMP{
-		Target:        client.DeviceConfig.Endpoint,
-		Port:          client.DeviceConfig.Port,
-		Version:       gosnmp.Version3,
-		Timeout:       client.DeviceConfig.Timeout,
-		SecurityModel: gosnmp.UserSecurityModel,
-		MsgFlags:      gosnmp.AuthPriv,
-		SecurityParameters: &gosnmp.UsmSecurityParameters{
-			UserName:                 client.DeviceConfig.SecurityParameters.UserName,
-			AuthenticationProtocol:   authProtocol,
-			AuthenticationPassphrase: client.DeviceConfig.SecurityParameters.AuthenticationPassphrase,
-			PrivacyProtocol:          privProtocol,
-			PrivacyPassphrase:        client.DeviceConfig.SecurityParameters.PrivacyPassphrase,
-		},
-		ContextName: client.DeviceConfig.ContextName,
-	}
-
-	// Connect
-	err := goSnmp.Connect()
+	start := time.Now()
+	pdus, err := getTransport().Get(client.DeviceConfig, numericOids)
+	recordRequest(client.DeviceConfig, "get", start, len(pdus), err)
 	if err != nil {
-		log.Error("gosnmp failed to connect")
-		return nil, fmt.Errorf("Failed to connect gosnmp: %+v", err)
+		return nil, fmt.Errorf("SNMP get %v failed: %v", OidDisplay(numericOids[0]), err)
+	}
+	if len(pdus) != len(numericOids) {
+		return nil, fmt.Errorf("SNMP get %v failed: %d variables in response for %d OIDs",
+			OidDisplay(numericOids[0]), len(pdus), len(numericOids))
 	}
-	return goSnmp, err
+	for _, pdu := range pdus {
+		results = append(results, NewReadResult(pdu))
+	}
+	return results, nil
 }
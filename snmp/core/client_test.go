@@ -4,28 +4,14 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
 )
 
 // TestClient is the initial positive test against the emulator.
 func TestClient(t *testing.T) {
-	// Create SecurityParameters for the config that should connect to the emulator.
-	securityParameters, err := NewSecurityParameters(
-		"simulator",  // User Name
-		SHA,          // Authentication Protocol
-		"auctoritas", // Authentication Passphrase
-		AES,          // Privacy Protocol
-		"privatus")   // Privacy Passphrase
-	if err != nil {
-		t.Fatal(err) // Fail the test.
-	}
-
-	// Create a config.
-	config, err := NewDeviceConfig(
-		"v3",        // SNMP v3
-		"127.0.0.1", // Endpoint
-		1024,        // Port
-		securityParameters,
-		"public") //  Context name
+	// Create a config that connects to the emulator.
+	config, err := GetDeviceConfig(emulator.AgentData())
 	if err != nil {
 		t.Fatal(err) // Fail the test.
 	}
@@ -368,6 +354,35 @@ func TestValidConfigMapShaAesExtraField(t *testing.T) {
 	}
 }
 
+// TestConfigMapTimeout tests the optional timeout of an agent.
+func TestConfigMapTimeout(t *testing.T) {
+	yamlConfig := map[string]interface{}{
+		"version":                  "v3",
+		"endpoint":                 "127.0.0.1",
+		"port":                     1024,
+		"userName":                 "simulator",
+		"authenticationProtocol":   "SHA",
+		"authenticationPassphrase": "auctorias",
+		"privacyProtocol":          "AES",
+		"privacyPassphrase":        "privatus",
+		"contextName":              "public",
+		"timeout":                  "5s",
+	}
+	actual, err := GetDeviceConfig(yamlConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.Timeout != 5*time.Second {
+		t.Fatalf("Expected timeout 5s, got %v", actual.Timeout)
+	}
+
+	yamlConfig["timeout"] = "soon"
+	_, err = GetDeviceConfig(yamlConfig)
+	if err == nil {
+		t.Fatal("Expected failure for a timeout that is not a duration")
+	}
+}
+
 // TestDeviceConfigSerialization tests serialization to and from a map[string]string.
 func TestDeviceConfigSerialization(t *testing.T) {
 	// Create SecurityParameters for the config that should connect to the emulator.
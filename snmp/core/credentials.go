@@ -0,0 +1,113 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Redacted replaces secrets in logs, dumps and errors.
+const Redacted = "<redacted>"
+
+// SecretKeys are the device data keys of the secrets of an agent. Their
+// values are never logged.
+var SecretKeys = []string{
+	"authenticationPassphrase",
+	"privacyPassphrase",
+}
+
+// credentialKeys are the device data keys of the credentials of an agent.
+// Each can be given as is, or as the key with a File suffix and the path of a
+// file with the value, such as a mounted Kubernetes secret, or with an Env
+// suffix and the name of an environment variable with the value:
+//
+//	userName: simulator
+//	authenticationPassphraseFile: /etc/snmp/ups-1/auth
+//	privacyPassphraseEnv: UPS_1_PRIVACY_PASSPHRASE
+var credentialKeys = []string{
+	"userName",
+	"authenticationPassphrase",
+	"privacyPassphrase",
+}
+
+const (
+	// credentialFileSuffix is the suffix of the key of a credential file.
+	credentialFileSuffix = "File"
+	// credentialEnvSuffix is the suffix of the key of a credential
+	// environment variable.
+	credentialEnvSuffix = "Env"
+)
+
+// credentialSourceKeys are the keys of the credential files and environment
+// variables.
+func credentialSourceKeys() (keys []string) {
+	for _, key := range credentialKeys {
+		keys = append(keys, key+credentialFileSuffix, key+credentialEnvSuffix)
+	}
+	return keys
+}
+
+// loadCredentials returns device data with the credentials given by file or
+// environment variable loaded. Device data without any is returned as is.
+// Errors name the file or variable, never the value.
+func loadCredentials(data map[string]interface{}) (map[string]interface{}, error) {
+	loaded := data
+	copied := false
+	for _, key := range credentialKeys {
+		path, fromFile := data[key+credentialFileSuffix]
+		name, fromEnv := data[key+credentialEnvSuffix]
+		if !fromFile && !fromEnv {
+			continue
+		}
+		if _, ok := data[key]; ok || (fromFile && fromEnv) {
+			return nil, fmt.Errorf("Only one of %v, %v%v and %v%v may be set",
+				key, key, credentialFileSuffix, key, credentialEnvSuffix)
+		}
+
+		var value string
+		if fromFile {
+			contents, err := ioutil.ReadFile(fmt.Sprint(path))
+			if err != nil {
+				return nil, fmt.Errorf("Failed to read %v%v: %v", key, credentialFileSuffix, err)
+			}
+			// Files written with echo or editors end with a newline.
+			value = strings.TrimRight(string(contents), "\r\n")
+		} else {
+			var ok bool
+			value, ok = os.LookupEnv(fmt.Sprint(name))
+			if !ok {
+				return nil, fmt.Errorf("%v%v: environment variable %v is not set",
+					key, credentialEnvSuffix, name)
+			}
+		}
+
+		if !copied {
+			loaded = CopyMapStringInterface(data)
+			copied = true
+		}
+		loaded[key] = value
+	}
+	return loaded, nil
+}
+
+// RedactData returns a copy of device data with the secrets replaced by
+// Redacted, for logging.
+func RedactData(data map[string]interface{}) map[string]interface{} {
+	redacted := CopyMapStringInterface(data)
+	for _, key := range SecretKeys {
+		if _, ok := redacted[key]; ok {
+			redacted[key] = Redacted
+		}
+	}
+	return redacted
+}
+
+// redactSecret returns Redacted for a secret that is set, and nothing for
+// one that is not, so that logs still show whether it is set.
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return Redacted
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+)
+
+// credentialData is agent data without the passphrases.
+func credentialData() map[string]interface{} {
+	data := emulator.AgentData()
+	delete(data, "authenticationPassphrase")
+	delete(data, "privacyPassphrase")
+	return data
+}
+
+// TestCredentialsFromFileAndEnv loads the passphrases from a file and an
+// environment variable.
+func TestCredentialsFromFileAndEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	path := filepath.Join(dir, "auth")
+	if err = ioutil.WriteFile(path, []byte(emulator.AuthenticationPassphrase+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Setenv("TEST_SNMP_PRIVACY_PASSPHRASE", emulator.PrivacyPassphrase); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("TEST_SNMP_PRIVACY_PASSPHRASE") // nolint: errcheck
+
+	data := credentialData()
+	data["authenticationPassphraseFile"] = path
+	data["privacyPassphraseEnv"] = "TEST_SNMP_PRIVACY_PASSPHRASE"
+	config, err := GetDeviceConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.SecurityParameters.AuthenticationPassphrase != emulator.AuthenticationPassphrase ||
+		config.SecurityParameters.PrivacyPassphrase != emulator.PrivacyPassphrase {
+		t.Fatalf("Expected the passphrases from the file and environment, got %q and %q",
+			config.SecurityParameters.AuthenticationPassphrase, config.SecurityParameters.PrivacyPassphrase)
+	}
+	if _, ok := data["authenticationPassphrase"]; ok {
+		t.Fatal("Expected the device data to be unchanged")
+	}
+}
+
+// TestCredentialErrors checks that credential errors name the source and not
+// the value.
+func TestCredentialErrors(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"missing file": {
+			"authenticationPassphraseFile": "/nonexistent/auth",
+			"privacyPassphrase":            emulator.PrivacyPassphrase,
+		},
+		"unset variable": {
+			"authenticationPassphrase": emulator.AuthenticationPassphrase,
+			"privacyPassphraseEnv":     "TEST_SNMP_UNSET_VARIABLE",
+		},
+		"value and file": {
+			"authenticationPassphrase":     emulator.AuthenticationPassphrase,
+			"authenticationPassphraseFile": "/nonexistent/auth",
+			"privacyPassphrase":            emulator.PrivacyPassphrase,
+		},
+	}
+	for name, credentials := range cases {
+		data, err := MergeMapStringInterface(credentialData(), credentials)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = GetDeviceConfig(data)
+		if err == nil {
+			t.Fatalf("%v: expected an error", name)
+		}
+		if strings.Contains(err.Error(), emulator.AuthenticationPassphrase) || strings.Contains(err.Error(), emulator.PrivacyPassphrase) {
+			t.Fatalf("%v: expected no passphrase in the error, got %v", name, err)
+		}
+	}
+}
+
+// TestRedaction checks that the passphrases are in no dump of the config or
+// device data.
+func TestRedaction(t *testing.T) {
+	data := credentialData()
+	data["authenticationPassphrase"] = emulator.AuthenticationPassphrase
+	data["privacyPassphrase"] = emulator.PrivacyPassphrase
+	config, err := GetDeviceConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewSnmpClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = MergeMapStringInterface(data, map[string]interface{}{"endpoint": "10.0.0.1"})
+	if err == nil {
+		t.Fatal("Expected an error for the duplicate endpoint")
+	}
+
+	dumps := []string{
+		fmt.Sprintf("%v", config),
+		fmt.Sprintf("%+v", config),
+		fmt.Sprintf("%+v", client),
+		fmt.Sprintf("%#v", config.SecurityParameters),
+		fmt.Sprintf("%+v", RedactData(data)),
+		err.Error(),
+	}
+	for _, dump := range dumps {
+		if strings.Contains(dump, emulator.AuthenticationPassphrase) || strings.Contains(dump, emulator.PrivacyPassphrase) {
+			t.Fatalf("Expected no passphrase in %v", dump)
+		}
+	}
+	if !strings.Contains(dumps[1], "AuthenticationPassphrase:"+Redacted) {
+		t.Fatalf("Expected the passphrase redacted in %v", dumps[1])
+	}
+}
+
+// TestAgentReference checks that device data refers to the agent rather than
+// holding its credentials.
+func TestAgentReference(t *testing.T) {
+	data := credentialData()
+	data["authenticationPassphrase"] = emulator.AuthenticationPassphrase
+	data["privacyPassphrase"] = emulator.PrivacyPassphrase
+	data[AgentKey] = "credentials-test"
+	config, err := GetDeviceConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference, err := config.AgentReference()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reference) != 1 || reference[AgentKey] != "credentials-test" {
+		t.Fatalf("Expected only the agent reference, got %v", reference)
+	}
+
+	resolved, err := GetDeviceConfig(reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.SecurityParameters.PrivacyPassphrase != emulator.PrivacyPassphrase || resolved.Endpoint != "127.0.0.1" {
+		t.Fatalf("Expected the agent settings, got %+v", resolved)
+	}
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultUnknownLabel is the label for values that are not in an enumeration.
+// This is not "unknown" since several MIBs define unknown as a label.
+const DefaultUnknownLabel = "undefined"
+
+// Enumeration is the set of labels of an enumerated INTEGER or BITS object.
+// Enumerations are registered in a catalog by name and referenced by name from
+// synse device data so that every device for the object reads the same labels.
+type Enumeration struct {
+	// Name of the enumeration. By convention the qualified name of the MIB
+	// object. Example: UPS-MIB::upsBatteryStatus
+	Name string
+	// Bits is true for BITS syntax, where Labels are keyed by bit number.
+	Bits bool
+	// Labels by value, or by bit number for BITS.
+	Labels map[int64]string
+	// Unknown is the label for values not in Labels. DefaultUnknownLabel if
+	// empty.
+	Unknown string
+}
+
+// enumerations is the catalog of enumerations by name.
+var enumerations = struct {
+	sync.RWMutex
+	byName map[string]*Enumeration
+}{
+	byName: map[string]*Enumeration{},
+}
+
+// RegisterEnumeration adds an enumeration to the catalog, or replaces the
+// existing one with the same name.
+func RegisterEnumeration(enumeration *Enumeration) error {
+	if enumeration == nil {
+		return fmt.Errorf("enumeration is nil")
+	}
+	if enumeration.Name == "" {
+		return fmt.Errorf("enumeration name is empty")
+	}
+	enumerations.Lock()
+	defer enumerations.Unlock()
+	enumerations.byName[enumeration.Name] = enumeration
+	return nil
+}
+
+// LookupEnumeration gets an enumeration by name, or nil if it is not known.
+func LookupEnumeration(name string) *Enumeration {
+	enumerations.RLock()
+	defer enumerations.RUnlock()
+	return enumerations.byName[name]
+}
+
+// EnumerationNames returns the names of all enumerations in the catalog in
+// sorted order.
+func EnumerationNames() []string {
+	enumerations.RLock()
+	defer enumerations.RUnlock()
+	names := make([]string, 0, len(enumerations.byName))
+	for name := range enumerations.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Label returns the label for an enumerated value, or the unknown label.
+func (enumeration *Enumeration) Label(value int64) string {
+	if label, ok := enumeration.Labels[value]; ok {
+		return label
+	}
+	return enumeration.unknownLabel()
+}
+
+// Value returns the value with a label, for writes. It is false if no value
+// has the label.
+func (enumeration *Enumeration) Value(label string) (int64, bool) {
+	for value, l := range enumeration.Labels {
+		if l == label {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// BitLabels decodes a BITS value to the labels of the set bits in bit order.
+// Bit 0 is the most significant bit of the first octet, RFC 2578 section 7.1.4.
+// Set bits without a label are reported as the unknown label with the bit
+// number. Example: undefined(9)
+func (enumeration *Enumeration) BitLabels(octets []byte) []string {
+	labels := []string{}
+	for i, octet := range octets {
+		for j := uint(0); j < 8; j++ {
+			if octet&(0x80>>j) == 0 {
+				continue
+			}
+			bit := int64(i*8) + int64(j)
+			if label, ok := enumeration.Labels[bit]; ok {
+				labels = append(labels, label)
+			} else {
+				labels = append(labels, fmt.Sprintf("%v(%d)", enumeration.unknownLabel(), bit))
+			}
+		}
+	}
+	return labels
+}
+
+// Translate translates a read result to its label. BITS are translated to the
+// comma separated labels of the set bits. Null is the empty string.
+func (enumeration *Enumeration) Translate(result ReadResult) (string, error) {
+	if result.IsNull() {
+		return "", nil
+	}
+
+	if enumeration.Bits {
+		octets, err := result.Bytes()
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(enumeration.BitLabels(octets), ","), nil
+	}
+
+	value, err := result.Int64()
+	if err != nil {
+		return "", err
+	}
+	return enumeration.Label(value), nil
+}
+
+// unknownLabel returns the label for values not in the enumeration.
+func (enumeration *Enumeration) unknownLabel() string {
+	if enumeration.Unknown == "" {
+		return DefaultUnknownLabel
+	}
+	return enumeration.Unknown
+}
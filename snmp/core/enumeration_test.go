@@ -0,0 +1,63 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/soniah/gosnmp"
+)
+
+// TestEnumerationTranslate checks enumerated INTEGER, BITS and unknown values.
+func TestEnumerationTranslate(t *testing.T) {
+	status := &Enumeration{
+		Name:   "TEST-MIB::testStatus",
+		Labels: map[int64]string{1: "ok", 2: "failed"},
+	}
+	flags := &Enumeration{
+		Name:    "TEST-MIB::testFlags",
+		Bits:    true,
+		Labels:  map[int64]string{0: "alpha", 1: "beta", 7: "gamma", 8: "delta"},
+		Unknown: "unknownFlag",
+	}
+	for _, enumeration := range []*Enumeration{status, flags} {
+		if err := RegisterEnumeration(enumeration); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if LookupEnumeration("TEST-MIB::testStatus") != status || LookupEnumeration("TEST-MIB::nothing") != nil {
+		t.Fatal("Unexpected enumeration lookup")
+	}
+
+	cases := []struct {
+		enumeration *Enumeration
+		pdu         gosnmp.SnmpPDU
+		expected    string
+	}{
+		{status, gosnmp.SnmpPDU{Name: ".1", Type: gosnmp.Integer, Value: 2}, "failed"},
+		{status, gosnmp.SnmpPDU{Name: ".2", Type: gosnmp.Integer, Value: 9}, DefaultUnknownLabel},
+		{status, gosnmp.SnmpPDU{Name: ".3", Type: gosnmp.NoSuchInstance}, ""},
+		{flags, gosnmp.SnmpPDU{Name: ".4", Type: gosnmp.OctetString, Value: []byte{0xc1}}, "alpha,beta,gamma"},
+		{flags, gosnmp.SnmpPDU{Name: ".5", Type: gosnmp.OctetString, Value: []byte{0x00, 0xc0}}, "delta,unknownFlag(9)"},
+		{flags, gosnmp.SnmpPDU{Name: ".6", Type: gosnmp.OctetString, Value: []byte{}}, ""},
+	}
+	for _, c := range cases {
+		actual, err := c.enumeration.Translate(NewReadResult(c.pdu))
+		if err != nil {
+			t.Fatalf("%v: %v", c.pdu.Name, err)
+		}
+		if actual != c.expected {
+			t.Fatalf("%v: expected [%v], got [%v]", c.pdu.Name, c.expected, actual)
+		}
+	}
+
+	// Enumerated INTEGER needs an integer, BITS needs octets.
+	if _, err := status.Translate(NewReadResult(gosnmp.SnmpPDU{Name: ".7", Type: gosnmp.OctetString, Value: []byte("x")})); err == nil {
+		t.Fatal("Expected error translating an OctetString as INTEGER")
+	}
+	if _, err := flags.Translate(NewReadResult(gosnmp.SnmpPDU{Name: ".8", Type: gosnmp.Integer, Value: 1})); err == nil {
+		t.Fatal("Expected error translating an Integer as BITS")
+	}
+
+	if err := RegisterEnumeration(&Enumeration{}); err == nil {
+		t.Fatal("Expected error registering an enumeration without a name")
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ExportDeviceConfigs merges the device configs enumerated for an agent into
+// one device config for a device config file. The connection settings and
+// credentials in the device data are replaced by a reference to the agent, so
+// the file holds no secrets. Device kinds with the same name, metadata and
+// outputs are merged.
+func ExportDeviceConfigs(deviceConfigs []*sdk.DeviceConfig, agentID string) *sdk.DeviceConfig {
+	exported := &sdk.DeviceConfig{
+		SchemeVersion: sdk.SchemeVersion{Version: "1.0"},
+		Locations:     []*sdk.LocationConfig{},
+		Devices:       []*sdk.DeviceKind{},
+	}
+
+	locations := map[string]bool{}
+	for _, deviceConfig := range deviceConfigs {
+		for _, location := range deviceConfig.Locations {
+			if !locations[location.Name] {
+				locations[location.Name] = true
+				exported.Locations = append(exported.Locations, location)
+			}
+		}
+
+		for _, kind := range deviceConfig.Devices {
+			var instances []*sdk.DeviceInstance
+			for _, instance := range kind.Instances {
+				copied := *instance
+				copied.Data = exportData(ReferenceAgent(instance.Data, agentID))
+				instances = append(instances, &copied)
+			}
+
+			merged := false
+			for _, existing := range exported.Devices {
+				if existing.Name == kind.Name && existing.HandlerName == kind.HandlerName &&
+					reflect.DeepEqual(existing.Metadata, kind.Metadata) &&
+					reflect.DeepEqual(existing.Outputs, kind.Outputs) {
+					existing.Instances = append(existing.Instances, instances...)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				copied := *kind
+				copied.Instances = instances
+				exported.Devices = append(exported.Devices, &copied)
+			}
+		}
+	}
+	return exported
+}
+
+// exportData converts float32 values in device data to the float64 they
+// print as, so that a multiplier of 0.1 is written as 0.1 and not
+// 0.10000000149011612.
+func exportData(data map[string]interface{}) map[string]interface{} {
+	for key, value := range data {
+		if f, ok := value.(float32); ok {
+			data[key], _ = strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+		}
+	}
+	return data
+}
+
+// WriteDeviceConfig writes a device config as a device config file.
+func WriteDeviceConfig(writer io.Writer, deviceConfig *sdk.DeviceConfig) error {
+	contents, err := yaml.Marshal(deviceConfig)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(contents)
+	return err
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// TestExportDeviceConfigs exports device configs, loads them back and reads
+// the agent settings through the agent reference.
+func TestExportDeviceConfigs(t *testing.T) {
+	agent := emulator.AgentData()
+	agent["model"] = "PXGMS UPS + EATON 93PM"
+	agent["agent"] = "ups-1"
+	agentConfig, err := GetDeviceConfig(agent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentData, err := agentConfig.ToMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kind := func(oid string, multiplier float32) *sdk.DeviceKind {
+		data, _ := MergeMapStringInterface(agentData, map[string]interface{}{
+			"oid":        oid,
+			"multiplier": multiplier,
+		})
+		return &sdk.DeviceKind{
+			Name:      "voltage",
+			Metadata:  map[string]string{"model": "PXGMS UPS + EATON 93PM"},
+			Outputs:   []*sdk.DeviceOutput{{Type: "voltage"}},
+			Instances: []*sdk.DeviceInstance{{Info: oid, Location: "snmp-location", Data: data, SortOrdinal: 1}},
+		}
+	}
+	location := &sdk.LocationConfig{Name: "snmp-location", Rack: &sdk.LocationData{Name: "site"}}
+	deviceConfigs := []*sdk.DeviceConfig{
+		{Locations: []*sdk.LocationConfig{location}, Devices: []*sdk.DeviceKind{kind(".1.3.6.1.2.1.33.1.2.5.0", 0.1)}},
+		{Locations: []*sdk.LocationConfig{location}, Devices: []*sdk.DeviceKind{kind(".1.3.6.1.2.1.33.1.3.3.1.3.1", 1)}},
+	}
+
+	var buffer bytes.Buffer
+	err = WriteDeviceConfig(&buffer, ExportDeviceConfigs(deviceConfigs, AgentID(agent)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := buffer.String()
+	for _, secret := range []string{emulator.AuthenticationPassphrase, emulator.PrivacyPassphrase, emulator.UserName, "endpoint"} {
+		if strings.Contains(text, secret) {
+			t.Fatalf("Expected no %v in the device config file:\n%v", secret, text)
+		}
+	}
+	if !strings.Contains(text, "multiplier: 0.1\n") {
+		t.Fatalf("Expected multiplier 0.1 in the device config file:\n%v", text)
+	}
+
+	loaded := sdk.DeviceConfig{}
+	if err = yaml.Unmarshal(buffer.Bytes(), &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Locations) != 1 || len(loaded.Devices) != 1 || len(loaded.Devices[0].Instances) != 2 {
+		t.Fatalf("Expected one location and one kind with two instances, got:\n%v", text)
+	}
+	data := loaded.Devices[0].Instances[0].Data
+
+	// The agent is unknown until the plugin registers it.
+	if _, err = GetDeviceConfig(data); err == nil {
+		t.Fatal("Expected an error for an unregistered agent")
+	}
+	registered, err := RegisterAgent(agent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registered.ID != "ups-1" {
+		t.Fatalf("Expected agent ups-1, got %v", registered.ID)
+	}
+	config, err := GetDeviceConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Endpoint != "127.0.0.1" || config.Port != 1024 ||
+		config.SecurityParameters.PrivacyPassphrase != emulator.PrivacyPassphrase {
+		t.Fatalf("Expected the agent settings, got %+v", config)
+	}
+}
+
+// TestAgentID checks the default agent ID.
+func TestAgentID(t *testing.T) {
+	id := AgentID(map[string]interface{}{"endpoint": "10.0.0.1", "port": 161, "contextName": "public"})
+	if id != "10.0.0.1:161/public" {
+		t.Fatalf("Expected 10.0.0.1:161/public, got %v", id)
+	}
+}
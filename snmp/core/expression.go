@@ -0,0 +1,388 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is an arithmetic expression over the values of an agent, for
+// computed devices. OIDs are in braces and may be symbolic. An OID reads as
+// the values under it, one per table row for a column, so that an expression
+// works for any number of rows:
+//
+//	sum({UPS-MIB::upsOutputPower})
+//	sum({.1.3.6.1.2.1.33.1.4.4.1.2} * {.1.3.6.1.2.1.33.1.4.4.1.3} * 0.1)
+//
+// The operators are +, -, * and / on rows with the same index, or on every
+// row with a single value. sum, avg, min and max take any number of arguments
+// and reduce all of their values to one.
+type Expression struct {
+	Text string   // The expression as parsed.
+	Oids []string // Numeric OIDs of the values, in order of appearance.
+	root exprNode
+}
+
+// ErrDivisionByZero is the error of an expression that divides by zero, such
+// as UPS efficiency with no input power. It is not wrapped, so that callers
+// can tell it from a failed read.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// exprValues are the values of an expression: rows by index, in OID order. A
+// single value has one row with an empty index.
+type exprValues struct {
+	indexes []string
+	values  []float64
+}
+
+// exprNode is a node in the syntax tree of an expression.
+type exprNode interface {
+	eval(reads map[string]exprValues) (exprValues, error)
+}
+
+// ParseExpression parses an expression. Symbolic OIDs are resolved here.
+func ParseExpression(text string) (*Expression, error) {
+	parser := &exprParser{text: text}
+	expression := &Expression{Text: text}
+	root, err := parser.parseSum(expression)
+	if err != nil {
+		return nil, fmt.Errorf("Bad expression [%v]: %v", text, err)
+	}
+	parser.skipSpace()
+	if parser.pos < len(parser.text) {
+		return nil, fmt.Errorf("Bad expression [%v]: unexpected %q at %d",
+			text, parser.text[parser.pos:], parser.pos)
+	}
+	expression.root = root
+	return expression, nil
+}
+
+// Evaluate reads the OIDs of the expression with the client and computes it.
+// The result must be a single value.
+func (expression *Expression) Evaluate(client *SnmpClient) (float64, error) {
+	reads := map[string]exprValues{}
+	for _, oid := range expression.Oids {
+		if _, ok := reads[oid]; ok {
+			continue
+		}
+		results, err := client.Walk(oid)
+		if err != nil {
+			return 0, err
+		}
+		read := exprValues{}
+		for _, result := range results {
+			if result.IsNull() {
+				continue
+			}
+			value, err := result.Float64()
+			if err != nil {
+				return 0, fmt.Errorf("%v: %v", OidDisplay(result.Oid), err)
+			}
+			read.indexes = append(read.indexes, strings.TrimPrefix(result.Oid, oid))
+			read.values = append(read.values, value)
+		}
+		if len(read.values) == 0 {
+			return 0, fmt.Errorf("No values for %v", OidDisplay(oid))
+		}
+		reads[oid] = read
+	}
+
+	result, err := expression.root.eval(reads)
+	if err != nil {
+		return 0, err
+	}
+	if len(result.values) != 1 {
+		return 0, fmt.Errorf("Expression [%v] has %d values. Use sum, avg, min or max",
+			expression.Text, len(result.values))
+	}
+	return result.values[0], nil
+}
+
+// exprParser is a recursive descent parser for expressions.
+type exprParser struct {
+	text string
+	pos  int
+}
+
+// skipSpace skips white space.
+func (parser *exprParser) skipSpace() {
+	for parser.pos < len(parser.text) && unicode.IsSpace(rune(parser.text[parser.pos])) {
+		parser.pos++
+	}
+}
+
+// peek returns the next character after white space, or 0 at the end.
+func (parser *exprParser) peek() byte {
+	parser.skipSpace()
+	if parser.pos >= len(parser.text) {
+		return 0
+	}
+	return parser.text[parser.pos]
+}
+
+// parseSum parses terms with + and -.
+func (parser *exprParser) parseSum(expression *Expression) (exprNode, error) {
+	left, err := parser.parseProduct(expression)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := parser.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		parser.pos++
+		right, err := parser.parseProduct(expression)
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{op: op, left: left, right: right}
+	}
+}
+
+// parseProduct parses factors with * and /.
+func (parser *exprParser) parseProduct(expression *Expression) (exprNode, error) {
+	left, err := parser.parseFactor(expression)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := parser.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		parser.pos++
+		right, err := parser.parseFactor(expression)
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{op: op, left: left, right: right}
+	}
+}
+
+// parseFactor parses a number, an OID, a function call, a parenthesized
+// expression or a negation.
+func (parser *exprParser) parseFactor(expression *Expression) (exprNode, error) {
+	c := parser.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end")
+
+	case c == '-':
+		parser.pos++
+		operand, err := parser.parseFactor(expression)
+		if err != nil {
+			return nil, err
+		}
+		return exprBinary{op: '-', left: exprNumber(0), right: operand}, nil
+
+	case c == '(':
+		parser.pos++
+		node, err := parser.parseSum(expression)
+		if err != nil {
+			return nil, err
+		}
+		if parser.peek() != ')' {
+			return nil, fmt.Errorf("expected ) at %d", parser.pos)
+		}
+		parser.pos++
+		return node, nil
+
+	case c == '{':
+		end := strings.IndexByte(parser.text[parser.pos:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("expected } after %v", parser.text[parser.pos:])
+		}
+		oid, err := ResolveOid(strings.TrimSpace(parser.text[parser.pos+1 : parser.pos+end]))
+		if err != nil {
+			return nil, err
+		}
+		parser.pos += end + 1
+		expression.Oids = append(expression.Oids, oid)
+		return exprOid(oid), nil
+
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := parser.pos
+		for parser.pos < len(parser.text) && strings.IndexByte("0123456789.eE", parser.text[parser.pos]) >= 0 {
+			parser.pos++
+		}
+		number, err := strconv.ParseFloat(parser.text[start:parser.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %v", parser.text[start:parser.pos])
+		}
+		return exprNumber(number), nil
+
+	case unicode.IsLetter(rune(c)):
+		start := parser.pos
+		for parser.pos < len(parser.text) && unicode.IsLetter(rune(parser.text[parser.pos])) {
+			parser.pos++
+		}
+		name := parser.text[start:parser.pos]
+		if _, ok := exprFunctions[name]; !ok {
+			return nil, fmt.Errorf("unknown function %v", name)
+		}
+		if parser.peek() != '(' {
+			return nil, fmt.Errorf("expected ( after %v", name)
+		}
+		parser.pos++
+		call := exprCall{name: name}
+		for {
+			arg, err := parser.parseSum(expression)
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if parser.peek() != ',' {
+				break
+			}
+			parser.pos++
+		}
+		if parser.peek() != ')' {
+			return nil, fmt.Errorf("expected ) at %d", parser.pos)
+		}
+		parser.pos++
+		return call, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", c, parser.pos)
+}
+
+// exprNumber is a number in an expression.
+type exprNumber float64
+
+func (number exprNumber) eval(reads map[string]exprValues) (exprValues, error) {
+	return exprValues{indexes: []string{""}, values: []float64{float64(number)}}, nil
+}
+
+// exprOid is the values under an OID.
+type exprOid string
+
+func (oid exprOid) eval(reads map[string]exprValues) (exprValues, error) {
+	return reads[string(oid)], nil
+}
+
+// exprBinary is an arithmetic operation.
+type exprBinary struct {
+	op    byte
+	left  exprNode
+	right exprNode
+}
+
+func (binary exprBinary) eval(reads map[string]exprValues) (exprValues, error) {
+	left, err := binary.left.eval(reads)
+	if err != nil {
+		return exprValues{}, err
+	}
+	right, err := binary.right.eval(reads)
+	if err != nil {
+		return exprValues{}, err
+	}
+
+	// A single value applies to every row of the other side. Otherwise rows
+	// with the same index are paired.
+	var result exprValues
+	switch {
+	case len(right.values) == 1:
+		for i := range left.values {
+			result.indexes = append(result.indexes, left.indexes[i])
+			result.values = append(result.values, 0)
+			if result.values[i], err = binary.apply(left.values[i], right.values[0]); err != nil {
+				return exprValues{}, err
+			}
+		}
+	case len(left.values) == 1:
+		for i := range right.values {
+			result.indexes = append(result.indexes, right.indexes[i])
+			result.values = append(result.values, 0)
+			if result.values[i], err = binary.apply(left.values[0], right.values[i]); err != nil {
+				return exprValues{}, err
+			}
+		}
+	default:
+		rightRows := map[string]float64{}
+		for i, index := range right.indexes {
+			rightRows[index] = right.values[i]
+		}
+		for i, index := range left.indexes {
+			rightValue, ok := rightRows[index]
+			if !ok {
+				continue
+			}
+			value, err := binary.apply(left.values[i], rightValue)
+			if err != nil {
+				return exprValues{}, err
+			}
+			result.indexes = append(result.indexes, index)
+			result.values = append(result.values, value)
+		}
+		if len(result.values) == 0 {
+			return exprValues{}, fmt.Errorf("No rows with the same index for %c", binary.op)
+		}
+	}
+	return result, nil
+}
+
+// apply applies the operator to two values.
+func (binary exprBinary) apply(left float64, right float64) (float64, error) {
+	switch binary.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	}
+	if right == 0 {
+		return 0, ErrDivisionByZero
+	}
+	return left / right, nil
+}
+
+// exprFunctions are the functions that reduce values to one.
+var exprFunctions = map[string]func(values []float64) float64{
+	"sum": exprSum,
+	"avg": func(values []float64) float64 { return exprSum(values) / float64(len(values)) },
+	"min": func(values []float64) float64 { return exprReduce(values, math.Min) },
+	"max": func(values []float64) float64 { return exprReduce(values, math.Max) },
+}
+
+// exprSum is the sum of the values.
+func exprSum(values []float64) (sum float64) {
+	for _, value := range values {
+		sum += value
+	}
+	return sum
+}
+
+// exprReduce reduces the values, of which there is at least one, with a
+// function.
+func exprReduce(values []float64, reduce func(float64, float64) float64) float64 {
+	result := values[0]
+	for _, value := range values[1:] {
+		result = reduce(result, value)
+	}
+	return result
+}
+
+// exprCall is a function call.
+type exprCall struct {
+	name string
+	args []exprNode
+}
+
+func (call exprCall) eval(reads map[string]exprValues) (exprValues, error) {
+	var values []float64
+	for _, arg := range call.args {
+		argValues, err := arg.eval(reads)
+		if err != nil {
+			return exprValues{}, err
+		}
+		values = append(values, argValues.values...)
+	}
+	if len(values) == 0 {
+		return exprValues{}, fmt.Errorf("%v has no values", call.name)
+	}
+	return exprValues{indexes: []string{""}, values: []float64{exprFunctions[call.name](values)}}, nil
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+)
+
+// TestParseExpressionErrors checks bad expressions.
+func TestParseExpressionErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"1 +",
+		"sum(1",
+		"median({.1.3.6.1.2.1.33.1.3.3.1.5})",
+		"{.1.3.6.1.2.1.33.1.3.3.1.5",
+		"{UPS-MIB::noSuchObject}",
+		"1 2",
+	} {
+		if _, err := ParseExpression(text); err == nil {
+			t.Fatalf("Expected an error for [%v]", text)
+		}
+	}
+}
+
+// TestEvaluateExpression evaluates expressions over the emulator UPS input
+// lines.
+func TestEvaluateExpression(t *testing.T) {
+	client := testReplayClient(t)
+
+	cases := []struct {
+		text     string
+		expected float64
+	}{
+		{"1 + 2 * 3 - -4 / 2", 9},
+		{"(1 + 2) * 3", 9},
+		// upsInputTruePower is 574, 512 and 512 W.
+		{"sum({.1.3.6.1.2.1.33.1.3.3.1.5})", 1598},
+		{"max({.1.3.6.1.2.1.33.1.3.3.1.5}) - min({.1.3.6.1.2.1.33.1.3.3.1.5})", 62},
+		{"{.1.3.6.1.2.1.33.1.3.3.1.5.1} * 2", 1148},
+		// upsInputVoltage times upsInputCurrent in .1 A, row by row.
+		{"sum({.1.3.6.1.2.1.33.1.3.3.1.3} * {.1.3.6.1.2.1.33.1.3.3.1.4} * 0.1)", 288*10.3 + 288*9.2 + 282*9.2},
+		{"max(1, 5, avg({.1.3.6.1.2.1.33.1.3.3.1.4}))", 95 + 2.0/3},
+	}
+	for _, c := range cases {
+		expression, err := ParseExpression(c.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := expression.Evaluate(client)
+		if err != nil {
+			t.Fatalf("[%v]: %v", c.text, err)
+		}
+		if math.Abs(value-c.expected) > 1e-9 {
+			t.Fatalf("[%v]: expected %v, got %v", c.text, c.expected, value)
+		}
+	}
+
+	// upsOutputPower is 0 W on every line.
+	expression, err := ParseExpression("sum({.1.3.6.1.2.1.33.1.3.3.1.5}) / sum({.1.3.6.1.2.1.33.1.4.4.1.4})")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = expression.Evaluate(client); err != ErrDivisionByZero {
+		t.Fatalf("Expected division by zero, got %v", err)
+	}
+
+	// A column without a reduction has a value per row.
+	expression, err = ParseExpression("{.1.3.6.1.2.1.33.1.3.3.1.5}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = expression.Evaluate(client); err == nil {
+		t.Fatal("Expected an error for multiple values")
+	}
+}
+
+// testReplayClient creates a client for the emulator agent.
+func testReplayClient(t *testing.T) *SnmpClient {
+	config, err := GetDeviceConfig(emulator.AgentData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewSnmpClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
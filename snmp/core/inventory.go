@@ -0,0 +1,219 @@
+package core
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OIDs of the identity objects in an Inventory.
+const (
+	// sysOid is SNMPv2-MIB::system.
+	sysOid = ".1.3.6.1.2.1.1"
+	// upsIdentOid is UPS-MIB::upsIdent.
+	upsIdentOid = ".1.3.6.1.2.1.33.1.1"
+	// entPhysicalEntryOid is ENTITY-MIB::entPhysicalEntry.
+	entPhysicalEntryOid = ".1.3.6.1.2.1.47.1.1.1.1"
+)
+
+// Inventory is the identity of an agent and the equipment it manages, for an
+// asset system. The summary fields are from UPS-MIB::upsIdent when the agent
+// has it, and otherwise from the first physical entity with the field.
+type Inventory struct {
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Model        string `json:"model,omitempty"`
+	Firmware     string `json:"firmware,omitempty"`
+	SerialNumber string `json:"serialNumber,omitempty"` // First entity with a serial number.
+
+	System   InventorySystem   `json:"system"`
+	Ups      *InventoryUps     `json:"ups,omitempty"`      // UPS-MIB::upsIdent.
+	Entities []InventoryEntity `json:"entities,omitempty"` // ENTITY-MIB::entPhysicalTable.
+}
+
+// InventorySystem is SNMPv2-MIB::system.
+type InventorySystem struct {
+	Descr    string `json:"sysDescr,omitempty"`
+	ObjectID string `json:"sysObjectID,omitempty"`
+	Contact  string `json:"sysContact,omitempty"`
+	Name     string `json:"sysName,omitempty"`
+	Location string `json:"sysLocation,omitempty"`
+}
+
+// InventoryUps is UPS-MIB::upsIdent.
+type InventoryUps struct {
+	Manufacturer         string `json:"manufacturer,omitempty"`
+	Model                string `json:"model,omitempty"`
+	UpsSoftwareVersion   string `json:"upsSoftwareVersion,omitempty"`
+	AgentSoftwareVersion string `json:"agentSoftwareVersion,omitempty"`
+	Name                 string `json:"name,omitempty"`
+	AttachedDevices      string `json:"attachedDevices,omitempty"`
+}
+
+// InventoryEntity is a row of ENTITY-MIB::entPhysicalTable.
+type InventoryEntity struct {
+	Index            int    `json:"index"`
+	Description      string `json:"description,omitempty"`
+	Name             string `json:"name,omitempty"`
+	HardwareRevision string `json:"hardwareRevision,omitempty"`
+	FirmwareRevision string `json:"firmwareRevision,omitempty"`
+	SoftwareRevision string `json:"softwareRevision,omitempty"`
+	SerialNumber     string `json:"serialNumber,omitempty"`
+	Manufacturer     string `json:"manufacturer,omitempty"`
+	Model            string `json:"model,omitempty"`
+}
+
+// ReadInventory walks the identity objects of the agent. Objects the agent
+// does not have are left empty.
+func ReadInventory(client *SnmpClient) (*Inventory, error) {
+	inventory := &Inventory{}
+
+	// system and upsIdent are scalars, indexed by column.
+	system, err := inventoryScalars(client, sysOid)
+	if err != nil {
+		return nil, err
+	}
+	inventory.System = InventorySystem{
+		Descr:    system[1],
+		ObjectID: system[2],
+		Contact:  system[4],
+		Name:     system[5],
+		Location: system[6],
+	}
+
+	ident, err := inventoryScalars(client, upsIdentOid)
+	if err != nil {
+		return nil, err
+	}
+	if len(ident) > 0 {
+		inventory.Ups = &InventoryUps{
+			Manufacturer:         ident[1],
+			Model:                ident[2],
+			UpsSoftwareVersion:   ident[3],
+			AgentSoftwareVersion: ident[4],
+			Name:                 ident[5],
+			AttachedDevices:      ident[6],
+		}
+	}
+
+	// entPhysicalTable columns by entity index.
+	results, err := client.Walk(entPhysicalEntryOid)
+	if err != nil {
+		return nil, err
+	}
+	entities := map[int]*InventoryEntity{}
+	for _, result := range results {
+		column, index, ok := inventoryColumnIndex(result.Oid, entPhysicalEntryOid)
+		if !ok {
+			continue
+		}
+		entity, ok := entities[index]
+		if !ok {
+			entity = &InventoryEntity{Index: index}
+			entities[index] = entity
+		}
+		value := inventoryText(result)
+		switch column {
+		case 2:
+			entity.Description = value
+		case 7:
+			entity.Name = value
+		case 8:
+			entity.HardwareRevision = value
+		case 9:
+			entity.FirmwareRevision = value
+		case 10:
+			entity.SoftwareRevision = value
+		case 11:
+			entity.SerialNumber = value
+		case 12:
+			entity.Manufacturer = value
+		case 13:
+			entity.Model = value
+		}
+	}
+	for _, entity := range entities {
+		inventory.Entities = append(inventory.Entities, *entity)
+	}
+	sort.Slice(inventory.Entities, func(i, j int) bool {
+		return inventory.Entities[i].Index < inventory.Entities[j].Index
+	})
+
+	inventory.summarize()
+	return inventory, nil
+}
+
+// summarize sets the summary fields.
+func (inventory *Inventory) summarize() {
+	if inventory.Ups != nil {
+		inventory.Manufacturer = inventory.Ups.Manufacturer
+		inventory.Model = inventory.Ups.Model
+		inventory.Firmware = inventory.Ups.UpsSoftwareVersion
+	}
+	for _, entity := range inventory.Entities {
+		if inventory.Manufacturer == "" {
+			inventory.Manufacturer = entity.Manufacturer
+		}
+		if inventory.Model == "" {
+			inventory.Model = entity.Model
+		}
+		if inventory.Firmware == "" {
+			inventory.Firmware = entity.FirmwareRevision
+		}
+		if inventory.SerialNumber == "" {
+			inventory.SerialNumber = entity.SerialNumber
+		}
+	}
+}
+
+// inventoryScalars walks a group of scalars and returns their values by
+// column.
+func inventoryScalars(client *SnmpClient, oid string) (map[int]string, error) {
+	results, err := client.Walk(oid)
+	if err != nil {
+		return nil, err
+	}
+	values := map[int]string{}
+	for _, result := range results {
+		column, index, ok := inventoryColumnIndex(result.Oid, oid)
+		if ok && index == 0 {
+			values[column] = inventoryText(result)
+		}
+	}
+	return values, nil
+}
+
+// inventoryColumnIndex splits an OID under the OID of an entry or group into
+// its column and its single numeric index.
+func inventoryColumnIndex(oid string, entryOid string) (column int, index int, ok bool) {
+	if !strings.HasPrefix(oid, entryOid+".") {
+		return 0, 0, false
+	}
+	parts := strings.Split(strings.TrimPrefix(oid, entryOid+"."), ".")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	column, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	index, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return column, index, true
+}
+
+// inventoryText returns a value as text. Strings are as is, and an OID such
+// as sysObjectID is numeric.
+func inventoryText(result ReadResult) string {
+	if result.IsNull() {
+		return ""
+	}
+	if text, err := result.Text(); err == nil {
+		return strings.TrimSpace(text)
+	}
+	if oid, err := result.OID(); err == nil {
+		return oid
+	}
+	return result.Format()
+}
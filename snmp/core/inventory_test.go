@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+)
+
+// TestReadInventory reads the inventory of the emulator UPS.
+func TestReadInventory(t *testing.T) {
+	client := testReplayClient(t)
+
+	inventory, err := ReadInventory(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The summary is from upsIdent, and the serial number from the first
+	// entity with one, the network card.
+	if inventory.Manufacturer != "Eaton Corporation" || inventory.Model != "PXGMS UPS + EATON 93PM" ||
+		inventory.Firmware != "INV: 1.44.0000" || inventory.SerialNumber != "00:20:85:F1:56:DE" {
+		t.Fatalf("Unexpected inventory summary %+v", inventory)
+	}
+
+	system := inventory.System
+	if system.Name != "PowerXpert-00-20-85-F1-56-DE" || system.ObjectID != ".1.3.6.1.4.1.534.2.12" ||
+		system.Contact != "Your Name" || system.Location != "Your Location" {
+		t.Fatalf("Unexpected system %+v", system)
+	}
+	if inventory.Ups == nil || inventory.Ups.AgentSoftwareVersion != "2.3.7" {
+		t.Fatalf("Unexpected upsIdent %+v", inventory.Ups)
+	}
+
+	// Entities are in index order.
+	if len(inventory.Entities) != 6 {
+		t.Fatalf("Expected 6 entities, got %+v", inventory.Entities)
+	}
+	ups := inventory.Entities[1]
+	if ups.Index != 2 || ups.Name != "EATON 93PM" || ups.SerialNumber != "EM111UXX06" ||
+		ups.SoftwareRevision != "1.44.0000" || ups.Model != "93PM" || ups.HardwareRevision != "" {
+		t.Fatalf("Unexpected UPS entity %+v", ups)
+	}
+	if inventory.Entities[5].Index != 247 {
+		t.Fatalf("Expected the probe last, got %+v", inventory.Entities[5])
+	}
+}
@@ -0,0 +1,15 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+)
+
+// TestMain runs the tests against the emulator. See emulator.Main.
+func TestMain(m *testing.M) {
+	emulator.Main(m, func(dir string) error {
+		_, err := UseReplayTransport(dir)
+		return err
+	})
+}
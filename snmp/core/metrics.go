@@ -0,0 +1,135 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/metrics"
+)
+
+// Metrics of the SNMP requests to each agent, in metrics.Default. The
+// operation label is get, walk or set. A walk is one request, whatever the
+// number of GETNEXT or GETBULK exchanges it takes. The reason label of a
+// failure is timeout, auth or error.
+var (
+	requestsTotal = metrics.Default.NewCounter(
+		"snmp_requests_total",
+		"SNMP requests made to an agent.",
+		"agent", "operation")
+	requestFailuresTotal = metrics.Default.NewCounter(
+		"snmp_request_failures_total",
+		"SNMP requests to an agent that failed, by reason.",
+		"agent", "operation", "reason")
+	requestDuration = metrics.Default.NewHistogram(
+		"snmp_request_duration_seconds",
+		"Duration of the SNMP requests to an agent.",
+		metrics.DefaultBuckets,
+		"agent", "operation")
+	responsePdus = metrics.Default.NewHistogram(
+		"snmp_response_pdus",
+		"PDUs in each successful SNMP request to an agent. For walks, this is the size of the walk.",
+		[]float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+		"agent", "operation")
+
+	_ = metrics.Default.NewGaugeFunc(
+		"snmp_agent_consecutive_failures",
+		"SNMP requests to a registered agent that failed since its last success.",
+		[]string{"agent"},
+		func() []metrics.Sample {
+			return agentSamples(func(health AgentHealth) float64 {
+				return float64(health.ConsecutiveFailures)
+			})
+		})
+	_ = metrics.Default.NewGaugeFunc(
+		"snmp_agent_last_success_timestamp_seconds",
+		"Unix time of the last successful SNMP request to a registered agent, or 0 if there was none.",
+		[]string{"agent"},
+		func() []metrics.Sample {
+			return agentSamples(func(health AgentHealth) float64 {
+				if health.LastSuccess.IsZero() {
+					return 0
+				}
+				return float64(health.LastSuccess.UnixNano()) / 1e9
+			})
+		})
+)
+
+// pduTotals are the PDUs of the successful requests to each agent, by agent
+// label, for the PDUs of each device read.
+var pduTotals = map[string]uint64{}
+var pduTotalsMutex sync.Mutex
+
+// ResponsePdus returns the PDUs of the successful requests made so far to the
+// agent with the agent label.
+func ResponsePdus(agent string) uint64 {
+	pduTotalsMutex.Lock()
+	defer pduTotalsMutex.Unlock()
+	return pduTotals[agent]
+}
+
+// agentSamples returns a sample of the health of each registered agent.
+func agentSamples(value func(AgentHealth) float64) []metrics.Sample {
+	var samples []metrics.Sample
+	for _, agent := range Agents() {
+		samples = append(samples, metrics.Sample{
+			LabelValues: []string{agent.ID},
+			Value:       value(agent.Health()),
+		})
+	}
+	return samples
+}
+
+// metricsAgent returns the agent label of the metrics of a client: the agent
+// ID, or endpoint:port for a client without a registered agent.
+func metricsAgent(config *DeviceConfig) string {
+	if config == nil {
+		return ""
+	}
+	if config.AgentID != "" {
+		return config.AgentID
+	}
+	return fmt.Sprintf("%v:%v", config.Endpoint, config.Port)
+}
+
+// recordRequest updates the health of the agent and the request metrics with
+// the outcome of a request that started at start and returned pdus PDUs.
+func recordRequest(config *DeviceConfig, operation string, start time.Time, pdus int, err error) {
+	recordAgentResult(config.AgentID, err)
+
+	agent := metricsAgent(config)
+	requestsTotal.Inc(agent, operation)
+	requestDuration.Observe(time.Since(start).Seconds(), agent, operation)
+	if err != nil {
+		requestFailuresTotal.Inc(agent, operation, failureReason(err))
+		return
+	}
+	responsePdus.Observe(float64(pdus), agent, operation)
+
+	pduTotalsMutex.Lock()
+	defer pduTotalsMutex.Unlock()
+	pduTotals[agent] += uint64(pdus)
+}
+
+// failureReason classifies a request error as timeout, auth or error. gosnmp
+// has no error types for these, so this is by the error text: its retry
+// timeout or the i/o timeout of the socket, and the USM report errors for the
+// credentials, engine ID and time window of the agent.
+func failureReason(err error) string {
+	text := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(text, "timeout"):
+		return "timeout"
+	case strings.Contains(text, "authentic"),
+		strings.Contains(text, "digest"),
+		strings.Contains(text, "unknown user"),
+		strings.Contains(text, "usmstats"),
+		strings.Contains(text, "decrypt"),
+		strings.Contains(text, "not in time window"),
+		strings.Contains(text, "unknown engine id"),
+		strings.Contains(text, "unknown security level"):
+		return "auth"
+	}
+	return "error"
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/metrics"
+)
+
+// TestRequestMetrics checks that requests to an agent update its metrics.
+func TestRequestMetrics(t *testing.T) {
+	data := registryTestAgent()
+	data[AgentKey] = "metrics-test"
+	agent, err := RegisterAgent(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = agent.Client.Get(".1.3.6.1.2.1.1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	results, err := agent.Client.Walk(".1.3.6.1.2.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordRequest(agent.DeviceConfig, "get", agent.Health().LastSuccess, 0, fmt.Errorf("request timeout (after 3 retries)"))
+
+	var buffer bytes.Buffer
+	if err = metrics.Default.Write(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	text := buffer.String()
+	for _, line := range []string{
+		`snmp_requests_total{agent="metrics-test",operation="get"} 2`,
+		`snmp_requests_total{agent="metrics-test",operation="walk"} 1`,
+		`snmp_request_failures_total{agent="metrics-test",operation="get",reason="timeout"} 1`,
+		`snmp_request_duration_seconds_count{agent="metrics-test",operation="walk"} 1`,
+		fmt.Sprintf(`snmp_response_pdus_sum{agent="metrics-test",operation="walk"} %d`, len(results)),
+		`snmp_agent_consecutive_failures{agent="metrics-test"} 1`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Expected %v in:\n%v", line, text)
+		}
+	}
+}
+
+// TestFailureReason classifies the request errors of gosnmp and of the
+// socket by their text.
+func TestFailureReason(t *testing.T) {
+	for text, expected := range map[string]string{
+		"request timeout (after 3 retries)":                     "timeout",
+		"read udp 127.0.0.1:54321->127.0.0.1:1024: i/o timeout": "timeout",
+		"incoming packet is not authentic, discarding":          "auth",
+		"wrong digest":     "auth",
+		"unknown username": "auth",
+		"decryption error": "auth",
+		"error decrypting ScopedPDU: truncated packet": "auth",
+		"not in time window":                           "auth",
+		"unknown engine id":                            "auth",
+		"unknown security level":                       "auth",
+		"read udp 127.0.0.1:54321->127.0.0.1:1024: read: connection refused": "error",
+		"error parsing SNMPV3 User Security Model parameters":                "error",
+		"SNMP error status noSuchName on .1.3.6.1.2.1.1.1.0":                 "error",
+	} {
+		if reason := failureReason(fmt.Errorf("%v", text)); reason != expected {
+			t.Errorf("Expected %v for %q, got %v", expected, text, reason)
+		}
+	}
+}
@@ -85,6 +85,7 @@ func NewOids(oids []string) (result OidSlice, err error) {
 type oidTrieNode struct {
 	OidSegment uint64         // The portion of the OID between periods.
 	Children   []*oidTrieNode // N way trie. Pointers to child nodes.
+	Value      interface{}    // Optional value stored at this OID. See Set.
 }
 
 // newOidTrieNode creates an oidTrieNode with no children.
@@ -157,6 +158,75 @@ func (oidTrie *OidTrie) Insert(oid *Oid) (err error) {
 	return nil
 }
 
+// Set inserts an Oid into the trie and stores a value with it. Any previous
+// value for the Oid is replaced.
+func (oidTrie *OidTrie) Set(oid *Oid, value interface{}) (err error) {
+	if oid == nil {
+		return fmt.Errorf("oid is nil")
+	}
+
+	current := oidTrie.Head
+	for i := 0; i < len(oid.ToSlice); i++ {
+		current, err = insertSegment(current, oid.ToSlice[i])
+		if err != nil {
+			return err
+		}
+	}
+	current.Value = value
+	return nil
+}
+
+// findChild returns the child of current with the given segment, or nil.
+func findChild(current *oidTrieNode, segment uint64) *oidTrieNode {
+	for i := 0; i < len(current.Children); i++ {
+		if current.Children[i].OidSegment == segment {
+			return current.Children[i]
+		}
+		if current.Children[i].OidSegment > segment {
+			break // Children are sorted.
+		}
+	}
+	return nil
+}
+
+// Lookup gets the value stored for an Oid with Set. found is false if the Oid
+// is not in the trie or has no value.
+func (oidTrie *OidTrie) Lookup(oid *Oid) (value interface{}, found bool) {
+	if oid == nil {
+		return nil, false
+	}
+
+	current := oidTrie.Head
+	for i := 0; i < len(oid.ToSlice); i++ {
+		current = findChild(current, oid.ToSlice[i])
+		if current == nil {
+			return nil, false
+		}
+	}
+	return current.Value, current.Value != nil
+}
+
+// LongestPrefix finds the longest prefix of an Oid that has a value stored
+// with Set. length is the number of segments in the prefix. found is false if
+// no prefix of the Oid has a value.
+func (oidTrie *OidTrie) LongestPrefix(oid *Oid) (length int, value interface{}, found bool) {
+	if oid == nil {
+		return 0, nil, false
+	}
+
+	current := oidTrie.Head
+	for i := 0; i < len(oid.ToSlice); i++ {
+		current = findChild(current, oid.ToSlice[i])
+		if current == nil {
+			break
+		}
+		if current.Value != nil {
+			length, value, found = i+1, current.Value, true
+		}
+	}
+	return length, value, found
+}
+
 // insertSegment inserts one OID segment (the part between the periods) into
 // the OidTrie. Returns the node at the position of insertion. If the node
 // exists no duplicate insertion is made, but the new position is returned.
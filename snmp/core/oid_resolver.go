@@ -0,0 +1,95 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// OidResolver translates between symbolic OIDs such as
+// UPS-MIB::upsBatteryStatus.0 and numeric OIDs. smi.Mibs implements this.
+type OidResolver interface {
+	// Resolve translates a symbolic OID to a numeric one.
+	Resolve(name string) (*Oid, error)
+	// Name translates a numeric OID to a symbolic one, or returns it as is
+	// if it is not known.
+	Name(oid string) string
+}
+
+// oidResolver is the process wide OidResolver. Nil until the plugin loads
+// its MIBs, in which case only numeric OIDs are supported.
+var oidResolver = struct {
+	sync.RWMutex
+	resolver OidResolver
+}{}
+
+// SetOidResolver sets the process wide OidResolver. nil removes it.
+func SetOidResolver(resolver OidResolver) {
+	oidResolver.Lock()
+	defer oidResolver.Unlock()
+	oidResolver.resolver = resolver
+}
+
+// getOidResolver gets the process wide OidResolver, which may be nil.
+func getOidResolver() OidResolver {
+	oidResolver.RLock()
+	defer oidResolver.RUnlock()
+	return oidResolver.resolver
+}
+
+// IsNumericOid returns true for OIDs such as .1.3.6.1 or 1.3.6.1.
+func IsNumericOid(oid string) bool {
+	trimmed := strings.TrimPrefix(oid, ".")
+	if trimmed == "" {
+		return false
+	}
+	for _, c := range trimmed {
+		if (c < '0' || c > '9') && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveOid translates a symbolic OID to a numeric OID with a leading
+// period. Numeric OIDs are returned with a leading period.
+func ResolveOid(oid string) (string, error) {
+	if IsNumericOid(oid) {
+		if !strings.HasPrefix(oid, ".") {
+			oid = "." + oid
+		}
+		return oid, nil
+	}
+
+	resolver := getOidResolver()
+	if resolver == nil {
+		return "", fmt.Errorf("Unable to resolve OID %v, no MIBs are loaded", oid)
+	}
+	resolved, err := resolver.Resolve(oid)
+	if err != nil {
+		return "", fmt.Errorf("Unable to resolve OID %v: %v", oid, err)
+	}
+	result, _ := NewOidFromSlice(resolved.ToSlice) // No error.
+	return result.ToString, nil
+}
+
+// OidName translates a numeric OID to a symbolic OID for display. The OID is
+// returned as is if it can not be translated.
+func OidName(oid string) string {
+	resolver := getOidResolver()
+	if resolver == nil || !IsNumericOid(oid) {
+		return oid
+	}
+	return resolver.Name(oid)
+}
+
+// OidDisplay formats an OID for logs and error messages with both the
+// symbolic name, if known, and the numeric OID.
+// Example: UPS-MIB::upsBatteryStatus.0 (.1.3.6.1.2.1.33.1.2.1.0)
+func OidDisplay(oid string) string {
+	name := OidName(oid)
+	if name == oid {
+		return oid
+	}
+	return fmt.Sprintf("%v (%v)", name, oid)
+}
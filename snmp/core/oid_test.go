@@ -836,3 +836,47 @@ func TestUpsOids1(t *testing.T) {
 		log.Fatal("String compare failed.")
 	}
 }
+
+// TestOidTrieValues checks storing values in the trie and prefix lookups.
+func TestOidTrieValues(t *testing.T) {
+	trie, err := NewOidTrie(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upsMib, _ := NewOid(".1.3.6.1.2.1.33")
+	upsBatteryStatus, _ := NewOid(".1.3.6.1.2.1.33.1.2.1")
+	if err = trie.Set(upsMib, "upsMIB"); err != nil {
+		t.Fatal(err)
+	}
+	if err = trie.Set(upsBatteryStatus, "upsBatteryStatus"); err != nil {
+		t.Fatal(err)
+	}
+
+	value, found := trie.Lookup(upsBatteryStatus)
+	if !found || value != "upsBatteryStatus" {
+		t.Fatalf("Expected upsBatteryStatus, got %v", value)
+	}
+
+	// Intermediate nodes have no value.
+	upsBattery, _ := NewOid(".1.3.6.1.2.1.33.1.2")
+	if _, found = trie.Lookup(upsBattery); found {
+		t.Fatal("Expected no value for upsBattery")
+	}
+
+	instance, _ := NewOid(".1.3.6.1.2.1.33.1.2.1.0")
+	length, value, found := trie.LongestPrefix(instance)
+	if !found || length != 10 || value != "upsBatteryStatus" {
+		t.Fatalf("Expected upsBatteryStatus at length 10, got %v at %d", value, length)
+	}
+
+	length, value, found = trie.LongestPrefix(upsBattery)
+	if !found || length != 7 || value != "upsMIB" {
+		t.Fatalf("Expected upsMIB at length 7, got %v at %d", value, length)
+	}
+
+	other, _ := NewOid(".1.3.6.1.4.1")
+	if _, _, found = trie.LongestPrefix(other); found {
+		t.Fatal("Expected no prefix for enterprises")
+	}
+}
@@ -0,0 +1,647 @@
+package core
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/soniah/gosnmp"
+)
+
+// Extensions of the data files loaded by ReplayTransport.LoadDir. These are
+// the snmpsim data file formats.
+const (
+	SnmpWalkFileExtension = ".snmpwalk"
+	SnmpRecFileExtension  = ".snmprec"
+)
+
+// ReplayTransport is a Transport that answers from snmpwalk output in memory
+// rather than from an SNMP agent. It reads the same data directory as the
+// snmpsim emulator under emulator/. Like snmpsim, each file is the data for
+// the SNMP context with the file name, so emulator/data/public.snmpwalk answers
+// for context name public. The endpoint and credentials are not checked.
+type ReplayTransport struct {
+	mutex    sync.RWMutex
+	contexts map[string][]replayRecord // Records sorted by OID, by context name.
+}
+
+// replayRecord is one OID and value.
+type replayRecord struct {
+	oid *Oid
+	pdu gosnmp.SnmpPDU
+}
+
+// NewReplayTransport creates a ReplayTransport with no data.
+func NewReplayTransport() *ReplayTransport {
+	return &ReplayTransport{
+		contexts: map[string][]replayRecord{},
+	}
+}
+
+// LoadReplayTransport creates a ReplayTransport with the snmpwalk files in a
+// directory.
+func LoadReplayTransport(dir string) (*ReplayTransport, error) {
+	replay := NewReplayTransport()
+	if err := replay.LoadDir(dir); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// UseReplayTransport loads a ReplayTransport with the snmpwalk files in a
+// directory and makes it the process wide Transport, so that tests run
+// without an agent.
+func UseReplayTransport(dir string) (*ReplayTransport, error) {
+	replay, err := LoadReplayTransport(dir)
+	if err != nil {
+		return nil, err
+	}
+	SetTransport(replay)
+	return replay, nil
+}
+
+// LoadDir loads every snmpwalk and snmprec file in a directory. The context
+// name for each file is the file name without the extension.
+func (replay *ReplayTransport) LoadDir(dir string) error {
+	data, err := LoadDataDir(dir)
+	if err != nil {
+		return err
+	}
+	for contextName, pdus := range data {
+		if err = replay.Load(contextName, pdus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile loads an snmpwalk or snmprec file as the data for a context name.
+func (replay *ReplayTransport) LoadFile(path string, contextName string) error {
+	pdus, err := LoadDataFile(path)
+	if err != nil {
+		return err
+	}
+	return replay.Load(contextName, pdus)
+}
+
+// LoadDataDir parses every snmpwalk and snmprec file in a directory, keyed by
+// the file name without the extension, which is the SNMP context name or
+// community the data is for.
+func LoadDataDir(dir string) (map[string][]gosnmp.SnmpPDU, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string][]gosnmp.SnmpPDU{}
+	for _, file := range files {
+		extension := filepath.Ext(file.Name())
+		if file.IsDir() || (extension != SnmpWalkFileExtension && extension != SnmpRecFileExtension) {
+			continue
+		}
+		contextName := strings.TrimSuffix(file.Name(), extension)
+		if _, exists := data[contextName]; exists {
+			return nil, fmt.Errorf("Duplicate data for context name [%v] in %v", contextName, dir)
+		}
+		pdus, err := LoadDataFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		data[contextName] = pdus
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("No %v or %v files in %v", SnmpWalkFileExtension, SnmpRecFileExtension, dir)
+	}
+	return data, nil
+}
+
+// LoadDataFile parses an snmpwalk or snmprec file by its extension.
+func LoadDataFile(path string) (pdus []gosnmp.SnmpPDU, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // nolint: errcheck
+
+	switch filepath.Ext(path) {
+	case SnmpWalkFileExtension:
+		pdus, err = ParseSnmpWalk(file)
+	case SnmpRecFileExtension:
+		pdus, err = ParseSnmpRec(file)
+	default:
+		return nil, fmt.Errorf("Unknown data file type %v", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse %v: %v", path, err)
+	}
+	return pdus, nil
+}
+
+// Load sets the data for a context name, replacing any existing data.
+func (replay *ReplayTransport) Load(contextName string, pdus []gosnmp.SnmpPDU) error {
+	records := make([]replayRecord, 0, len(pdus))
+	for _, pdu := range pdus {
+		oid, err := NewOid(pdu.Name)
+		if err != nil {
+			return err
+		}
+		pdu.Name = "." + oid.ToString // gosnmp names have a leading period.
+		records = append(records, replayRecord{oid: oid, pdu: pdu})
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return CompareOids(records[i].oid.ToSlice, records[j].oid.ToSlice) < 0
+	})
+
+	replay.mutex.Lock()
+	defer replay.mutex.Unlock()
+	replay.contexts[contextName] = records
+	return nil
+}
+
+// Get answers an SNMP get from the data for the context name in the config.
+func (replay *ReplayTransport) Get(config *DeviceConfig, oids []string) ([]gosnmp.SnmpPDU, error) {
+	records, err := replay.records(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var pdus []gosnmp.SnmpPDU
+	for _, oidString := range oids {
+		oid, err := NewOid(oidString)
+		if err != nil {
+			return nil, err
+		}
+		i := searchRecords(records, oid.ToSlice)
+		if i < len(records) && CompareOids(records[i].oid.ToSlice, oid.ToSlice) == 0 {
+			pdus = append(pdus, records[i].pdu)
+			continue
+		}
+		// Like an agent, report an instance that does not exist under an
+		// object that does as NoSuchInstance.
+		pduType := gosnmp.NoSuchObject
+		object := oid.ToSlice[:len(oid.ToSlice)-1]
+		if (i > 0 && HasOidPrefix(records[i-1].oid.ToSlice, object)) ||
+			(i < len(records) && HasOidPrefix(records[i].oid.ToSlice, object)) {
+			pduType = gosnmp.NoSuchInstance
+		}
+		pdus = append(pdus, gosnmp.SnmpPDU{Name: "." + oid.ToString, Type: pduType})
+	}
+	return pdus, nil
+}
+
+// Walk answers an SNMP walk from the data for the context name in the config.
+func (replay *ReplayTransport) Walk(config *DeviceConfig, rootOid string) ([]gosnmp.SnmpPDU, error) {
+	records, err := replay.records(config)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := NewOid(rootOid)
+	if err != nil {
+		return nil, err
+	}
+
+	var pdus []gosnmp.SnmpPDU
+	i := searchRecords(records, root.ToSlice)
+	if i < len(records) && CompareOids(records[i].oid.ToSlice, root.ToSlice) == 0 {
+		// The root is a leaf. gosnmp returns it since there is nothing under it.
+		return []gosnmp.SnmpPDU{records[i].pdu}, nil
+	}
+	for ; i < len(records) && HasOidPrefix(records[i].oid.ToSlice, root.ToSlice); i++ {
+		pdus = append(pdus, records[i].pdu)
+	}
+	return pdus, nil
+}
+
+// Set answers an SNMP set by replacing values in the data for the context
+// name in the config, so that later reads return them. Like an agent, only
+// existing OIDs can be set and only with the type they have.
+func (replay *ReplayTransport) Set(config *DeviceConfig, pdus []gosnmp.SnmpPDU) error {
+	if config == nil {
+		return fmt.Errorf("config is nil")
+	}
+	replay.mutex.Lock()
+	defer replay.mutex.Unlock()
+	records, ok := replay.contexts[config.ContextName]
+	if !ok {
+		return fmt.Errorf("No replay data for context name [%v]", config.ContextName)
+	}
+
+	// Copy the records, since readers hold the current slice after unlocking.
+	updated := make([]replayRecord, len(records))
+	copy(updated, records)
+	for i, pdu := range pdus {
+		oid, err := NewOid(pdu.Name)
+		if err != nil {
+			return err
+		}
+		j := searchRecords(updated, oid.ToSlice)
+		if j >= len(updated) || CompareOids(updated[j].oid.ToSlice, oid.ToSlice) != 0 {
+			return fmt.Errorf("NoCreation on varbind %d", i+1)
+		}
+		if updated[j].pdu.Type != pdu.Type {
+			return fmt.Errorf("WrongType on varbind %d", i+1)
+		}
+		pdu.Name = "." + oid.ToString
+		updated[j].pdu = pdu
+	}
+	replay.contexts[config.ContextName] = updated
+	return nil
+}
+
+// records gets the sorted records for the context name in the config.
+func (replay *ReplayTransport) records(config *DeviceConfig) ([]replayRecord, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
+	replay.mutex.RLock()
+	defer replay.mutex.RUnlock()
+	records, ok := replay.contexts[config.ContextName]
+	if !ok {
+		return nil, fmt.Errorf("No replay data for context name [%v]", config.ContextName)
+	}
+	return records, nil
+}
+
+// searchRecords returns the index of the first record at or after the OID.
+func searchRecords(records []replayRecord, oid []uint64) int {
+	return sort.Search(len(records), func(i int) bool {
+		return CompareOids(records[i].oid.ToSlice, oid) >= 0
+	})
+}
+
+// CompareOids compares OIDs in walk order. The result is negative, zero or
+// positive like strings.Compare.
+func CompareOids(a []uint64, b []uint64) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+// HasOidPrefix returns true if the OID is under the prefix.
+func HasOidPrefix(oid []uint64, prefix []uint64) bool {
+	if len(oid) <= len(prefix) {
+		return false
+	}
+	return CompareOids(oid[:len(prefix)], prefix) == 0
+}
+
+// ParseSnmpWalk parses net-snmp snmpwalk output, for example from
+// snmpwalk -v2c -c public -ObentU host .1, as snmpsim reads it:
+//
+//	.1.3.6.1.2.1.1.5.0 = STRING: "PowerXpert-00-20-85-F1-56-DE"
+//	.1.3.6.1.2.1.1.3.0 = 6930266
+//
+// Values without a type are TimeTicks, as printed by snmpwalk -Ot, and "" is
+// an empty OctetString. Blank lines and lines starting with # are skipped.
+func ParseSnmpWalk(reader io.Reader) (pdus []gosnmp.SnmpPDU, err error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pdu, err := ParseSnmpWalkLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		pdus = append(pdus, pdu)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pdus, nil
+}
+
+// ParseSnmpWalkLine parses one line of snmpwalk output.
+func ParseSnmpWalkLine(line string) (pdu gosnmp.SnmpPDU, err error) {
+	fields := strings.SplitN(line, " = ", 2)
+	if len(fields) != 2 {
+		return pdu, fmt.Errorf("Expected OID = value, got [%v]", line)
+	}
+	if !IsNumericOid(fields[0]) {
+		return pdu, fmt.Errorf("Expected a numeric OID, got [%v]", fields[0])
+	}
+	pdu, err = ParseSnmpWalkValue(fields[1])
+	pdu.Name = fields[0]
+	return pdu, err
+}
+
+// ParseSnmpWalkValue parses the value part of a line of snmpwalk output, the
+// part after the equals sign. Example: INTEGER: 3
+func ParseSnmpWalkValue(text string) (pdu gosnmp.SnmpPDU, err error) { // nolint: gocyclo
+	tag, value := "", strings.TrimSpace(text)
+	if colon := strings.Index(value, ": "); colon > 0 && !strings.HasPrefix(value, "\"") {
+		tag, value = value[:colon], strings.TrimSpace(value[colon+2:])
+	} else if strings.HasSuffix(value, ":") && !strings.HasPrefix(value, "\"") {
+		tag, value = strings.TrimSuffix(value, ":"), "" // Empty value. Example: STRING:
+	}
+
+	switch tag {
+	case "":
+		if strings.HasPrefix(value, "\"") {
+			pdu.Type = gosnmp.OctetString
+			pdu.Value, err = parseSnmpWalkString(value)
+			return pdu, err
+		}
+		pdu.Type = gosnmp.TimeTicks
+		pdu.Value, err = parseSnmpWalkUint32(value)
+	case "Timeticks":
+		// Example: (6930266) 19:15:02.66
+		pdu.Type = gosnmp.TimeTicks
+		value = strings.TrimPrefix(value, "(")
+		if end := strings.Index(value, ")"); end >= 0 {
+			value = value[:end]
+		}
+		pdu.Value, err = parseSnmpWalkUint32(value)
+	case "STRING":
+		pdu.Type = gosnmp.OctetString
+		pdu.Value, err = parseSnmpWalkString(value)
+	case "Hex-STRING":
+		pdu.Type = gosnmp.OctetString
+		pdu.Value, err = hex.DecodeString(strings.Join(strings.Fields(value), ""))
+	case "INTEGER":
+		// Enumerations may be printed with the label. Example: up(1)
+		if open := strings.Index(value, "("); open >= 0 && strings.HasSuffix(value, ")") {
+			value = value[open+1 : len(value)-1]
+		}
+		var i int64
+		i, err = strconv.ParseInt(value, 10, 32)
+		pdu.Type = gosnmp.Integer
+		pdu.Value = int(i)
+	case "Counter32", "Gauge32":
+		pdu.Type = gosnmp.Counter32
+		if tag == "Gauge32" {
+			pdu.Type = gosnmp.Gauge32
+		}
+		var u uint64
+		u, err = strconv.ParseUint(value, 10, 32)
+		pdu.Value = uint(u)
+	case "Counter64":
+		pdu.Type = gosnmp.Counter64
+		pdu.Value, err = strconv.ParseUint(value, 10, 64)
+	case "IpAddress":
+		pdu.Type = gosnmp.IPAddress
+		pdu.Value = value
+	case "OID":
+		pdu.Type = gosnmp.ObjectIdentifier
+		if !IsNumericOid(value) {
+			return pdu, fmt.Errorf("Expected a numeric OID value, got [%v]", value)
+		}
+		if !strings.HasPrefix(value, ".") {
+			value = "." + value
+		}
+		pdu.Value = value
+	default:
+		return pdu, fmt.Errorf("Unsupported type [%v]", tag)
+	}
+	if err != nil {
+		return pdu, fmt.Errorf("Bad %v value [%v]: %v", tag, value, err)
+	}
+	return pdu, nil
+}
+
+// parseSnmpWalkUint32 parses an unsigned 32 bit value.
+func parseSnmpWalkUint32(value string) (uint32, error) {
+	u, err := strconv.ParseUint(value, 10, 32)
+	return uint32(u), err
+}
+
+// parseSnmpWalkString parses a quoted STRING value. net-snmp escapes
+// double quotes and backslashes in the string with a backslash.
+func parseSnmpWalkString(value string) ([]byte, error) {
+	if len(value) < 2 || !strings.HasPrefix(value, "\"") || !strings.HasSuffix(value, "\"") {
+		return nil, fmt.Errorf("Expected a quoted string, got [%v]", value)
+	}
+	value = value[1 : len(value)-1]
+	result := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+		result = append(result, value[i])
+	}
+	return result, nil
+}
+
+// FormatSnmpWalkLine formats a ReadResult as a line of snmpwalk output, the
+// way snmpwalk -ObentU prints it, so that ParseSnmpWalkLine parses it back.
+// Strings that are not printable ASCII are Hex-STRING. Null values and
+// Opaque floats can not be formatted.
+func FormatSnmpWalkLine(result ReadResult) (string, error) { // nolint: gocyclo
+	oid := result.Oid
+	if !strings.HasPrefix(oid, ".") {
+		oid = "." + oid
+	}
+
+	var value string
+	switch result.Type {
+	case gosnmp.OctetString, gosnmp.Opaque:
+		octets, err := result.Bytes()
+		if err != nil {
+			return "", err
+		}
+		value = formatSnmpWalkString(octets)
+	case gosnmp.Integer:
+		i, err := result.Int64()
+		if err != nil {
+			return "", err
+		}
+		value = fmt.Sprintf("INTEGER: %d", i)
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.Counter64:
+		u, err := result.Uint64()
+		if err != nil {
+			return "", err
+		}
+		value = fmt.Sprintf("%v: %d", result.TypeName(), u)
+	case gosnmp.TimeTicks:
+		u, err := result.Uint64()
+		if err != nil {
+			return "", err
+		}
+		value = fmt.Sprintf("Timeticks: (%d) %v", u, formatTimeTicks(u))
+	case gosnmp.IPAddress:
+		address, err := result.Text()
+		if err != nil {
+			return "", err
+		}
+		value = "IpAddress: " + address
+	case gosnmp.ObjectIdentifier:
+		oidValue, err := result.OID()
+		if err != nil {
+			return "", err
+		}
+		value = "OID: " + oidValue
+	default:
+		return "", fmt.Errorf("Unable to format %v value for %v", result.TypeName(), oid)
+	}
+	return oid + " = " + value, nil
+}
+
+// formatSnmpWalkString formats an OCTET STRING like net-snmp, quoted if it is
+// printable ASCII and hex otherwise.
+func formatSnmpWalkString(octets []byte) string {
+	for _, b := range octets {
+		if b < 0x20 || b > 0x7e {
+			var buffer strings.Builder
+			buffer.WriteString("Hex-STRING: ")
+			for _, b := range octets {
+				fmt.Fprintf(&buffer, "%02X ", b)
+			}
+			return buffer.String()
+		}
+	}
+	escaped := strings.Replace(string(octets), "\\", "\\\\", -1)
+	escaped = strings.Replace(escaped, "\"", "\\\"", -1)
+	return "STRING: \"" + escaped + "\""
+}
+
+// formatTimeTicks formats hundredths of a second like net-snmp. Example:
+// 1 day, 19:15:02.66
+func formatTimeTicks(ticks uint64) string {
+	days := ticks / 8640000
+	ticks %= 8640000
+	clock := fmt.Sprintf("%d:%02d:%02d.%02d", ticks/360000, ticks/6000%60, ticks/100%60, ticks%100)
+	switch days {
+	case 0:
+		return clock
+	case 1:
+		return "1 day, " + clock
+	}
+	return fmt.Sprintf("%d days, %v", days, clock)
+}
+
+// snmpRecTypes are the ASN.1 types by snmprec tag.
+var snmpRecTypes = map[string]gosnmp.Asn1BER{
+	"2":  gosnmp.Integer,
+	"4":  gosnmp.OctetString,
+	"5":  gosnmp.Null,
+	"6":  gosnmp.ObjectIdentifier,
+	"64": gosnmp.IPAddress,
+	"65": gosnmp.Counter32,
+	"66": gosnmp.Gauge32,
+	"67": gosnmp.TimeTicks,
+	"68": gosnmp.Opaque,
+	"70": gosnmp.Counter64,
+}
+
+// ParseSnmpRec parses snmpsim snmprec data, one OID|tag|value per line where
+// the tag is the numeric ASN.1 tag. An x after the tag means the value is hex.
+//
+//	1.3.6.1.2.1.1.5.0|4|PowerXpert-00-20-85-F1-56-DE
+//	1.3.6.1.2.1.2.2.1.6.2|4x|002085f156de
+//
+// Variation modules are not supported. Blank lines and lines starting with #
+// are skipped.
+func ParseSnmpRec(reader io.Reader) (pdus []gosnmp.SnmpPDU, err error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pdu, err := parseSnmpRecLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		pdus = append(pdus, pdu)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pdus, nil
+}
+
+// parseSnmpRecLine parses one line of snmprec data.
+func parseSnmpRecLine(line string) (pdu gosnmp.SnmpPDU, err error) { // nolint: gocyclo
+	fields := strings.SplitN(line, "|", 3)
+	if len(fields) != 3 {
+		return pdu, fmt.Errorf("Expected OID|tag|value, got [%v]", line)
+	}
+	if !IsNumericOid(fields[0]) {
+		return pdu, fmt.Errorf("Expected a numeric OID, got [%v]", fields[0])
+	}
+	pdu.Name = fields[0]
+	if !strings.HasPrefix(pdu.Name, ".") {
+		pdu.Name = "." + pdu.Name
+	}
+
+	tag, value := fields[1], fields[2]
+	hexValue := strings.HasSuffix(tag, "x")
+	tag = strings.TrimSuffix(tag, "x")
+	pduType, ok := snmpRecTypes[tag]
+	if !ok {
+		return pdu, fmt.Errorf("Unsupported tag [%v]", fields[1])
+	}
+	pdu.Type = pduType
+
+	if hexValue {
+		octets, err := hex.DecodeString(value)
+		if err != nil {
+			return pdu, fmt.Errorf("Bad hex value [%v]: %v", value, err)
+		}
+		switch {
+		case pduType == gosnmp.OctetString || pduType == gosnmp.Opaque:
+			pdu.Value = octets
+		case pduType == gosnmp.IPAddress && len(octets) == net.IPv4len:
+			pdu.Value = net.IP(octets).String()
+		default:
+			return pdu, fmt.Errorf("Bad hex value for tag [%v]", fields[1])
+		}
+		return pdu, nil
+	}
+
+	switch pduType {
+	case gosnmp.Integer:
+		var i int64
+		i, err = strconv.ParseInt(value, 10, 32)
+		pdu.Value = int(i)
+	case gosnmp.OctetString, gosnmp.Opaque:
+		pdu.Value = []byte(value)
+	case gosnmp.Null:
+		pdu.Value = nil
+	case gosnmp.ObjectIdentifier:
+		if !IsNumericOid(value) {
+			return pdu, fmt.Errorf("Expected a numeric OID value, got [%v]", value)
+		}
+		if !strings.HasPrefix(value, ".") {
+			value = "." + value
+		}
+		pdu.Value = value
+	case gosnmp.IPAddress:
+		pdu.Value = value
+	case gosnmp.Counter32, gosnmp.Gauge32:
+		var u uint64
+		u, err = strconv.ParseUint(value, 10, 32)
+		pdu.Value = uint(u)
+	case gosnmp.TimeTicks:
+		pdu.Value, err = parseSnmpWalkUint32(value)
+	case gosnmp.Counter64:
+		pdu.Value, err = strconv.ParseUint(value, 10, 64)
+	}
+	if err != nil {
+		return pdu, fmt.Errorf("Bad value [%v] for tag %v: %v", value, tag, err)
+	}
+	return pdu, nil
+}
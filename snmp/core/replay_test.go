@@ -0,0 +1,240 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/soniah/gosnmp"
+)
+
+// TestParseSnmpWalk checks the value types in snmpwalk output.
+func TestParseSnmpWalk(t *testing.T) {
+	text := `
+# A comment.
+.1.3.6.1.2.1.1.1.0 = STRING: "Linux \"quoted\" ppc"
+.1.3.6.1.2.1.1.2.0 = OID: .1.3.6.1.4.1.534.2.12
+.1.3.6.1.2.1.1.3.0 = 6930266
+.1.3.6.1.2.1.1.4.0 = Timeticks: (42) 0:00:00.42
+.1.3.6.1.2.1.2.1.0 = INTEGER: -4
+.1.3.6.1.2.1.2.2.1.8.1 = INTEGER: up(1)
+.1.3.6.1.2.1.2.2.1.6.2 = Hex-STRING: 00 20 85 F1 56 DE 
+.1.3.6.1.2.1.2.2.1.6.1 = ""
+.1.3.6.1.2.1.2.2.1.10.1 = Counter32: 4294967295
+.1.3.6.1.2.1.2.2.1.5.1 = Gauge32: 10000000
+.1.3.6.1.2.1.31.1.1.1.6.1 = Counter64: 18446744073709551615
+.1.3.6.1.2.1.4.20.1.1.10.193.3.201 = IpAddress: 10.193.3.201
+`
+	pdus, err := ParseSnmpWalk(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte(`Linux "quoted" ppc`)},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.534.2.12"},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(6930266)},
+		{Name: ".1.3.6.1.2.1.1.4.0", Type: gosnmp.TimeTicks, Value: uint32(42)},
+		{Name: ".1.3.6.1.2.1.2.1.0", Type: gosnmp.Integer, Value: -4},
+		{Name: ".1.3.6.1.2.1.2.2.1.8.1", Type: gosnmp.Integer, Value: 1},
+		{Name: ".1.3.6.1.2.1.2.2.1.6.2", Type: gosnmp.OctetString, Value: []byte{0x00, 0x20, 0x85, 0xf1, 0x56, 0xde}},
+		{Name: ".1.3.6.1.2.1.2.2.1.6.1", Type: gosnmp.OctetString, Value: []byte{}},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(4294967295)},
+		{Name: ".1.3.6.1.2.1.2.2.1.5.1", Type: gosnmp.Gauge32, Value: uint(10000000)},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)},
+		{Name: ".1.3.6.1.2.1.4.20.1.1.10.193.3.201", Type: gosnmp.IPAddress, Value: "10.193.3.201"},
+	}
+	if len(pdus) != len(expected) {
+		t.Fatalf("Expected %d PDUs, got %d", len(expected), len(pdus))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(pdus[i], expected[i]) {
+			t.Fatalf("Expected %#v, got %#v", expected[i], pdus[i])
+		}
+	}
+
+	for _, bad := range []string{
+		"no equals sign",
+		".1.3.6 = Float: 1.5",
+		".1.3.6 = INTEGER: many",
+		"sysName.0 = STRING: \"x\"",
+		".1.3.6 = STRING: unquoted",
+	} {
+		if _, err = ParseSnmpWalk(strings.NewReader(bad)); err == nil {
+			t.Fatalf("Expected error parsing [%v]", bad)
+		}
+	}
+}
+
+// TestParseSnmpRec checks the value types in snmprec data.
+func TestParseSnmpRec(t *testing.T) {
+	text := `
+# A comment.
+1.3.6.1.2.1.1.1.0|4|Linux ppc
+1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.534.2.12
+1.3.6.1.2.1.1.3.0|67|6930266
+1.3.6.1.2.1.2.1.0|2|-4
+1.3.6.1.2.1.2.2.1.6.2|4x|002085f156de
+1.3.6.1.2.1.2.2.1.6.1|4|
+1.3.6.1.2.1.2.2.1.10.1|65|4294967295
+1.3.6.1.2.1.2.2.1.5.1|66|10000000
+1.3.6.1.2.1.31.1.1.1.6.1|70|18446744073709551615
+1.3.6.1.2.1.4.20.1.1.10.193.3.201|64|10.193.3.201
+1.3.6.1.2.1.4.20.1.1.10.193.3.202|64x|0ac103ca
+`
+	pdus, err := ParseSnmpRec(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("Linux ppc")},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.534.2.12"},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(6930266)},
+		{Name: ".1.3.6.1.2.1.2.1.0", Type: gosnmp.Integer, Value: -4},
+		{Name: ".1.3.6.1.2.1.2.2.1.6.2", Type: gosnmp.OctetString, Value: []byte{0x00, 0x20, 0x85, 0xf1, 0x56, 0xde}},
+		{Name: ".1.3.6.1.2.1.2.2.1.6.1", Type: gosnmp.OctetString, Value: []byte{}},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(4294967295)},
+		{Name: ".1.3.6.1.2.1.2.2.1.5.1", Type: gosnmp.Gauge32, Value: uint(10000000)},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)},
+		{Name: ".1.3.6.1.2.1.4.20.1.1.10.193.3.201", Type: gosnmp.IPAddress, Value: "10.193.3.201"},
+		{Name: ".1.3.6.1.2.1.4.20.1.1.10.193.3.202", Type: gosnmp.IPAddress, Value: "10.193.3.202"},
+	}
+	if len(pdus) != len(expected) {
+		t.Fatalf("Expected %d PDUs, got %d", len(expected), len(pdus))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(pdus[i], expected[i]) {
+			t.Fatalf("Expected %#v, got %#v", expected[i], pdus[i])
+		}
+	}
+
+	for _, bad := range []string{
+		"1.3.6.1",
+		"1.3.6.1|99|x",
+		"1.3.6.1|2|many",
+		"sysName.0|4|x",
+		"1.3.6.1|2x|00",
+		"1.3.6.1|4x|zz",
+	} {
+		if _, err = ParseSnmpRec(strings.NewReader(bad)); err == nil {
+			t.Fatalf("Expected error parsing [%v]", bad)
+		}
+	}
+}
+
+// TestFormatSnmpWalkLine formats values as snmpwalk output and parses them
+// back.
+func TestFormatSnmpWalkLine(t *testing.T) {
+	for _, tc := range []struct {
+		pdu      gosnmp.SnmpPDU
+		expected string
+	}{
+		{gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte(`Linux "ppc"`)}, `.1.3.6.1.2.1.1.1.0 = STRING: "Linux \"ppc\""`},
+		{gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.534.2.12"}, ".1.3.6.1.2.1.1.2.0 = OID: .1.3.6.1.4.1.534.2.12"},
+		{gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(16930266)}, ".1.3.6.1.2.1.1.3.0 = Timeticks: (16930266) 1 day, 23:01:42.66"},
+		{gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.1.0", Type: gosnmp.Integer, Value: -4}, ".1.3.6.1.2.1.2.1.0 = INTEGER: -4"},
+		{gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.6.2", Type: gosnmp.OctetString, Value: []byte{0x00, 0x20, 0x85, 0xf1, 0x56, 0xde}}, ".1.3.6.1.2.1.2.2.1.6.2 = Hex-STRING: 00 20 85 F1 56 DE "},
+		{gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(4294967295)}, ".1.3.6.1.2.1.2.2.1.10.1 = Counter32: 4294967295"},
+		{gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.5.1", Type: gosnmp.Gauge32, Value: uint(10000000)}, ".1.3.6.1.2.1.2.2.1.5.1 = Gauge32: 10000000"},
+		{gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)}, ".1.3.6.1.2.1.31.1.1.1.6.1 = Counter64: 18446744073709551615"},
+		{gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.4.20.1.1.10.193.3.201", Type: gosnmp.IPAddress, Value: "10.193.3.201"}, ".1.3.6.1.2.1.4.20.1.1.10.193.3.201 = IpAddress: 10.193.3.201"},
+	} {
+		line, err := FormatSnmpWalkLine(NewReadResult(tc.pdu))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != tc.expected {
+			t.Fatalf("Expected %q, got %q", tc.expected, line)
+		}
+
+		pdus, err := ParseSnmpWalk(strings.NewReader(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pdus) != 1 || pdus[0].Type != tc.pdu.Type ||
+			NewReadResult(pdus[0]).Format() != NewReadResult(tc.pdu).Format() {
+			t.Fatalf("Expected %#v to parse back, got %#v", tc.pdu, pdus)
+		}
+	}
+
+	if _, err := FormatSnmpWalkLine(ReadResult{Oid: ".1.3.6.1", Type: gosnmp.Null}); err == nil {
+		t.Fatal("Expected an error for a null value")
+	}
+}
+
+// TestReplayTransport checks get and walk against the emulator data.
+func TestReplayTransport(t *testing.T) {
+	replay, err := LoadReplayTransport("../../emulator/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &DeviceConfig{ContextName: "public"}
+
+	pdus, err := replay.Get(config, []string{
+		".1.3.6.1.2.1.33.1.1.2.0", // upsIdentModel.0
+		".1.3.6.1.2.1.33.1.1.2.1", // No such instance.
+		".1.3.6.1.2.1.33.99.0",    // No such object.
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pdus) != 3 || string(pdus[0].Value.([]byte)) != "PXGMS UPS + EATON 93PM" ||
+		pdus[1].Type != gosnmp.NoSuchInstance || pdus[2].Type != gosnmp.NoSuchObject {
+		t.Fatalf("Unexpected get response %+v", pdus)
+	}
+
+	// Walk returns the subtree in OID order, .10 after .9.
+	pdus, err = replay.Walk(config, ".1.3.6.1.2.1.2.2.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, pdu := range pdus {
+		names = append(names, pdu.Name)
+	}
+	expected := []string{".1.3.6.1.2.1.2.2.1.1.1", ".1.3.6.1.2.1.2.2.1.1.2", ".1.3.6.1.2.1.2.2.1.1.3", ".1.3.6.1.2.1.2.2.1.1.4"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	pdus, err = replay.Walk(config, ".1.3.6.1.2.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(pdus); i++ {
+		previous, _ := NewOid(pdus[i-1].Name)
+		current, _ := NewOid(pdus[i].Name)
+		if CompareOids(previous.ToSlice, current.ToSlice) >= 0 {
+			t.Fatalf("Walk out of order at %v, %v", pdus[i-1].Name, pdus[i].Name)
+		}
+	}
+
+	// A leaf walks to itself.
+	pdus, err = replay.Walk(config, ".1.3.6.1.2.1.1.5.0")
+	if err != nil || len(pdus) != 1 || pdus[0].Name != ".1.3.6.1.2.1.1.5.0" {
+		t.Fatalf("Expected sysName.0, got %+v, %v", pdus, err)
+	}
+
+	// Unknown contexts are errors, like a misconfigured agent.
+	if _, err = replay.Get(&DeviceConfig{ContextName: "private"}, []string{".1.3.6.1.2.1.1.5.0"}); err == nil {
+		t.Fatal("Expected error for unknown context")
+	}
+
+	// A set is read back. Only existing OIDs with the same type can be set.
+	outputVoltage := ".1.3.6.1.2.1.33.1.9.3.0" // upsConfigOutputVoltage.0
+	if err = replay.Set(config, []gosnmp.SnmpPDU{{Name: outputVoltage, Type: gosnmp.Integer, Value: 480}}); err != nil {
+		t.Fatal(err)
+	}
+	pdus, err = replay.Get(config, []string{outputVoltage})
+	if err != nil || pdus[0].Value != 480 {
+		t.Fatalf("Expected 480, got %+v, %v", pdus, err)
+	}
+	for _, pdu := range []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.33.1.9.3.1", Type: gosnmp.Integer, Value: 1},
+		{Name: outputVoltage, Type: gosnmp.OctetString, Value: []byte("480")},
+	} {
+		if err = replay.Set(config, []gosnmp.SnmpPDU{pdu}); err == nil {
+			t.Fatalf("Expected error for %+v", pdu)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 )
@@ -19,6 +20,10 @@ type SnmpRow struct {
 	RowData []*ReadResult
 }
 
+// LineKey is the device data key of the index of the table row of a device,
+// such as the line of a UPS input, output or bypass. See SnmpRow.Index.
+const LineKey = "line"
+
 // NewSnmpRow creates the SnmpRow structure.
 func NewSnmpRow(baseOid string, table *SnmpTable, rowData []*ReadResult) (*SnmpRow, error) {
 	// Arg checks.
@@ -49,6 +54,72 @@ func (snmpRow *SnmpRow) Dump() {
 	log.Debugf("Dumping row for SNMP table %v", snmpRow.Table.Name)
 	log.Debugf("baseOid: %v", snmpRow.BaseOid)
 	for i := 0; i < len(snmpRow.Table.ColumnList); i++ {
-		log.Debugf("row[%v] = %v", snmpRow.Table.ColumnList[i], snmpRow.RowData[i])
+		log.Debugf("row[%v] = %v %v", snmpRow.Table.ColumnList[i],
+			OidDisplay(snmpRow.RowData[i].Oid), snmpRow.RowData[i].Format())
+	}
+}
+
+// Index returns the index of the row, the last arc of its base OID, such as
+// "1" for the first line of a UPS.
+func (snmpRow *SnmpRow) Index() string {
+	return snmpRow.BaseOid[strings.LastIndex(snmpRow.BaseOid, ".")+1:]
+}
+
+// RowDevicesKey is the dynamicRegistration key that enumerates a device per
+// line of a UPS, with an output per column, rather than a device per column.
+// A row device reads all of its columns in one request.
+const RowDevicesKey = "rowDevices"
+
+// The device data of a row device has the OID of each output, and the
+// multiplier if any, keyed by output type:
+//
+//	oid.voltage: .1.3.6.1.2.1.33.1.4.4.1.2.1
+//	oid.current: .1.3.6.1.2.1.33.1.4.4.1.3.1
+//	multiplier.current: 0.1
+const (
+	RowOidPrefix        = "oid."
+	RowMultiplierPrefix = "multiplier."
+)
+
+// IsRowDevices returns true if a dynamicRegistration entry asks for row
+// devices.
+func IsRowDevices(data map[string]interface{}) bool {
+	rowDevices, ok := data[RowDevicesKey].(bool)
+	return ok && rowDevices
+}
+
+// RowOids returns the OIDs of a row device by output type. It is empty for
+// other devices.
+func RowOids(data map[string]interface{}) map[string]string {
+	oids := map[string]string{}
+	for key, value := range data {
+		if strings.HasPrefix(key, RowOidPrefix) {
+			oids[strings.TrimPrefix(key, RowOidPrefix)] = fmt.Sprint(value)
+		}
+	}
+	return oids
+}
+
+// RowSortOid returns the lowest numeric OID of a row device. It sorts and
+// identifies the device, like the OID of a device for a single column.
+func RowSortOid(data map[string]interface{}) (string, error) {
+	var lowest string
+	var lowestSlice []uint64
+	for _, oid := range RowOids(data) {
+		numericOid, err := ResolveOid(oid)
+		if err != nil {
+			return "", err
+		}
+		parsed, err := NewOid(numericOid)
+		if err != nil {
+			return "", err
+		}
+		if lowestSlice == nil || CompareOids(parsed.ToSlice, lowestSlice) < 0 {
+			lowest, lowestSlice = numericOid, parsed.ToSlice
+		}
+	}
+	if lowestSlice == nil {
+		return "", fmt.Errorf("No row OIDs in device data")
 	}
+	return lowest, nil
 }
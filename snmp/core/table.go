@@ -3,6 +3,7 @@ package core
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 
@@ -42,6 +43,8 @@ type SnmpTable struct {
 
 	// The row data in the table.
 	Rows []SnmpRow
+	// The time the rows were last loaded from the SNMP server.
+	Loaded time.Time
 
 	// Overrideable interface for device enumeration.
 	DevEnumerator DeviceEnumeratorInterface
@@ -85,6 +88,14 @@ func NewSnmpTable(
 	if name == "" {
 		return nil, fmt.Errorf("NewSnmpTable. name is empty")
 	}
+	if !IsNumericOid(walkOid) && walkOid != "" {
+		// Symbolic, for example UPS-MIB::upsBattery.
+		resolved, err := ResolveOid(walkOid)
+		if err != nil {
+			return nil, fmt.Errorf("NewSnmpTable. %v", err)
+		}
+		walkOid = resolved
+	}
 	if !strings.HasPrefix(walkOid, ".") {
 		return nil, fmt.Errorf(
 			"NewSnmpTable. walkOid must start with a period, walkOid: %v", walkOid)
@@ -120,9 +131,9 @@ func NewSnmpTable(
 func (snmpTable *SnmpTable) Dump() {
 	// Header
 	log.Debugf("Dumping %v table. %d rows. walk oid: %v",
-		snmpTable.Name, len(snmpTable.Rows), snmpTable.WalkOid)
+		snmpTable.Name, len(snmpTable.Rows), OidDisplay(snmpTable.WalkOid))
 	fmt.Printf("Dumping %v table. %d rows. walk oid: %v\n",
-		snmpTable.Name, len(snmpTable.Rows), snmpTable.WalkOid)
+		snmpTable.Name, len(snmpTable.Rows), OidDisplay(snmpTable.WalkOid))
 	// Column list
 	log.Debugf("%v", strings.Join(snmpTable.ColumnList, ","))
 	fmt.Printf("%v\n", strings.Join(snmpTable.ColumnList, ","))
@@ -161,7 +172,11 @@ func (snmpTable *SnmpTable) Load() error {
 		return err
 	}
 	err = snmpTable.translate(rawResults)
-	return err
+	if err != nil {
+		return err
+	}
+	snmpTable.Loaded = time.Now()
+	return nil
 }
 
 // Unload cached row data once we're done with it.
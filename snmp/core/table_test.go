@@ -2,6 +2,8 @@ package core
 
 import (
 	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
 )
 
 // TestTable
@@ -11,24 +13,8 @@ func TestTable(t *testing.T) {
 	// In order to create the table, we need to create an SNMP Server.
 	// In order to create the SNMP server, we need to have an SnmpClient.
 
-	// Create SecurityParameters for the config that should connect to the emulator.
-	securityParameters, err := NewSecurityParameters(
-		"simulator",  // User Name
-		SHA,          // Authentication Protocol
-		"auctoritas", // Authentication Passphrase
-		AES,          // Privacy Protocol
-		"privatus")   // Privacy Passphrase
-	if err != nil {
-		t.Fatal(err) // Fail the test.
-	}
-
-	// Create a config.
-	config, err := NewDeviceConfig(
-		"v3",        // SNMP v3
-		"127.0.0.1", // Endpoint
-		1024,        // Port
-		securityParameters,
-		"public") //  Context name
+	// Create a config that connects to the emulator.
+	config, err := GetDeviceConfig(emulator.AgentData())
 	if err != nil {
 		t.Fatal(err) // Fail the test.
 	}
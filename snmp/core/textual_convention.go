@@ -0,0 +1,499 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/soniah/gosnmp"
+)
+
+// This file contains formatting of SNMP values driven by the TEXTUAL-CONVENTION
+// of the object, RFC 2579. Most textual conventions are formatted by their
+// DISPLAY-HINT. A few that can not be expressed as a DISPLAY-HINT, or where a
+// standard representation is more useful, are formatted specially.
+
+// TextualConvention is an SNMP TEXTUAL-CONVENTION.
+type TextualConvention struct {
+	Name        string // Name of the textual convention. Example: DisplayString
+	DisplayHint string // DISPLAY-HINT clause, if any. Example: 255a
+}
+
+// Textual conventions with special formatting.
+const (
+	DisplayString    = "DisplayString"
+	SnmpAdminString  = "SnmpAdminString"
+	MacAddress       = "MacAddress"
+	PhysAddress      = "PhysAddress"
+	DateAndTime      = "DateAndTime"
+	InetAddress      = "InetAddress"
+	InetAddressIPv4  = "InetAddressIPv4"
+	InetAddressIPv6  = "InetAddressIPv6"
+	InetAddressIPv4z = "InetAddressIPv4z"
+	InetAddressIPv6z = "InetAddressIPv6z"
+	InetAddressDNS   = "InetAddressDNS"
+)
+
+// InetAddressType values from INET-ADDRESS-MIB, RFC 4001.
+const (
+	InetAddressTypeUnknown = 0
+	InetAddressTypeIPv4    = 1
+	InetAddressTypeIPv6    = 2
+	InetAddressTypeIPv4z   = 3
+	InetAddressTypeIPv6z   = 4
+	InetAddressTypeDNS     = 16
+)
+
+// inetAddressLengths are the lengths of the InetAddress values of the IP
+// InetAddressTypes.
+var inetAddressLengths = map[int]int{
+	InetAddressTypeIPv4:  4,
+	InetAddressTypeIPv6:  16,
+	InetAddressTypeIPv4z: 8,
+	InetAddressTypeIPv6z: 20,
+}
+
+// textualConventions is the registry of known textual conventions by name.
+// It starts out with the common ones from SNMPv2-TC, SNMP-FRAMEWORK-MIB and
+// INET-ADDRESS-MIB. More can be registered as MIBs are loaded.
+var textualConventions = struct {
+	sync.RWMutex
+	byName map[string]*TextualConvention
+}{
+	byName: map[string]*TextualConvention{
+		DisplayString:    {Name: DisplayString, DisplayHint: "255a"},
+		SnmpAdminString:  {Name: SnmpAdminString, DisplayHint: "255t"},
+		MacAddress:       {Name: MacAddress, DisplayHint: "1x:"},
+		PhysAddress:      {Name: PhysAddress, DisplayHint: "1x:"},
+		DateAndTime:      {Name: DateAndTime, DisplayHint: "2d-1d-1d,1d:1d:1d.1d,1a1d:1d"},
+		InetAddress:      {Name: InetAddress},
+		InetAddressIPv4:  {Name: InetAddressIPv4, DisplayHint: "1d.1d.1d.1d"},
+		InetAddressIPv6:  {Name: InetAddressIPv6, DisplayHint: "2x:2x:2x:2x:2x:2x:2x:2x"},
+		InetAddressIPv4z: {Name: InetAddressIPv4z, DisplayHint: "1d.1d.1d.1d%4d"},
+		InetAddressIPv6z: {Name: InetAddressIPv6z, DisplayHint: "2x:2x:2x:2x:2x:2x:2x:2x%4d"},
+		InetAddressDNS:   {Name: InetAddressDNS, DisplayHint: "255a"},
+	},
+}
+
+// RegisterTextualConvention adds a textual convention to the registry, or
+// replaces the existing one with the same name.
+func RegisterTextualConvention(tc *TextualConvention) error {
+	if tc == nil {
+		return fmt.Errorf("tc is nil")
+	}
+	if tc.Name == "" {
+		return fmt.Errorf("tc name is empty")
+	}
+	textualConventions.Lock()
+	defer textualConventions.Unlock()
+	textualConventions.byName[tc.Name] = tc
+	return nil
+}
+
+// LookupTextualConvention gets a textual convention by name, or nil if it is
+// not known.
+func LookupTextualConvention(name string) *TextualConvention {
+	textualConventions.RLock()
+	defer textualConventions.RUnlock()
+	return textualConventions.byName[name]
+}
+
+// Bytes returns an OctetString or Opaque value as a byte slice.
+func (result ReadResult) Bytes() ([]byte, error) {
+	switch v := result.Data.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		if result.Type == gosnmp.OctetString || result.Type == gosnmp.Opaque {
+			return []byte(v), nil
+		}
+	}
+	return nil, result.typeError("OctetString")
+}
+
+// FormatTextualConvention formats the value for display using the named
+// textual convention, or the given DISPLAY-HINT if the textual convention is
+// empty or has no hint of its own. With neither, this is the same as Format.
+func (result ReadResult) FormatTextualConvention(textualConvention string, displayHint string) (string, error) {
+	if result.IsNull() {
+		return "", nil
+	}
+
+	if textualConvention != "" {
+		tc := LookupTextualConvention(textualConvention)
+		if tc == nil {
+			return "", fmt.Errorf("Unknown textual convention %v", textualConvention)
+		}
+
+		// Special cases first.
+		switch tc.Name {
+		case DateAndTime:
+			octets, err := result.Bytes()
+			if err != nil {
+				return "", err
+			}
+			return FormatDateAndTime(octets)
+		case InetAddress:
+			// Without the InetAddressType that goes with the address, infer
+			// the type from the length. See ReadResult.FormatInetAddress.
+			return result.FormatInetAddress(InetAddressTypeUnknown)
+		case InetAddressIPv6:
+			octets, err := result.Bytes()
+			if err != nil {
+				return "", err
+			}
+			return FormatInetAddress(InetAddressTypeIPv6, octets)
+		}
+
+		if tc.DisplayHint != "" {
+			displayHint = tc.DisplayHint
+		}
+	}
+
+	if displayHint == "" {
+		return result.Format(), nil
+	}
+
+	// Integer hints start with the format character. Octet string hints start
+	// with a length or the repeat indicator.
+	if result.IsNumeric() && result.Type != gosnmp.Opaque {
+		value, err := result.Int64()
+		if err != nil {
+			return "", err
+		}
+		return FormatIntegerDisplayHint(value, displayHint)
+	}
+
+	octets, err := result.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return FormatOctetStringDisplayHint(octets, displayHint)
+}
+
+// FormatIntegerDisplayHint formats an integer with an integer DISPLAY-HINT
+// from RFC 2579: "d" or "d-N" for decimal with N implied decimal places, "x"
+// for hex, "o" for octal and "b" for binary.
+func FormatIntegerDisplayHint(value int64, displayHint string) (string, error) {
+	if displayHint == "" {
+		return "", fmt.Errorf("displayHint is empty")
+	}
+
+	switch displayHint[0] {
+	case 'x':
+		return strconv.FormatInt(value, 16), nil
+	case 'o':
+		return strconv.FormatInt(value, 8), nil
+	case 'b':
+		return strconv.FormatInt(value, 2), nil
+	case 'd':
+	default:
+		return "", fmt.Errorf("Unsupported integer display hint %v", displayHint)
+	}
+
+	if displayHint == "d" {
+		return strconv.FormatInt(value, 10), nil
+	}
+	if !strings.HasPrefix(displayHint, "d-") {
+		return "", fmt.Errorf("Unsupported integer display hint %v", displayHint)
+	}
+	places, err := strconv.Atoi(displayHint[2:])
+	if err != nil || places < 0 {
+		return "", fmt.Errorf("Invalid integer display hint %v", displayHint)
+	}
+	if places == 0 {
+		return strconv.FormatInt(value, 10), nil
+	}
+
+	// Fixed point. Insert the decimal point into the digits.
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	digits := strconv.FormatInt(value, 10)
+	if len(digits) <= places {
+		digits = strings.Repeat("0", places-len(digits)+1) + digits
+	}
+	point := len(digits) - places
+	return sign + digits[:point] + "." + digits[point:], nil
+}
+
+// octetDisplaySpec is one octet-format specification from an octet string
+// DISPLAY-HINT.
+type octetDisplaySpec struct {
+	Repeat     bool  // Leading '*'. The first octet is a repeat count.
+	Length     int   // Number of octets to consume.
+	Format     byte  // One of d, x, o, a, t.
+	Separator  *byte // Display separator character, if any.
+	Terminator *byte // Repeat terminator character, if any. Only with Repeat.
+}
+
+// parseOctetDisplayHint parses an octet string DISPLAY-HINT into its
+// specifications.
+func parseOctetDisplayHint(displayHint string) (specs []octetDisplaySpec, err error) {
+	i := 0
+	for i < len(displayHint) {
+		spec := octetDisplaySpec{}
+		if displayHint[i] == '*' {
+			spec.Repeat = true
+			i++
+		}
+
+		start := i
+		for i < len(displayHint) && displayHint[i] >= '0' && displayHint[i] <= '9' {
+			i++
+		}
+		if start == i {
+			return nil, fmt.Errorf("Missing length in display hint %v at %d", displayHint, i)
+		}
+		spec.Length, err = strconv.Atoi(displayHint[start:i])
+		if err != nil || spec.Length == 0 {
+			return nil, fmt.Errorf("Invalid length %v in display hint %v", displayHint[start:i], displayHint)
+		}
+
+		if i >= len(displayHint) {
+			return nil, fmt.Errorf("Missing format in display hint %v", displayHint)
+		}
+		spec.Format = displayHint[i]
+		switch spec.Format {
+		case 'd', 'x', 'o':
+			// The octets are an unsigned integer. More than 8 overflow uint64.
+			if spec.Length > 8 {
+				return nil, fmt.Errorf("Length %d too long for format %c in display hint %v",
+					spec.Length, spec.Format, displayHint)
+			}
+		case 'a', 't':
+		default:
+			return nil, fmt.Errorf("Unsupported format %c in display hint %v", spec.Format, displayHint)
+		}
+		i++
+
+		// The separator is any character that does not start the next spec.
+		if i < len(displayHint) && !isDisplayHintSpecStart(displayHint[i]) {
+			separator := displayHint[i]
+			spec.Separator = &separator
+			i++
+			if spec.Repeat && i < len(displayHint) && !isDisplayHintSpecStart(displayHint[i]) {
+				terminator := displayHint[i]
+				spec.Terminator = &terminator
+				i++
+			}
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("Empty display hint")
+	}
+	return specs, nil
+}
+
+// isDisplayHintSpecStart returns true for characters that can begin an
+// octet-format specification.
+func isDisplayHintSpecStart(c byte) bool {
+	return c == '*' || (c >= '0' && c <= '9')
+}
+
+// FormatOctetStringDisplayHint formats an octet string with an octet string
+// DISPLAY-HINT from RFC 2579. Examples: "1x:" for a MAC address, "255a" for
+// ASCII text, "255t" for UTF-8 text, "1d.1d.1d.1d" for an IPv4 address.
+func FormatOctetStringDisplayHint(value []byte, displayHint string) (string, error) {
+	specs, err := parseOctetDisplayHint(displayHint)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	specIndex := 0
+	for len(value) > 0 {
+		spec := specs[specIndex]
+		// The last specification is reused until the value is exhausted.
+		if specIndex < len(specs)-1 {
+			specIndex++
+		}
+
+		repeat := 1
+		if spec.Repeat {
+			repeat = int(value[0])
+			value = value[1:]
+		}
+
+		for r := 0; r < repeat && len(value) > 0; r++ {
+			length := spec.Length
+			if length > len(value) {
+				length = len(value)
+			}
+			chunk := value[:length]
+			value = value[length:]
+
+			switch spec.Format {
+			case 'a':
+				buffer.Write(chunk)
+			case 't':
+				if !utf8.Valid(chunk) {
+					return "", fmt.Errorf("Invalid UTF-8 %x", chunk)
+				}
+				buffer.Write(chunk)
+			default:
+				// Numeric formats interpret the chunk as a big endian unsigned integer.
+				var number uint64
+				for _, b := range chunk {
+					number = number<<8 | uint64(b)
+				}
+				switch spec.Format {
+				case 'd':
+					buffer.WriteString(strconv.FormatUint(number, 10))
+				case 'x':
+					buffer.WriteString(fmt.Sprintf("%0*x", 2*len(chunk), number))
+				case 'o':
+					buffer.WriteString(strconv.FormatUint(number, 8))
+				}
+			}
+
+			// No separator after the last octets, and the terminator replaces
+			// the separator after the last repetition.
+			if len(value) == 0 {
+				break
+			}
+			if spec.Terminator != nil && r == repeat-1 {
+				buffer.WriteByte(*spec.Terminator)
+			} else if spec.Separator != nil {
+				buffer.WriteByte(*spec.Separator)
+			}
+		}
+	}
+	return buffer.String(), nil
+}
+
+// FormatDateAndTime formats an SNMPv2-TC DateAndTime as RFC 3339. The octets
+// are year (2), month, day, hour, minutes, seconds, deci-seconds and
+// optionally direction from UTC ('+' or '-'), hours and minutes from UTC.
+// Without the UTC offset the time is assumed to be UTC.
+func FormatDateAndTime(value []byte) (string, error) {
+	if len(value) != 8 && len(value) != 11 {
+		return "", fmt.Errorf("DateAndTime must be 8 or 11 octets, got %d", len(value))
+	}
+
+	location := time.UTC
+	if len(value) == 11 {
+		offset := (int(value[9])*60 + int(value[10])) * 60
+		switch value[8] {
+		case '+':
+		case '-':
+			offset = -offset
+		default:
+			return "", fmt.Errorf("Invalid DateAndTime direction from UTC %q", value[8])
+		}
+		location = time.FixedZone("", offset)
+	}
+
+	t := time.Date(
+		int(binary.BigEndian.Uint16(value[0:2])),
+		time.Month(value[2]),
+		int(value[3]),
+		int(value[4]),
+		int(value[5]),
+		int(value[6]),
+		int(value[7])*100000000,
+		location)
+	return t.Format(time.RFC3339Nano), nil
+}
+
+// FormatInetAddress formats an InetAddress value for the InetAddressType of
+// the object that goes with it, such as ipAddressAddrType for
+// ipAddressAddr. If the type is unknown it is inferred from the length.
+func (result ReadResult) FormatInetAddress(addressType int) (string, error) {
+	if result.IsNull() {
+		return "", nil
+	}
+	octets, err := result.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return FormatInetAddress(addressType, octets)
+}
+
+// ParseInetAddressType parses an InetAddressType given as its number, such as
+// 16, or its label, such as dns.
+func ParseInetAddressType(value interface{}) (int, error) {
+	switch fmt.Sprint(value) {
+	case "0", "unknown":
+		return InetAddressTypeUnknown, nil
+	case "1", "ipv4":
+		return InetAddressTypeIPv4, nil
+	case "2", "ipv6":
+		return InetAddressTypeIPv6, nil
+	case "3", "ipv4z":
+		return InetAddressTypeIPv4z, nil
+	case "4", "ipv6z":
+		return InetAddressTypeIPv6z, nil
+	case "16", "dns":
+		return InetAddressTypeDNS, nil
+	}
+	return 0, fmt.Errorf("Unsupported InetAddressType %v", value)
+}
+
+// FormatInetAddress formats an INET-ADDRESS-MIB InetAddress for the given
+// InetAddressType. If the type is unknown it is inferred from the length.
+func FormatInetAddress(addressType int, value []byte) (string, error) {
+	if addressType == InetAddressTypeUnknown {
+		switch len(value) {
+		case 0:
+			return "", nil
+		case 4:
+			addressType = InetAddressTypeIPv4
+		case 8:
+			addressType = InetAddressTypeIPv4z
+		case 16:
+			addressType = InetAddressTypeIPv6
+		case 20:
+			addressType = InetAddressTypeIPv6z
+		default:
+			addressType = InetAddressTypeDNS
+		}
+	}
+
+	if length, ok := inetAddressLengths[addressType]; ok && len(value) != length {
+		return "", fmt.Errorf("Invalid InetAddressType %d address length %d", addressType, len(value))
+	}
+
+	switch addressType {
+	case InetAddressTypeIPv4, InetAddressTypeIPv6:
+		return net.IP(value).String(), nil
+	case InetAddressTypeIPv4z, InetAddressTypeIPv6z:
+		split := len(value) - 4
+		return fmt.Sprintf("%v%%%d", net.IP(value[:split]), binary.BigEndian.Uint32(value[split:])), nil
+	case InetAddressTypeDNS:
+		return TranslatePrintableText(value)
+	}
+	return "", fmt.Errorf("Unsupported InetAddressType %d", addressType)
+}
+
+// TranslatePrintableText translates byte arrays from gosnmp to a string if
+// they are valid UTF-8 (which includes ASCII) without control characters
+// other than whitespace. If this call fails, the caller should normally just
+// keep the raw byte array.
+func TranslatePrintableText(x interface{}) (string, error) {
+	octets, ok := x.([]uint8)
+	if !ok {
+		return "", fmt.Errorf("Failure converting type: %T, data: %v to byte array", x, x)
+	}
+
+	if !utf8.Valid(octets) {
+		return "", fmt.Errorf("Unable to convert %x to UTF-8", octets)
+	}
+	text := string(octets)
+	for _, r := range text {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return "", fmt.Errorf("Unable to convert %x with control character %U to text", octets, r)
+		}
+	}
+	return text, nil
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/soniah/gosnmp"
+)
+
+// TestFormatTextualConvention checks formatting by textual convention and
+// display hint.
+func TestFormatTextualConvention(t *testing.T) {
+	cases := []struct {
+		pdu               gosnmp.SnmpPDU
+		textualConvention string
+		displayHint       string
+		expected          string
+	}{
+		// UTF-8 text is kept as a string.
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("Zürich UPS")}, SnmpAdminString, "", "Zürich UPS"},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{0x00, 0x0c, 0x29, 0xab, 0x01, 0xff}}, MacAddress, "", "00:0c:29:ab:01:ff"},
+		// 2018-05-14 13:30:15.2 -05:00
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{0x07, 0xe2, 5, 14, 13, 30, 15, 2, '-', 5, 0}}, DateAndTime, "", "2018-05-14T13:30:15.2-05:00"},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{0x07, 0xe2, 5, 14, 13, 30, 15, 0}}, DateAndTime, "", "2018-05-14T13:30:15Z"},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{10, 1, 2, 3}}, InetAddress, "", "10.1.2.3"},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{0xfe, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}}, InetAddress, "", "fe80::1"},
+		{gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: 1234}, "", "d-1", "123.4"},
+		{gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: -5}, "", "d-2", "-0.05"},
+		{gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: 255}, "", "x", "ff"},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{192, 168, 0, 1}}, "", "1d.1d.1d.1d", "192.168.0.1"},
+		// Repeat indicator: two 2 octet groups, then the rest as text.
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{2, 0x01, 0x02, 0x03, 0x04, 'o', 'k'}}, "", "*2x,/1a", "0102,0304/ok"},
+		{gosnmp.SnmpPDU{Type: gosnmp.NoSuchInstance}, DateAndTime, "", ""},
+		{gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: 3}, "", "", "3"},
+	}
+
+	for i, c := range cases {
+		result := NewReadResult(c.pdu)
+		actual, err := result.FormatTextualConvention(c.textualConvention, c.displayHint)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if actual != c.expected {
+			t.Fatalf("case %d: expected [%v], got [%v]", i, c.expected, actual)
+		}
+	}
+
+	// Invalid values and hints are errors.
+	result := NewReadResult(gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{1, 2, 3}})
+	if _, err := result.FormatTextualConvention(DateAndTime, ""); err == nil {
+		t.Fatal("Expected error formatting a 3 octet DateAndTime")
+	}
+	if _, err := result.FormatTextualConvention("NoSuchTC", ""); err == nil {
+		t.Fatal("Expected error for an unknown textual convention")
+	}
+
+	// Invalid octet string display hints: no length, a zero length that
+	// consumes nothing, and numbers longer than 8 octets.
+	for _, displayHint := range []string{"x", "0a", "1d.0x", "9d", "1x:16o"} {
+		if _, err := result.FormatTextualConvention("", displayHint); err == nil {
+			t.Fatalf("Expected error for display hint %v", displayHint)
+		}
+	}
+}
+
+// TestFormatInetAddressType checks that an InetAddress is formatted for its
+// InetAddressType rather than its length when the type is known.
+func TestFormatInetAddressType(t *testing.T) {
+	result := NewReadResult(gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("host")})
+	for _, c := range []struct {
+		addressType interface{}
+		expected    string
+	}{
+		{"unknown", "104.111.115.116"},
+		{"ipv4", "104.111.115.116"},
+		{"dns", "host"},
+		{16, "host"},
+	} {
+		addressType, err := ParseInetAddressType(c.addressType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := result.FormatInetAddress(addressType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != c.expected {
+			t.Fatalf("InetAddressType %v: expected [%v], got [%v]", c.addressType, c.expected, actual)
+		}
+	}
+	if _, err := ParseInetAddressType("ipx"); err == nil {
+		t.Fatal("Expected error for an unknown InetAddressType")
+	}
+	if _, err := result.FormatInetAddress(InetAddressTypeIPv6); err == nil {
+		t.Fatal("Expected error formatting 4 octets as IPv6")
+	}
+}
+
+// TestTranslatePrintableText checks which octet strings are treated as text.
+func TestTranslatePrintableText(t *testing.T) {
+	if _, err := TranslatePrintableText([]byte("Ünïcode\tok\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TranslatePrintableText([]byte{0xff, 0xfe}); err == nil {
+		t.Fatal("Expected error for invalid UTF-8")
+	}
+	if _, err := TranslatePrintableText([]byte{0x00, 0x0c, 0x29}); err == nil {
+		t.Fatal("Expected error for control characters")
+	}
+}
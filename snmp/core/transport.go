@@ -0,0 +1,175 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/soniah/gosnmp"
+)
+
+// Transport performs the SNMP operations for an SnmpClient. The default
+// transport talks to the SNMP agent over UDP with gosnmp. Tests replace it with
+// a ReplayTransport so they run without an SNMP agent.
+type Transport interface {
+	// Get gets the given numeric OIDs. There is one PDU per OID in the
+	// response. OIDs that do not exist are NoSuchObject or NoSuchInstance.
+	Get(config *DeviceConfig, oids []string) ([]gosnmp.SnmpPDU, error)
+	// Walk walks the subtree under the given numeric OID in OID order. If the
+	// OID is a leaf, the result is the leaf.
+	Walk(config *DeviceConfig, rootOid string) ([]gosnmp.SnmpPDU, error)
+	// Set sets the given PDUs, all or none. An error status in the response is
+	// an error.
+	Set(config *DeviceConfig, pdus []gosnmp.SnmpPDU) error
+}
+
+// transport is the process wide Transport. Nil means UDPTransport.
+var transport = struct {
+	sync.RWMutex
+	transport Transport
+}{}
+
+// SetTransport sets the process wide Transport used by all SnmpClients. nil
+// restores the default UDPTransport.
+func SetTransport(t Transport) {
+	transport.Lock()
+	defer transport.Unlock()
+	transport.transport = t
+}
+
+// getTransport gets the process wide Transport.
+func getTransport() Transport {
+	transport.RLock()
+	defer transport.RUnlock()
+	if transport.transport == nil {
+		return UDPTransport{}
+	}
+	return transport.transport
+}
+
+// UDPTransport is the Transport to SNMP agents on the network. It opens a
+// connection per operation.
+type UDPTransport struct{}
+
+// Get performs an SNMP get with gosnmp.
+func (UDPTransport) Get(config *DeviceConfig, oids []string) ([]gosnmp.SnmpPDU, error) {
+	goSnmp, err := createGoSNMP(config)
+	if err != nil {
+		return nil, err
+	}
+
+	snmpPacket, err := goSnmp.Get(oids)
+	err2 := goSnmp.Conn.Close() // Do not leak connection.
+
+	// Return first error.
+	if err != nil {
+		return nil, err
+	}
+	if err2 != nil {
+		return nil, err2
+	}
+	return snmpPacket.Variables, nil
+}
+
+// Walk performs an SNMP bulk walk with gosnmp.
+func (UDPTransport) Walk(config *DeviceConfig, rootOid string) ([]gosnmp.SnmpPDU, error) {
+	goSnmp, err := createGoSNMP(config)
+	if err != nil {
+		return nil, err
+	}
+
+	resultSet, err := goSnmp.BulkWalkAll(rootOid)
+	err2 := goSnmp.Conn.Close() // Do not leak connection.
+
+	// Return first error.
+	if err != nil {
+		return nil, err
+	}
+	if err2 != nil {
+		return nil, err2
+	}
+	return resultSet, nil
+}
+
+// Set performs an SNMP set with gosnmp.
+func (UDPTransport) Set(config *DeviceConfig, pdus []gosnmp.SnmpPDU) error {
+	goSnmp, err := createGoSNMP(config)
+	if err != nil {
+		return err
+	}
+
+	snmpPacket, err := goSnmp.Set(pdus)
+	err2 := goSnmp.Conn.Close() // Do not leak connection.
+
+	// Return first error.
+	if err != nil {
+		return err
+	}
+	if err2 != nil {
+		return err2
+	}
+	if snmpPacket.Error != gosnmp.NoError {
+		return fmt.Errorf("%v on varbind %d", snmpPacket.Error, snmpPacket.ErrorIndex)
+	}
+	return nil
+}
+
+// createGoSNMP is a helper to create gosnmp.GoSNMP from a DeviceConfig.
+// On success, the connection is open.
+func createGoSNMP(config *DeviceConfig) (*gosnmp.GoSNMP, error) {
+
+	// Argument checks
+	if config == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
+	if config.SecurityParameters == nil {
+		return nil, fmt.Errorf("No security parameters")
+	}
+
+	// Map DeviceConfig parameters to gosnmp parameters.
+	securityParameters := config.SecurityParameters
+	var authProtocol gosnmp.SnmpV3AuthProtocol
+	var privProtocol gosnmp.SnmpV3PrivProtocol
+
+	if securityParameters.AuthenticationProtocol == MD5 {
+		authProtocol = gosnmp.MD5
+	} else if securityParameters.AuthenticationProtocol == SHA {
+		authProtocol = gosnmp.SHA
+	} else {
+		return nil, fmt.Errorf("Unsupported authentication protocol [%v]", securityParameters.AuthenticationProtocol)
+	}
+
+	if securityParameters.PrivacyProtocol == DES {
+		privProtocol = gosnmp.DES
+	} else if securityParameters.PrivacyProtocol == AES {
+		privProtocol = gosnmp.AES
+	} else {
+		return nil, fmt.Errorf("Unsupported privacy protocol [%v]", securityParameters.PrivacyProtocol)
+	}
+
+	goSnmp := &gosnmp.GoSNMP{
+		Target:        config.Endpoint,
+		Port:          config.Port,
+		Version:       gosnmp.Version3,
+		Timeout:       config.Timeout,
+		SecurityModel: gosnmp.UserSecurityModel,
+		MsgFlags:      gosnmp.AuthPriv,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			UserName:                 securityParameters.UserName,
+			AuthenticationProtocol:   authProtocol,
+			AuthenticationPassphrase: securityParameters.AuthenticationPassphrase,
+			PrivacyProtocol:          privProtocol,
+			PrivacyPassphrase:        securityParameters.PrivacyPassphrase,
+		},
+		ContextName: config.ContextName,
+	}
+
+	// Connect
+	err := goSnmp.Connect()
+	if err != nil {
+		log.Error("gosnmp failed to connect")
+		return nil, fmt.Errorf("Failed to connect gosnmp: %+v", err)
+	}
+	return goSnmp, err
+}
@@ -20,7 +20,7 @@ func CopyMapStringInterface(m map[string]interface{}) map[string]interface{} {
 	return target
 }
 
-// DumpDeviceConfigs to the log.
+// DumpDeviceConfigs to the log. Secrets in device data are redacted.
 func DumpDeviceConfigs(deviceConfigs []*sdk.DeviceConfig) {
 	if deviceConfigs == nil {
 		logger.Infof("No Device Configs to dump\n")
@@ -35,8 +35,14 @@ func DumpDeviceConfigs(deviceConfigs []*sdk.DeviceConfig) {
 			logger.Infof("deviceConfig[%d].Devices[%d]: %T: %+v", i, j,
 				devices[j], devices[j])
 			for k := 0; k < len(devices[j].Instances); k++ {
+				instance := *devices[j].Instances[k]
+				instance.Data = RedactData(instance.Data)
 				logger.Infof("deviceConfig[%d].Devices[%d].Instances[%d]: %T: %+v", i, j, k,
-					devices[j].Instances[k], devices[j].Instances[k])
+					devices[j].Instances[k], instance)
+				if oid, ok := devices[j].Instances[k].Data["oid"]; ok {
+					logger.Infof("deviceConfig[%d].Devices[%d].Instances[%d]: oid: %v", i, j, k,
+						OidDisplay(fmt.Sprint(oid)))
+				}
 			}
 			for l := 0; l < len(devices[j].Outputs); l++ {
 				logger.Infof("deviceConfig[%d].Devices[%d].Outputs[%d]: %T: %+v", i, j, l,
@@ -83,7 +89,7 @@ func MergeMapStringInterface(a map[string]interface{}, b map[string]interface{})
 	for k, v := range b {
 		_, inMap := merged[k]
 		if inMap {
-			return nil, fmt.Errorf("Key %v already in merged map: %v", k, merged)
+			return nil, fmt.Errorf("Key %v already in merged map: %v", k, RedactData(merged))
 		}
 		merged[k] = v
 	}
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/soniah/gosnmp"
+)
+
+// This file contains the typed accessors for SNMP values. gosnmp decodes each
+// ASN.1 type to a different go type (see decodeValue() in gosnmp helper.go):
+//
+//   Integer                     int
+//   Counter32, Gauge32          uint
+//   TimeTicks                   uint32 (uint on older gosnmp)
+//   Counter64                   uint64
+//   OctetString, Opaque         []byte (string here when printable)
+//   IPAddress, ObjectIdentifier string
+//   OpaqueFloat, OpaqueDouble   float32, float64
+//   Null, NoSuchObject,
+//   NoSuchInstance, EndOfMibView nil
+//
+// The accessors below hide those differences from the device handlers.
+
+// NewReadResult creates a ReadResult from a gosnmp PDU.
+func NewReadResult(pdu gosnmp.SnmpPDU) ReadResult {
+	value := pdu.Value
+
+	// If it looks like a string, try to translate it.
+	if pdu.Type == gosnmp.OctetString {
+		text, err := TranslatePrintableText(value)
+		if err == nil {
+			value = text
+		}
+		// err above is deliberately ignored here. SNMP does not differentiate
+		// between strings and byte array. The raw bytes are still available
+		// from Bytes() for textual convention formatting.
+	}
+
+	return ReadResult{
+		Oid:  pdu.Name,
+		Type: pdu.Type,
+		Data: value,
+	}
+}
+
+// TypeName returns the name of the ASN.1 type of the result.
+func (result ReadResult) TypeName() string {
+	switch result.Type {
+	case gosnmp.Integer:
+		return "Integer"
+	case gosnmp.OctetString:
+		return "OctetString"
+	case gosnmp.Null:
+		return "Null"
+	case gosnmp.ObjectIdentifier:
+		return "ObjectIdentifier"
+	case gosnmp.IPAddress:
+		return "IpAddress"
+	case gosnmp.Counter32:
+		return "Counter32"
+	case gosnmp.Gauge32:
+		return "Gauge32"
+	case gosnmp.TimeTicks:
+		return "TimeTicks"
+	case gosnmp.Opaque:
+		return "Opaque"
+	case gosnmp.Counter64:
+		return "Counter64"
+	case gosnmp.Uinteger32:
+		return "Unsigned32"
+	case gosnmp.OpaqueFloat:
+		return "OpaqueFloat"
+	case gosnmp.OpaqueDouble:
+		return "OpaqueDouble"
+	case gosnmp.NoSuchObject:
+		return "NoSuchObject"
+	case gosnmp.NoSuchInstance:
+		return "NoSuchInstance"
+	case gosnmp.EndOfMibView:
+		return "EndOfMibView"
+	}
+	return fmt.Sprintf("Unknown(0x%02x)", uint8(result.Type))
+}
+
+// IsNull returns true if the agent returned no value for the OID. This
+// includes NoSuchObject, NoSuchInstance and EndOfMibView.
+func (result ReadResult) IsNull() bool {
+	switch result.Type {
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+		return true
+	}
+	return result.Data == nil
+}
+
+// IsNumeric returns true if the value can be read with Float64.
+func (result ReadResult) IsNumeric() bool {
+	_, err := result.Float64()
+	return err == nil
+}
+
+// IsCounter returns true if the value is a Counter32 or Counter64.
+func (result ReadResult) IsCounter() bool {
+	return result.Type == gosnmp.Counter32 || result.Type == gosnmp.Counter64
+}
+
+// Int64 returns any integer value as an int64.
+func (result ReadResult) Int64() (int64, error) {
+	switch v := result.Data.(type) {
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("%v value %d overflows int64", result.TypeName(), v)
+		}
+		return int64(v), nil
+	}
+	return 0, result.typeError("integer")
+}
+
+// Uint64 returns any non-negative integer value as a uint64.
+func (result ReadResult) Uint64() (uint64, error) {
+	if v, ok := result.Data.(uint64); ok {
+		return v, nil
+	}
+	v, err := result.Int64()
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("%v value %d is negative", result.TypeName(), v)
+	}
+	return uint64(v), nil
+}
+
+// Float64 returns any numeric value as a float64, including the NET-SNMP
+// Opaque encodings of float and double.
+func (result ReadResult) Float64() (float64, error) {
+	switch v := result.Data.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case []byte:
+		if result.Type == gosnmp.Opaque {
+			return decodeOpaqueFloat(v)
+		}
+	case uint64:
+		return float64(v), nil
+	}
+	v, err := result.Int64()
+	if err != nil {
+		return 0, result.typeError("numeric")
+	}
+	return float64(v), nil
+}
+
+// Text returns an OctetString, IpAddress or ObjectIdentifier value as a string.
+func (result ReadResult) Text() (string, error) {
+	switch v := result.Data.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		if result.Type == gosnmp.OctetString || result.Type == gosnmp.Opaque {
+			return string(v), nil
+		}
+	}
+	return "", result.typeError("string")
+}
+
+// OID returns an ObjectIdentifier value with a leading period.
+func (result ReadResult) OID() (string, error) {
+	v, ok := result.Data.(string)
+	if !ok || result.Type != gosnmp.ObjectIdentifier {
+		return "", result.typeError("OID")
+	}
+	if len(v) > 0 && v[0] != '.' {
+		v = "." + v
+	}
+	return v, nil
+}
+
+// Duration returns a TimeTicks value, which is in hundredths of a second, as a
+// time.Duration.
+func (result ReadResult) Duration() (time.Duration, error) {
+	if result.Type != gosnmp.TimeTicks {
+		return 0, result.typeError("TimeTicks")
+	}
+	ticks, err := result.Uint64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ticks) * 10 * time.Millisecond, nil
+}
+
+// Format returns the value as a string suitable for display regardless of
+// type. Null values are the empty string.
+func (result ReadResult) Format() string {
+	if result.IsNull() {
+		return ""
+	}
+	switch v := result.Data.(type) {
+	case string:
+		return v
+	case []byte:
+		if f, err := result.Float64(); err == nil {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		return fmt.Sprintf("%x", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(result.Data)
+}
+
+// typeError creates the error for a value that can not be read as the
+// requested kind.
+func (result ReadResult) typeError(expected string) error {
+	return fmt.Errorf(
+		"Expected %v reading for %v, got %v, type: %T, value: %v",
+		expected, result.Oid, result.TypeName(), result.Data, result.Data)
+}
+
+// decodeOpaqueFloat decodes the NET-SNMP float and double encodings inside an
+// Opaque, used by older versions of gosnmp that do not decode them.
+// The encoding is an ASN.1 extension tag 0x9f followed by 0x78 (float) or 0x79
+// (double), a length, and the IEEE 754 big endian value.
+func decodeOpaqueFloat(data []byte) (float64, error) {
+	if len(data) < 3 || data[0] != 0x9f {
+		return 0, fmt.Errorf("Opaque value %x is not a float", data)
+	}
+	length := int(data[2])
+	if len(data) < 3+length {
+		return 0, fmt.Errorf("Opaque value %x is truncated", data)
+	}
+	value := data[3 : 3+length]
+
+	switch {
+	case data[1] == 0x78 && length == 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(value))), nil
+	case data[1] == 0x79 && length == 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(value)), nil
+	}
+	return 0, fmt.Errorf("Opaque value %x is not a float", data)
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/soniah/gosnmp"
+)
+
+// TestReadResultNumeric checks the numeric accessors across the ASN.1 types.
+func TestReadResultNumeric(t *testing.T) {
+	cases := []struct {
+		pdu      gosnmp.SnmpPDU
+		expected float64
+	}{
+		{gosnmp.SnmpPDU{Name: ".1", Type: gosnmp.Integer, Value: -42}, -42},
+		{gosnmp.SnmpPDU{Name: ".2", Type: gosnmp.Gauge32, Value: uint(4294967295)}, 4294967295},
+		{gosnmp.SnmpPDU{Name: ".3", Type: gosnmp.Counter32, Value: uint(17)}, 17},
+		{gosnmp.SnmpPDU{Name: ".4", Type: gosnmp.Counter64, Value: uint64(1) << 40}, 1 << 40},
+		{gosnmp.SnmpPDU{Name: ".5", Type: gosnmp.TimeTicks, Value: uint32(6930266)}, 6930266},
+		{gosnmp.SnmpPDU{Name: ".6", Type: gosnmp.OpaqueFloat, Value: float32(1.5)}, 1.5},
+		{gosnmp.SnmpPDU{Name: ".7", Type: gosnmp.OpaqueDouble, Value: float64(-0.25)}, -0.25},
+		// NET-SNMP Opaque float 2.5 as sent to older gosnmp.
+		{gosnmp.SnmpPDU{Name: ".8", Type: gosnmp.Opaque, Value: []byte{0x9f, 0x78, 0x04, 0x40, 0x20, 0x00, 0x00}}, 2.5},
+	}
+
+	for _, c := range cases {
+		result := NewReadResult(c.pdu)
+		if !result.IsNumeric() {
+			t.Fatalf("%v: expected numeric %v", c.pdu.Name, result.TypeName())
+		}
+		actual, err := result.Float64()
+		if err != nil {
+			t.Fatalf("%v: %v", c.pdu.Name, err)
+		}
+		if actual != c.expected {
+			t.Fatalf("%v: expected %v, got %v", c.pdu.Name, c.expected, actual)
+		}
+	}
+
+	// Negative integers are not unsigned.
+	result := NewReadResult(gosnmp.SnmpPDU{Name: ".1", Type: gosnmp.Integer, Value: -1})
+	if _, err := result.Uint64(); err == nil {
+		t.Fatal("Expected error reading a negative Integer as Uint64")
+	}
+
+	// Strings are not numbers.
+	result = NewReadResult(gosnmp.SnmpPDU{Name: ".1", Type: gosnmp.OctetString, Value: []byte("abc")})
+	if _, err := result.Float64(); err == nil {
+		t.Fatal("Expected error reading an OctetString as Float64")
+	}
+}
+
+// TestReadResultText checks the string, OID and duration accessors.
+func TestReadResultText(t *testing.T) {
+	result := NewReadResult(gosnmp.SnmpPDU{
+		Name: ".1.3.6.1.2.1.33.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("Eaton Corporation")})
+	text, err := result.Text()
+	if err != nil || text != "Eaton Corporation" {
+		t.Fatalf("Expected [Eaton Corporation], got [%v], err %v", text, err)
+	}
+
+	result = NewReadResult(gosnmp.SnmpPDU{
+		Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: "1.3.6.1.4.1.534.2.12"})
+	oid, err := result.OID()
+	if err != nil || oid != ".1.3.6.1.4.1.534.2.12" {
+		t.Fatalf("Expected [.1.3.6.1.4.1.534.2.12], got [%v], err %v", oid, err)
+	}
+
+	result = NewReadResult(gosnmp.SnmpPDU{
+		Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(6930266)})
+	duration, err := result.Duration()
+	if err != nil || duration != 69302660*time.Millisecond {
+		t.Fatalf("Expected 19h15m2.66s, got %v, err %v", duration, err)
+	}
+
+	result = NewReadResult(gosnmp.SnmpPDU{
+		Name: ".1.3.6.1.2.1.33.1.2.1.0", Type: gosnmp.NoSuchInstance, Value: nil})
+	if !result.IsNull() || result.Format() != "" {
+		t.Fatalf("Expected null result, got %+v", result)
+	}
+
+	result = NewReadResult(gosnmp.SnmpPDU{
+		Name: ".1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"})
+	if result.Format() != "10.0.0.1" {
+		t.Fatalf("Expected [10.0.0.1], got [%v]", result.Format())
+	}
+}
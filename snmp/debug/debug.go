@@ -0,0 +1,263 @@
+// Package debug serves the state of a running plugin over HTTP for support:
+// the registered agents, the MIB tables they were enumerated with and their
+// cached rows, and the enumerated devices.
+//
+//	/debug/agents                             Agents, their health, MIBs and tables.
+//	/debug/table?agent=ID&mib=NAME&table=NAME Cached rows of a table. format=csv for CSV.
+//	/debug/devices?agent=ID                   Enumerated devices of an agent.
+//
+// Responses are JSON unless noted. Connection settings and credentials are
+// never served.
+package debug
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// AgentSummary is an agent in /debug/agents.
+type AgentSummary struct {
+	ID         string           `json:"id"`
+	Endpoint   string           `json:"endpoint"`
+	Health     core.AgentHealth `json:"health"`
+	Healthy    bool             `json:"healthy"`
+	Enumerated *time.Time       `json:"enumerated,omitempty"` // Time of the last enumeration.
+	Devices    int              `json:"devices"`              // Device instances enumerated.
+	Mibs       []MibSummary     `json:"mibs"`
+}
+
+// MibSummary is a MIB of an agent in /debug/agents.
+type MibSummary struct {
+	Name   string         `json:"name"`
+	Tables []TableSummary `json:"tables"`
+}
+
+// TableSummary is a MIB table in /debug/agents.
+type TableSummary struct {
+	Name    string     `json:"name"`
+	WalkOid string     `json:"walkOid"`
+	Columns []string   `json:"columns"`
+	Rows    int        `json:"rows"`
+	Loaded  *time.Time `json:"loaded,omitempty"` // Time of the last refresh.
+}
+
+// Table is the cached rows of a table in /debug/table. Each row has its base
+// OID under "baseOid" and a formatted value per column.
+type Table struct {
+	TableSummary
+	Agent string              `json:"agent"`
+	Mib   string              `json:"mib"`
+	Data  []map[string]string `json:"data"`
+}
+
+// Device is an enumerated device instance in /debug/devices.
+type Device struct {
+	Kind     string                 `json:"kind"`
+	Info     string                 `json:"info"`
+	Location string                 `json:"location"`
+	Oid      string                 `json:"oid,omitempty"` // Symbolic if the MIB is loaded.
+	Data     map[string]interface{} `json:"data"`
+}
+
+// Devices is the enumerated devices of an agent in /debug/devices.
+type Devices struct {
+	Agent      string     `json:"agent"`
+	Enumerated *time.Time `json:"enumerated,omitempty"`
+	Devices    []Device   `json:"devices"`
+}
+
+// Handler returns an http.Handler that serves the debug endpoints.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/agents", serveAgents)
+	mux.HandleFunc("/debug/table", serveTable)
+	mux.HandleFunc("/debug/devices", serveDevices)
+	return mux
+}
+
+// Agents summarizes the registered agents.
+func Agents() []AgentSummary {
+	summaries := []AgentSummary{}
+	for _, agent := range core.Agents() {
+		health := agent.Health()
+		deviceConfigs, enumerated := agent.Devices()
+		summary := AgentSummary{
+			ID:         agent.ID,
+			Endpoint:   fmt.Sprintf("%v:%v", agent.DeviceConfig.Endpoint, agent.DeviceConfig.Port),
+			Health:     health,
+			Healthy:    health.Healthy(),
+			Enumerated: timeOrNil(enumerated),
+			Devices:    len(devices(deviceConfigs)),
+			Mibs:       []MibSummary{},
+		}
+		for _, mib := range agent.Mibs() {
+			mibSummary := MibSummary{Name: mib.Name, Tables: []TableSummary{}}
+			for _, table := range mib.Tables {
+				mibSummary.Tables = append(mibSummary.Tables, tableSummary(table))
+			}
+			summary.Mibs = append(summary.Mibs, mibSummary)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// LookupTable returns the cached rows of a table of an agent.
+func LookupTable(agentID string, mibName string, tableName string) (*Table, error) {
+	agent := core.LookupAgent(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("no agent %v", agentID)
+	}
+	for _, mib := range agent.Mibs() {
+		if mib.Name != mibName {
+			continue
+		}
+		for _, snmpTable := range mib.Tables {
+			if snmpTable.Name != tableName {
+				continue
+			}
+			table := &Table{
+				TableSummary: tableSummary(snmpTable),
+				Agent:        agent.ID,
+				Mib:          mib.Name,
+				Data:         []map[string]string{},
+			}
+			for _, row := range snmpTable.Rows {
+				values := map[string]string{"baseOid": core.OidDisplay(row.BaseOid)}
+				for i, column := range snmpTable.ColumnList {
+					values[column] = cellText(row.RowData, i)
+				}
+				table.Data = append(table.Data, values)
+			}
+			return table, nil
+		}
+		return nil, fmt.Errorf("no table %v in MIB %v of agent %v", tableName, mibName, agentID)
+	}
+	return nil, fmt.Errorf("no MIB %v for agent %v", mibName, agentID)
+}
+
+// LookupDevices returns the enumerated devices of an agent.
+func LookupDevices(agentID string) (*Devices, error) {
+	agent := core.LookupAgent(agentID)
+	if agent == nil {
+		return nil, fmt.Errorf("no agent %v", agentID)
+	}
+	deviceConfigs, enumerated := agent.Devices()
+	return &Devices{
+		Agent:      agent.ID,
+		Enumerated: timeOrNil(enumerated),
+		Devices:    devices(deviceConfigs),
+	}, nil
+}
+
+func serveAgents(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, Agents())
+}
+
+func serveTable(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	table, err := LookupTable(query.Get("agent"), query.Get("mib"), query.Get("table"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if query.Get("format") != "csv" {
+		writeJSON(w, table)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	writer := csv.NewWriter(w)
+	writer.Write(append([]string{"baseOid"}, table.Columns...)) // nolint: errcheck
+	for _, values := range table.Data {
+		record := []string{values["baseOid"]}
+		for _, column := range table.Columns {
+			record = append(record, values[column])
+		}
+		writer.Write(record) // nolint: errcheck
+	}
+	writer.Flush()
+}
+
+func serveDevices(w http.ResponseWriter, r *http.Request) {
+	enumerated, err := LookupDevices(r.URL.Query().Get("agent"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, enumerated)
+}
+
+// writeJSON writes a response as indented JSON.
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	body, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n')) // nolint: errcheck
+}
+
+// tableSummary summarizes a MIB table.
+func tableSummary(table *core.SnmpTable) TableSummary {
+	return TableSummary{
+		Name:    table.Name,
+		WalkOid: core.OidDisplay(table.WalkOid),
+		Columns: table.ColumnList,
+		Rows:    len(table.Rows),
+		Loaded:  timeOrNil(table.Loaded),
+	}
+}
+
+// cellText formats a cell of a row. Cells the agent does not have are empty.
+func cellText(rowData []*core.ReadResult, i int) string {
+	if i >= len(rowData) || rowData[i] == nil || rowData[i].IsNull() {
+		return ""
+	}
+	return rowData[i].Format()
+}
+
+// devices flattens device configs into their device instances, without the
+// connection settings and credentials in their data.
+func devices(deviceConfigs []*sdk.DeviceConfig) []Device {
+	list := []Device{}
+	for _, cfg := range deviceConfigs {
+		for _, kind := range cfg.Devices {
+			for _, instance := range kind.Instances {
+				data := map[string]interface{}{}
+				for key, value := range instance.Data {
+					data[key] = value
+				}
+				for _, key := range core.AgentKeys {
+					delete(data, key)
+				}
+				device := Device{
+					Kind:     kind.Name,
+					Info:     instance.Info,
+					Location: instance.Location,
+					Data:     data,
+				}
+				if oid, ok := instance.Data["oid"]; ok {
+					device.Oid = core.OidDisplay(fmt.Sprint(oid))
+				}
+				list = append(list, device)
+			}
+		}
+	}
+	return list
+}
+
+// timeOrNil returns nil for the zero time so that it is left out of JSON.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
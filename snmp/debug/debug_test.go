@@ -0,0 +1,140 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/mibs/ups_mib"
+)
+
+// TestMain runs the tests against the emulator. See emulator.Main.
+func TestMain(m *testing.M) {
+	emulator.Main(m, func(dir string) error {
+		_, err := core.UseReplayTransport(dir)
+		return err
+	})
+}
+
+// debugTestAgent registers the emulator agent with the UPS-MIB and a device.
+func debugTestAgent(t *testing.T) *core.Agent {
+	data := emulator.AgentData()
+	data["agent"] = "debug-test"
+	agent, err := core.RegisterAgent(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := core.NewSnmpServerBase(agent.Client, agent.DeviceConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upsMib, err := mibs.NewUpsMib(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent.AddMib(upsMib.SnmpMib)
+	agent.SetDevices([]*sdk.DeviceConfig{{
+		Devices: []*sdk.DeviceKind{{
+			Name: "voltage",
+			Instances: []*sdk.DeviceInstance{{
+				Info:     "upsOutputVoltage0",
+				Location: "snmp-location",
+				Data: map[string]interface{}{
+					core.AgentKey:              "debug-test",
+					"oid":                      ".1.3.6.1.2.1.33.1.4.4.1.2.1",
+					"privacyPassphrase":        emulator.PrivacyPassphrase,
+					"authenticationPassphrase": emulator.AuthenticationPassphrase,
+				},
+			}},
+		}},
+	}})
+	return agent
+}
+
+// get serves a request and returns the response body.
+func get(t *testing.T, url string, status int) string {
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+	if recorder.Code != status {
+		t.Fatalf("Expected status %v for %v, got %v: %v", status, url, recorder.Code, recorder.Body.String())
+	}
+	return recorder.Body.String()
+}
+
+// TestAgents lists the agent with its MIB and tables.
+func TestAgents(t *testing.T) {
+	debugTestAgent(t)
+
+	var agents []AgentSummary
+	if err := json.Unmarshal([]byte(get(t, "/debug/agents", 200)), &agents); err != nil {
+		t.Fatal(err)
+	}
+	var agent *AgentSummary
+	for i := range agents {
+		if agents[i].ID == "debug-test" {
+			agent = &agents[i]
+		}
+	}
+	if agent == nil {
+		t.Fatalf("Expected agent debug-test, got %+v", agents)
+	}
+	if agent.Endpoint != "127.0.0.1:1024" || agent.Devices != 1 || agent.Enumerated == nil {
+		t.Fatalf("Expected an enumerated agent with one device, got %+v", agent)
+	}
+	if len(agent.Mibs) != 1 || agent.Mibs[0].Name != "UPS-MIB" || len(agent.Mibs[0].Tables) == 0 {
+		t.Fatalf("Expected the UPS-MIB, got %+v", agent.Mibs)
+	}
+	for _, table := range agent.Mibs[0].Tables {
+		if table.Loaded == nil {
+			t.Fatalf("Expected table %v to be loaded", table.Name)
+		}
+	}
+}
+
+// TestTable shows the cached rows of a table as JSON and CSV.
+func TestTable(t *testing.T) {
+	debugTestAgent(t)
+	url := "/debug/table?agent=debug-test&mib=UPS-MIB&table=UPS-MIB-UPS-Output-Table"
+
+	var table Table
+	if err := json.Unmarshal([]byte(get(t, url, 200)), &table); err != nil {
+		t.Fatal(err)
+	}
+	if table.Rows != 3 || len(table.Data) != 3 {
+		t.Fatalf("Expected 3 output lines, got %+v", table)
+	}
+	if table.Data[0]["upsOutputVoltage"] == "" {
+		t.Fatalf("Expected an output voltage, got %+v", table.Data[0])
+	}
+
+	lines := strings.Split(strings.TrimSpace(get(t, url+"&format=csv", 200)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "baseOid,upsOutputLineIndex,upsOutputVoltage,") {
+		t.Fatalf("Expected a header and 3 rows, got %v", lines)
+	}
+
+	get(t, "/debug/table?agent=debug-test&mib=UPS-MIB&table=none", 404)
+	get(t, "/debug/table?agent=none&mib=UPS-MIB&table=UPS-MIB-UPS-Output-Table", 404)
+}
+
+// TestDevices shows the enumerated devices without credentials.
+func TestDevices(t *testing.T) {
+	debugTestAgent(t)
+
+	body := get(t, "/debug/devices?agent=debug-test", 200)
+	var devices Devices
+	if err := json.Unmarshal([]byte(body), &devices); err != nil {
+		t.Fatal(err)
+	}
+	if len(devices.Devices) != 1 || devices.Devices[0].Info != "upsOutputVoltage0" || devices.Devices[0].Oid == "" {
+		t.Fatalf("Expected the voltage device, got %+v", devices)
+	}
+	if strings.Contains(body, emulator.PrivacyPassphrase) || strings.Contains(body, emulator.AuthenticationPassphrase) {
+		t.Fatalf("Expected no credentials, got %v", body)
+	}
+
+	get(t, "/debug/devices?agent=none", 404)
+}
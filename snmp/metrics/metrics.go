@@ -0,0 +1,300 @@
+// Package metrics keeps the plugin's own metrics, such as SNMP request counts
+// and latencies per agent, and writes them in the Prometheus text exposition
+// format for a /metrics endpoint.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds of the histogram buckets for request
+// durations in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// family is a metric with its help, type and series.
+type family interface {
+	write(w *bufio.Writer)
+}
+
+// Registry is a set of metrics.
+type Registry struct {
+	mutex    sync.Mutex
+	names    map[string]bool
+	families []family
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+// Default is the registry of the plugin's metrics.
+var Default = NewRegistry()
+
+// register adds a metric to the registry. Names must be unique.
+func (registry *Registry) register(name string, f family) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if registry.names[name] {
+		panic(fmt.Sprintf("metric %v is already registered", name))
+	}
+	registry.names[name] = true
+	registry.families = append(registry.families, f)
+}
+
+// Write writes the metrics in the Prometheus text format.
+func (registry *Registry) Write(w io.Writer) error {
+	registry.mutex.Lock()
+	families := append([]family{}, registry.families...)
+	registry.mutex.Unlock()
+
+	buffered := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(buffered)
+	}
+	return buffered.Flush()
+}
+
+// Handler returns an http.Handler that serves the metrics of the registry.
+func (registry *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := registry.Write(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// Counter is a counter with labels.
+type Counter struct {
+	name   string
+	help   string
+	labels []string
+
+	mutex  sync.Mutex
+	series map[string]*counterSeries // Keyed by label values.
+}
+
+// counterSeries is the value of a counter for one set of label values.
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+// NewCounter creates a counter in the registry.
+func (registry *Registry) NewCounter(name string, help string, labels ...string) *Counter {
+	counter := &Counter{name: name, help: help, labels: labels, series: map[string]*counterSeries{}}
+	registry.register(name, counter)
+	return counter
+}
+
+// Inc adds one to the counter with the label values.
+func (counter *Counter) Inc(labelValues ...string) {
+	counter.Add(1, labelValues...)
+}
+
+// Add adds a value to the counter with the label values. The value should
+// not be negative.
+func (counter *Counter) Add(value float64, labelValues ...string) {
+	checkLabelValues(counter.name, counter.labels, labelValues)
+	key := strings.Join(labelValues, "\xff")
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	series, ok := counter.series[key]
+	if !ok {
+		series = &counterSeries{labelValues: labelValues}
+		counter.series[key] = series
+	}
+	series.value += value
+}
+
+func (counter *Counter) write(w *bufio.Writer) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	writeHeader(w, counter.name, counter.help, "counter")
+	for _, key := range sortedKeys(counter.series) {
+		series := counter.series[key]
+		writeSample(w, counter.name, counter.labels, series.labelValues, "", "", series.value)
+	}
+}
+
+// Histogram is a histogram with labels.
+type Histogram struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mutex  sync.Mutex
+	series map[string]*histogramSeries // Keyed by label values.
+}
+
+// histogramSeries is the distribution of a histogram for one set of label
+// values.
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // Per bucket, not cumulative.
+	count       uint64
+	sum         float64
+}
+
+// NewHistogram creates a histogram in the registry with the upper bounds of
+// its buckets in increasing order.
+func (registry *Registry) NewHistogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	histogram := &Histogram{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		series:  map[string]*histogramSeries{},
+	}
+	registry.register(name, histogram)
+	return histogram
+}
+
+// Observe adds a value to the histogram with the label values.
+func (histogram *Histogram) Observe(value float64, labelValues ...string) {
+	checkLabelValues(histogram.name, histogram.labels, labelValues)
+	key := strings.Join(labelValues, "\xff")
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+	series, ok := histogram.series[key]
+	if !ok {
+		series = &histogramSeries{labelValues: labelValues, counts: make([]uint64, len(histogram.buckets))}
+		histogram.series[key] = series
+	}
+	for i, bound := range histogram.buckets {
+		if value <= bound {
+			series.counts[i]++
+			break
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+func (histogram *Histogram) write(w *bufio.Writer) {
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+	writeHeader(w, histogram.name, histogram.help, "histogram")
+	for _, key := range sortedKeys(histogram.series) {
+		series := histogram.series[key]
+		var cumulative uint64
+		for i, bound := range histogram.buckets {
+			cumulative += series.counts[i]
+			writeSample(w, histogram.name+"_bucket", histogram.labels, series.labelValues,
+				"le", formatFloat(bound), float64(cumulative))
+		}
+		writeSample(w, histogram.name+"_bucket", histogram.labels, series.labelValues,
+			"le", "+Inf", float64(series.count))
+		writeSample(w, histogram.name+"_sum", histogram.labels, series.labelValues, "", "", series.sum)
+		writeSample(w, histogram.name+"_count", histogram.labels, series.labelValues, "", "", float64(series.count))
+	}
+}
+
+// Sample is a value of a gauge with its label values.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// GaugeFunc is a gauge whose samples are collected when the metrics are
+// written, such as the health of each agent.
+type GaugeFunc struct {
+	name    string
+	help    string
+	labels  []string
+	collect func() []Sample
+}
+
+// NewGaugeFunc creates a gauge in the registry that is collected by a
+// function.
+func (registry *Registry) NewGaugeFunc(name string, help string, labels []string, collect func() []Sample) *GaugeFunc {
+	gauge := &GaugeFunc{name: name, help: help, labels: labels, collect: collect}
+	registry.register(name, gauge)
+	return gauge
+}
+
+func (gauge *GaugeFunc) write(w *bufio.Writer) {
+	writeHeader(w, gauge.name, gauge.help, "gauge")
+	for _, sample := range gauge.collect() {
+		checkLabelValues(gauge.name, gauge.labels, sample.LabelValues)
+		writeSample(w, gauge.name, gauge.labels, sample.LabelValues, "", "", sample.Value)
+	}
+}
+
+// checkLabelValues panics if the number of label values is not the number of
+// labels of a metric. This is a programming error.
+func checkLabelValues(name string, labels []string, labelValues []string) {
+	if len(labels) != len(labelValues) {
+		panic(fmt.Sprintf("metric %v has labels %v, got values %v", name, labels, labelValues))
+	}
+}
+
+// sortedKeys returns the keys of series in order, for stable output.
+func sortedKeys(series interface{}) []string {
+	var keys []string
+	switch m := series.(type) {
+	case map[string]*counterSeries:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[string]*histogramSeries:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric.
+func writeHeader(w *bufio.Writer, name string, help string, typ string) {
+	fmt.Fprintf(w, "# HELP %v %v\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	fmt.Fprintf(w, "# TYPE %v %v\n", name, typ)
+}
+
+// writeSample writes a sample line with the labels, and an extra label such
+// as le if extraName is not empty.
+func writeSample(w *bufio.Writer, name string, labels []string, labelValues []string,
+	extraName string, extraValue string, value float64) {
+
+	var pairs []string
+	for i, label := range labels {
+		pairs = append(pairs, label+`="`+escapeLabelValue(labelValues[i])+`"`)
+	}
+	if extraName != "" {
+		pairs = append(pairs, extraName+`="`+extraValue+`"`)
+	}
+	if len(pairs) == 0 {
+		fmt.Fprintf(w, "%v %v\n", name, formatFloat(value))
+		return
+	}
+	fmt.Fprintf(w, "%v{%v} %v\n", name, strings.Join(pairs, ","), formatFloat(value))
+}
+
+// escapeLabelValue escapes a label value for the text format.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatFloat formats a sample value.
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
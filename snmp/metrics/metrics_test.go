@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRegistryWrite checks the text format of each kind of metric.
+func TestRegistryWrite(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounter("test_requests_total", "Requests.", "agent", "operation")
+	histogram := registry.NewHistogram("test_duration_seconds", "Duration.", []float64{.1, 1}, "agent")
+	registry.NewGaugeFunc("test_up", "Up.", []string{"agent"}, func() []Sample {
+		return []Sample{{LabelValues: []string{`a"b`}, Value: 1}}
+	})
+
+	counter.Inc("b", "get")
+	counter.Add(2, "a", "walk")
+	counter.Inc("a", "walk")
+	histogram.Observe(.05, "a")
+	histogram.Observe(.5, "a")
+	histogram.Observe(5, "a")
+
+	var buffer bytes.Buffer
+	if err := registry.Write(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{agent="a",operation="walk"} 3
+test_requests_total{agent="b",operation="get"} 1
+# HELP test_duration_seconds Duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{agent="a",le="0.1"} 1
+test_duration_seconds_bucket{agent="a",le="1"} 2
+test_duration_seconds_bucket{agent="a",le="+Inf"} 3
+test_duration_seconds_sum{agent="a"} 5.55
+test_duration_seconds_count{agent="a"} 3
+# HELP test_up Up.
+# TYPE test_up gauge
+test_up{agent="a\"b"} 1
+`
+	if buffer.String() != expected {
+		t.Fatalf("Expected:\n%v\nGot:\n%v", expected, buffer.String())
+	}
+}
+
+// TestRegistryHandler serves the metrics over HTTP.
+func TestRegistryHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("test_total", "Total.").Inc()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Code != 200 {
+		t.Fatalf("Expected status 200, got %v", recorder.Code)
+	}
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("Expected text/plain, got %v", recorder.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(recorder.Body.String(), "\ntest_total 1\n") {
+		t.Fatalf("Expected test_total 1, got %v", recorder.Body.String())
+	}
+}
+
+// TestRegistryDuplicate checks that a metric name can only be registered once.
+func TestRegistryDuplicate(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("test_total", "Total.")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected a panic for a duplicate metric")
+		}
+	}()
+	registry.NewCounter("test_total", "Total.")
+}
@@ -0,0 +1,87 @@
+package mibs
+
+import (
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// Names of the UPS-MIB enumerations in the core enumeration catalog. Synse
+// devices reference these in their data. Example:
+// "enumeration": EnumerationUpsBatteryStatus
+const (
+	EnumerationUpsBatteryStatus       = "UPS-MIB::upsBatteryStatus"
+	EnumerationUpsOutputSource        = "UPS-MIB::upsOutputSource"
+	EnumerationUpsTestResultsSummary  = "UPS-MIB::upsTestResultsSummary"
+	EnumerationUpsShutdownType        = "UPS-MIB::upsShutdownType"
+	EnumerationUpsAutoRestart         = "UPS-MIB::upsAutoRestart"
+	EnumerationUpsConfigAudibleStatus = "UPS-MIB::upsConfigAudibleStatus"
+)
+
+// upsMibEnumerations are the enumerated objects in UPS-MIB, rfc 1628. These
+// are registered so the enumerations work without the MIB files. A loaded
+// UPS-MIB registers the same enumerations.
+var upsMibEnumerations = []*core.Enumeration{
+	{
+		Name: EnumerationUpsBatteryStatus,
+		Labels: map[int64]string{
+			1: "unknown",
+			2: "batteryNormal",
+			3: "batteryLow",
+			4: "batteryDepleted",
+		},
+	},
+	{
+		Name: EnumerationUpsOutputSource,
+		Labels: map[int64]string{
+			1: "other",
+			2: "none",
+			3: "normal",
+			4: "bypass",
+			5: "battery",
+			6: "booster",
+			7: "reducer",
+		},
+	},
+	{
+		Name: EnumerationUpsTestResultsSummary,
+		Labels: map[int64]string{
+			1: "donePass",
+			2: "doneWarning",
+			3: "doneError",
+			4: "aborted",
+			5: "inProgress",
+			6: "noTestsInitiated",
+		},
+	},
+	{
+		Name: EnumerationUpsShutdownType,
+		Labels: map[int64]string{
+			1: "output",
+			2: "system",
+		},
+	},
+	{
+		Name: EnumerationUpsAutoRestart,
+		Labels: map[int64]string{
+			1: "on",
+			2: "off",
+		},
+	},
+	{
+		Name: EnumerationUpsConfigAudibleStatus,
+		Labels: map[int64]string{
+			1: "disabled",
+			2: "enabled",
+			3: "muted",
+		},
+	},
+}
+
+// registerEnumerations adds the UPS-MIB enumerations to the core catalog.
+// Enumerations already registered from a loaded MIB are kept.
+func registerEnumerations() {
+	for _, enumeration := range upsMibEnumerations {
+		if core.LookupEnumeration(enumeration.Name) == nil {
+			_ = core.RegisterEnumeration(enumeration) // Name is not empty.
+		}
+	}
+}
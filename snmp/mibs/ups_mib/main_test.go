@@ -0,0 +1,16 @@
+package mibs
+
+import (
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestMain runs the tests against the emulator. See emulator.Main.
+func TestMain(m *testing.M) {
+	emulator.Main(m, func(dir string) error {
+		_, err := core.UseReplayTransport(dir)
+		return err
+	})
+}
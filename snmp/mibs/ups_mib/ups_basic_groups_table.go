@@ -21,7 +21,7 @@ func NewUpsBasicGroupsTable(snmpServerBase *core.SnmpServerBase) (
 		[]string{ // Column Names
 			"upsBasicIdentGroup",
 			"upsBasicBatteryGroup",
-			"upsBasicInpuGroup",
+			"upsBasicInputGroup",
 			"upsBasicOutputGroup",
 			"upsBasicBypassGroup",
 			"upsBasicAlarmGroup",
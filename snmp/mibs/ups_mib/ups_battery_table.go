@@ -27,7 +27,7 @@ func NewUpsBatteryTable(snmpServerBase *core.SnmpServerBase) (
 			"upsEstimatedChargeRemaining", // Percentage
 			"upsBatteryVoltage",           // Units .1 VDC.
 			"upsBatteryCurrent",           // Units .1 Amp DC.
-			"upsBacontteryTemperature",    // Units degrees C.
+			"upsBatteryTemperature",       // Units degrees C.
 		},
 		snmpServerBase, // snmpServer
 		"",             // rowBase
@@ -65,7 +65,7 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 	mib := table.Mib.(*UpsMib)
 	model := mib.UpsIdentityTable.UpsIdentity.Model
 
-	snmpDeviceConfigMap, err := table.SnmpServerBase.DeviceConfig.ToMap()
+	agentReference, err := table.SnmpServerBase.DeviceConfig.AgentReference()
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +82,8 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		Devices: []*sdk.DeviceKind{},
 	}
 
-	// We will have "status", "voltage", "current", and "temperature" device kinds.
+	// We will have "status", "duration", "percentage", "voltage", "current", and
+	// "temperature" device kinds.
 	// There is probably a better way of doing this, but this just gets things to
 	// where they need to be for now.
 	statusKind := &sdk.DeviceKind{
@@ -96,6 +97,39 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		Instances: []*sdk.DeviceInstance{},
 	}
 
+	secondsKind := &sdk.DeviceKind{
+		Name: "duration",
+		Metadata: map[string]string{
+			"model": model,
+		},
+		Outputs: []*sdk.DeviceOutput{
+			{Type: "seconds.duration"},
+		},
+		Instances: []*sdk.DeviceInstance{},
+	}
+
+	minutesKind := &sdk.DeviceKind{
+		Name: "duration",
+		Metadata: map[string]string{
+			"model": model,
+		},
+		Outputs: []*sdk.DeviceOutput{
+			{Type: "minutes.duration"},
+		},
+		Instances: []*sdk.DeviceInstance{},
+	}
+
+	percentageKind := &sdk.DeviceKind{
+		Name: "percentage",
+		Metadata: map[string]string{
+			"model": model,
+		},
+		Outputs: []*sdk.DeviceOutput{
+			{Type: "percentage"},
+		},
+		Instances: []*sdk.DeviceInstance{},
+	}
+
 	voltageKind := &sdk.DeviceKind{
 		Name: "voltage",
 		Metadata: map[string]string{
@@ -131,6 +165,9 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 
 	cfg.Devices = []*sdk.DeviceKind{
 		statusKind,
+		secondsKind,
+		minutesKind,
+		percentageKind,
 		voltageKind,
 		currentKind,
 		temperatureKind,
@@ -148,14 +185,9 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		"column":     "1",
 		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 1), // base_oid and integer column.
 		// This is an enumeration. We need to translate the integer we read to a string.
-		// Enumeration data. For now we have map[string]string to work with so the
-		// key is fmt.Sprintf("enumeration%d", reading).
-		"enumeration1": "unknown",
-		"enumeration2": "batteryNormal",
-		"enumeration3": "batteryLow",
-		"enumeration4": "batteryDepleted",
-	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		"enumeration": EnumerationUpsBatteryStatus,
+	}
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +207,7 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		"column":     "2",
 		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 2), // base_oid and integer column.
 	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +217,7 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		Location: snmpLocation,
 		Data:     deviceData,
 	}
-	statusKind.Instances = append(statusKind.Instances, device)
+	secondsKind.Instances = append(secondsKind.Instances, device)
 
 	// upsEstimatedMinutesRemaining -----------------------------------------------
 	deviceData = map[string]interface{}{
@@ -195,7 +227,7 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		"column":     "3",
 		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 3), // base_oid and integer column.
 	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +237,7 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		Location: snmpLocation,
 		Data:     deviceData,
 	}
-	statusKind.Instances = append(statusKind.Instances, device)
+	minutesKind.Instances = append(minutesKind.Instances, device)
 
 	// upsEstimatedChargeRemaining ------------------------------------------------
 	deviceData = map[string]interface{}{
@@ -215,7 +247,7 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		"column":     "4",
 		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 4), // base_oid and integer column.
 	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -225,7 +257,7 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		Location: snmpLocation,
 		Data:     deviceData,
 	}
-	statusKind.Instances = append(statusKind.Instances, device)
+	percentageKind.Instances = append(percentageKind.Instances, device)
 
 	// upsBatteryVoltage ----------------------------------------------------------
 	deviceData = map[string]interface{}{
@@ -237,7 +269,7 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 5), // base_oid and integer column.
 		"multiplier": float32(0.1),                          // Units are 0.1 Volt DC.
 	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -258,7 +290,7 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 6), // base_oid and integer column.
 		"multiplier": float32(0.1),                          // Units are 0.1 Amp DC.
 	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -279,7 +311,7 @@ func (enumerator UpsBatteryTableDeviceEnumerator) DeviceEnumerator(
 		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 7), // base_oid and integer column.
 		// No multiplier needed. Units are degrees C.
 	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,9 @@
 package mibs
 
 import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
 	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
 )
 
@@ -31,5 +34,128 @@ func NewUpsBypassHeadersTable(snmpServerBase *core.SnmpServerBase) (
 	}
 
 	table = &UpsBypassHeadersTable{SnmpTable: snmpTable}
+	table.DevEnumerator = UpsBypassHeadersTableDeviceEnumerator{table}
 	return table, nil
 }
+
+// UpsBypassHeadersTableDeviceEnumerator overrides the default SnmpTable device
+// enumerator for the bypass headers table.
+type UpsBypassHeadersTableDeviceEnumerator struct {
+	Table *UpsBypassHeadersTable // Pointer back to the table.
+}
+
+// DeviceEnumerator overrides the default SnmpTable device enumerator. Columns
+// that the agent does not have are not enumerated.
+func (enumerator UpsBypassHeadersTableDeviceEnumerator) DeviceEnumerator(
+	data map[string]interface{}) (devices []*sdk.DeviceConfig, err error) {
+
+	// Get the rack and board ids. Setup the location.
+	rack, board, err := core.GetRackAndBoard(data)
+	if err != nil {
+		return nil, err
+	}
+
+	table := enumerator.Table
+	if len(table.Rows) == 0 {
+		return nil, nil
+	}
+	mib := table.Mib.(*UpsMib)
+	model := mib.UpsIdentityTable.UpsIdentity.Model
+
+	agentReference, err := table.SnmpServerBase.DeviceConfig.AgentReference()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &sdk.DeviceConfig{
+		SchemeVersion: sdk.SchemeVersion{Version: "1.0"},
+		Locations: []*sdk.LocationConfig{
+			{
+				Name:  snmpLocation,
+				Rack:  &sdk.LocationData{Name: rack},
+				Board: &sdk.LocationData{Name: board},
+			},
+		},
+		Devices: []*sdk.DeviceKind{},
+	}
+
+	// We will have "frequency" and "count" device kinds.
+	frequencyKind := &sdk.DeviceKind{
+		Name: "frequency",
+		Metadata: map[string]string{
+			"model": model,
+		},
+		Outputs: []*sdk.DeviceOutput{
+			{Type: "frequency"},
+		},
+		Instances: []*sdk.DeviceInstance{},
+	}
+
+	countKind := &sdk.DeviceKind{
+		Name: "count",
+		Metadata: map[string]string{
+			"model": model,
+		},
+		Outputs: []*sdk.DeviceOutput{
+			{Type: "count"},
+		},
+		Instances: []*sdk.DeviceInstance{},
+	}
+
+	// This is always a single row table.
+	row := table.Rows[0]
+
+	// upsBypassFrequency
+	if !row.RowData[0].IsNull() {
+		deviceData := map[string]interface{}{
+			"base_oid":   row.BaseOid,
+			"table_name": table.Name,
+			"row":        "0",
+			"column":     "1",
+			"oid":        fmt.Sprintf(row.BaseOid, 1), // base_oid and integer column.
+			"multiplier": float32(0.1),                // Units are 0.1 Hertz
+		}
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
+		if err != nil {
+			return nil, err
+		}
+
+		device := &sdk.DeviceInstance{
+			Info:     "upsBypassFrequency",
+			Location: snmpLocation,
+			Data:     deviceData,
+		}
+		frequencyKind.Instances = append(frequencyKind.Instances, device)
+	}
+
+	// upsBypassNumLines ---------------------------------------------------------
+	if !row.RowData[1].IsNull() {
+		deviceData := map[string]interface{}{
+			"base_oid":   row.BaseOid,
+			"table_name": table.Name,
+			"row":        "0",
+			"column":     "2",
+			"oid":        fmt.Sprintf(row.BaseOid, 2), // base_oid and integer column.
+		}
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
+		if err != nil {
+			return nil, err
+		}
+
+		device := &sdk.DeviceInstance{
+			Info:     "upsBypassNumLines",
+			Location: snmpLocation,
+			Data:     deviceData,
+		}
+		countKind.Instances = append(countKind.Instances, device)
+	}
+
+	for _, kind := range []*sdk.DeviceKind{frequencyKind, countKind} {
+		if len(kind.Instances) > 0 {
+			cfg.Devices = append(cfg.Devices, kind)
+		}
+	}
+
+	devices = append(devices, cfg)
+	return devices, err
+}
@@ -19,7 +19,7 @@ func NewUpsBypassTable(snmpServerBase *core.SnmpServerBase) (
 	// Initialize the base.
 	snmpTable, err := core.NewSnmpTable(
 		"UPS-MIB-UPS-Bypass-Table", // Table Name
-		".1.3.6.1.2.1.33.1.2",      // WalkOid
+		".1.3.6.1.2.1.33.1.5.3",    // WalkOid
 		[]string{ // Column Names
 			"upsBypassLineIndex",
 			"upsBypassVoltage",
@@ -50,6 +50,11 @@ type UpsBypassTableDeviceEnumerator struct {
 func (enumerator UpsBypassTableDeviceEnumerator) DeviceEnumerator(
 	data map[string]interface{}) (devices []*sdk.DeviceConfig, err error) {
 
+	// A device per line rather than per column if configured.
+	if core.IsRowDevices(data) {
+		return enumerateRowDevices(enumerator.Table.SnmpTable, data, "upsBypassLine", upsBypassRowColumns)
+	}
+
 	// Get the rack and board ids. Setup the location.
 	rack, board, err := core.GetRackAndBoard(data)
 	if err != nil {
@@ -115,75 +120,89 @@ func (enumerator UpsBypassTableDeviceEnumerator) DeviceEnumerator(
 		powerKind,
 	}
 
-	snmpDeviceConfigMap, err := table.SnmpServerBase.DeviceConfig.ToMap()
+	agentReference, err := table.SnmpServerBase.DeviceConfig.AgentReference()
 	if err != nil {
 		return nil, err
 	}
 
 	for i := 0; i < len(table.Rows); i++ {
+		// The agent may not have every column, such as a bypass that only
+		// reports its voltage. There are no devices for missing columns.
+		row := table.Rows[i]
+
 		// upsBypassVoltage ---------------------------------------------------
 		// deviceData gets shimmed into the DeviceConfig for each synse device.
 		// It varies slightly for each device below.
-		deviceData := map[string]interface{}{
-			"base_oid":   table.Rows[i].BaseOid,
-			"table_name": table.Name,
-			"row":        fmt.Sprintf("%d", i),
-			"column":     "2",
-			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 2), // base_oid and integer column.
-			// No multiplier needed. Units are RMS Volts.
-		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
-		if err != nil {
-			return nil, err
-		}
-
-		device := &sdk.DeviceInstance{
-			Info:     fmt.Sprintf("upsBypassVoltage%d", i),
-			Location: snmpLocation,
-			Data:     deviceData,
+		if !row.RowData[1].IsNull() {
+			deviceData := map[string]interface{}{
+				"base_oid":   row.BaseOid,
+				"table_name": table.Name,
+				"row":        fmt.Sprintf("%d", i),
+				core.LineKey: row.Index(),
+				"column":     "2",
+				"oid":        fmt.Sprintf(row.BaseOid, 2), // base_oid and integer column.
+				// No multiplier needed. Units are RMS Volts.
+			}
+			deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
+			if err != nil {
+				return nil, err
+			}
+
+			device := &sdk.DeviceInstance{
+				Info:     fmt.Sprintf("upsBypassVoltage%d", i),
+				Location: snmpLocation,
+				Data:     deviceData,
+			}
+			voltageKind.Instances = append(voltageKind.Instances, device)
 		}
-		voltageKind.Instances = append(voltageKind.Instances, device)
 
 		// upsBypassCurrent ---------------------------------------------------------
-		deviceData = map[string]interface{}{
-			"base_oid":   table.Rows[i].BaseOid,
-			"table_name": table.Name,
-			"row":        fmt.Sprintf("%d", i),
-			"column":     "3",
-			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 3), // base_oid and integer column.
+		if !row.RowData[2].IsNull() {
+			deviceData := map[string]interface{}{
+				"base_oid":   row.BaseOid,
+				"table_name": table.Name,
+				"row":        fmt.Sprintf("%d", i),
+				core.LineKey: row.Index(),
+				"column":     "3",
+				"oid":        fmt.Sprintf(row.BaseOid, 3), // base_oid and integer column.
+				"multiplier": float32(0.1),                // Units are 0.1 RMS Amp
+			}
+			deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
+			if err != nil {
+				return nil, err
+			}
+
+			device := &sdk.DeviceInstance{
+				Info:     fmt.Sprintf("upsBypassCurrent%d", i),
+				Location: snmpLocation,
+				Data:     deviceData,
+			}
+			currentKind.Instances = append(currentKind.Instances, device)
 		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
-		if err != nil {
-			return nil, err
-		}
-
-		device = &sdk.DeviceInstance{
-			Info:     fmt.Sprintf("upsBypassCurrent%d", i),
-			Location: snmpLocation,
-			Data:     deviceData,
-		}
-		currentKind.Instances = append(currentKind.Instances, device)
 
 		// upsBypassPower --------------------------------------------------------------
-		deviceData = map[string]interface{}{
-			"base_oid":   table.Rows[i].BaseOid,
-			"table_name": table.Name,
-			"row":        fmt.Sprintf("%d", i),
-			"column":     "4",
-			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 4), // base_oid and integer column.
-			// Output is in Watts. No multiplier needed.
-		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
-		if err != nil {
-			return nil, err
-		}
-
-		device = &sdk.DeviceInstance{
-			Info:     fmt.Sprintf("upsBypassPower%d", i),
-			Location: snmpLocation,
-			Data:     deviceData,
+		if !row.RowData[3].IsNull() {
+			deviceData := map[string]interface{}{
+				"base_oid":   row.BaseOid,
+				"table_name": table.Name,
+				"row":        fmt.Sprintf("%d", i),
+				core.LineKey: row.Index(),
+				"column":     "4",
+				"oid":        fmt.Sprintf(row.BaseOid, 4), // base_oid and integer column.
+				// Output is in Watts. No multiplier needed.
+			}
+			deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
+			if err != nil {
+				return nil, err
+			}
+
+			device := &sdk.DeviceInstance{
+				Info:     fmt.Sprintf("upsBypassPower%d", i),
+				Location: snmpLocation,
+				Data:     deviceData,
+			}
+			powerKind.Instances = append(powerKind.Instances, device)
 		}
-		powerKind.Instances = append(powerKind.Instances, device)
 	}
 
 	devices = append(devices, cfg)
@@ -0,0 +1,105 @@
+package mibs
+
+import (
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// upsComputedDevice is a device computed from the input and output lines of a
+// UPS. See devices.SnmpComputed.
+type upsComputedDevice struct {
+	Info       string // Device info.
+	Output     string // Output type.
+	Expression string // Expression over the line columns.
+}
+
+// Columns of the input and output line tables in the expressions.
+const (
+	upsInputTruePower = "{.1.3.6.1.2.1.33.1.3.3.1.5}" // Watts
+	upsOutputVoltage  = "{.1.3.6.1.2.1.33.1.4.4.1.2}" // RMS Volts
+	upsOutputCurrent  = "{.1.3.6.1.2.1.33.1.4.4.1.3}" // .1 RMS Amp
+	upsOutputPower    = "{.1.3.6.1.2.1.33.1.4.4.1.4}" // Watts
+)
+
+// upsComputedDevices are the devices computed for a UPS with multiple lines.
+// Output VA is the sum of volts times amps over the lines, since UPS-MIB has
+// no VA per line. Phase imbalance is the largest deviation of a line current
+// from the average, as a fraction of the average.
+var upsComputedDevices = []upsComputedDevice{
+	{"upsTotalInputPower", "watts.power", "sum(" + upsInputTruePower + ")"},
+	{"upsTotalOutputPower", "watts.power", "sum(" + upsOutputPower + ")"},
+	{"upsOutputPowerFactor", "ratio",
+		"sum(" + upsOutputPower + ") / sum(" + upsOutputVoltage + " * " + upsOutputCurrent + " * 0.1)"},
+	{"upsEfficiency", "ratio", "sum(" + upsOutputPower + ") / sum(" + upsInputTruePower + ")"},
+	{"upsOutputPhaseImbalance", "ratio",
+		"max(max(" + upsOutputCurrent + ") - avg(" + upsOutputCurrent + "), avg(" + upsOutputCurrent +
+			") - min(" + upsOutputCurrent + ")) / avg(" + upsOutputCurrent + ")"},
+}
+
+// EnumerateComputedDevices enumerates the devices computed from the input and
+// output lines when the UPS has more than one input or output line. The
+// devices have an expression rather than an OID.
+func (upsMib *UpsMib) EnumerateComputedDevices(data map[string]interface{}) (
+	devices []*sdk.DeviceConfig, err error) {
+
+	if len(upsMib.UpsInputTable.Rows) < 2 && len(upsMib.UpsOutputTable.Rows) < 2 {
+		return nil, nil
+	}
+
+	// Get the rack and board ids. Setup the location.
+	rack, board, err := core.GetRackAndBoard(data)
+	if err != nil {
+		return nil, err
+	}
+
+	model := upsMib.UpsIdentityTable.UpsIdentity.Model
+	agentReference, err := upsMib.UpsOutputTable.SnmpServerBase.DeviceConfig.AgentReference()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &sdk.DeviceConfig{
+		SchemeVersion: sdk.SchemeVersion{Version: "1.0"},
+		Locations: []*sdk.LocationConfig{
+			{
+				Name:  snmpLocation,
+				Rack:  &sdk.LocationData{Name: rack},
+				Board: &sdk.LocationData{Name: board},
+			},
+		},
+		Devices: []*sdk.DeviceKind{},
+	}
+
+	// One computed kind per output type.
+	kinds := map[string]*sdk.DeviceKind{}
+	for _, computed := range upsComputedDevices {
+		kind, ok := kinds[computed.Output]
+		if !ok {
+			kind = &sdk.DeviceKind{
+				Name: "computed",
+				Metadata: map[string]string{
+					"model": model,
+				},
+				Outputs: []*sdk.DeviceOutput{
+					{Type: computed.Output},
+				},
+				Instances: []*sdk.DeviceInstance{},
+			}
+			kinds[computed.Output] = kind
+			cfg.Devices = append(cfg.Devices, kind)
+		}
+
+		deviceData, err := core.MergeMapStringInterface(agentReference, map[string]interface{}{
+			"expression": computed.Expression,
+		})
+		if err != nil {
+			return nil, err
+		}
+		kind.Instances = append(kind.Instances, &sdk.DeviceInstance{
+			Info:     computed.Info,
+			Location: snmpLocation,
+			Data:     deviceData,
+		})
+	}
+	return []*sdk.DeviceConfig{cfg}, nil
+}
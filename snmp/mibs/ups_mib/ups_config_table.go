@@ -1,6 +1,9 @@
 package mibs
 
 import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
 	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
 )
 
@@ -39,5 +42,150 @@ func NewUpsConfigTable(snmpServerBase *core.SnmpServerBase) (
 	}
 
 	table = &UpsConfigTable{SnmpTable: snmpTable}
+	// Override the default Device Enumerator
+	table.DevEnumerator = UpsConfigTableDeviceEnumerator{table}
 	return table, nil
 }
+
+// upsConfigDevice describes the synse device for a column of the config table.
+type upsConfigDevice struct {
+	column int                    // One based column of the table.
+	kind   string                 // Device kind.
+	output string                 // Output type of the kind.
+	data   map[string]interface{} // Device data other than the OID.
+}
+
+// upsConfigDevices are the synse devices of the config table in column order.
+// The low battery time, audible status and transfer points are writable
+// settings. The transfer points must be on either side of the nominal input
+// voltage. The volt-ampere rating is a raw device since the power handler
+// reads watts.
+var upsConfigDevices = []upsConfigDevice{
+	{column: 1, kind: "voltage", output: "voltage"},
+	{column: 2, kind: "frequency", output: "frequency", data: map[string]interface{}{
+		"multiplier": float32(0.1), // Units are 0.1 Hertz
+	}},
+	{column: 3, kind: "voltage", output: "voltage"},
+	{column: 4, kind: "frequency", output: "frequency", data: map[string]interface{}{
+		"multiplier": float32(0.1), // Units are 0.1 Hertz
+	}},
+	{column: 5, kind: "raw", output: "va.power"},
+	{column: 6, kind: "power", output: "watts.power"},
+	{column: 7, kind: "setting", output: "minutes.duration", data: map[string]interface{}{
+		"writable": true,
+		"minimum":  1,
+	}},
+	{column: 8, kind: "setting", output: "status", data: map[string]interface{}{
+		"writable":    true,
+		"enumeration": EnumerationUpsConfigAudibleStatus,
+	}},
+	{column: 9, kind: "setting", output: "voltage", data: map[string]interface{}{
+		"writable":  true,
+		"minimum":   0,
+		"below_oid": upsConfigInputVoltage,
+	}},
+	{column: 10, kind: "setting", output: "voltage", data: map[string]interface{}{
+		"writable":  true,
+		"minimum":   0,
+		"above_oid": upsConfigInputVoltage,
+	}},
+}
+
+// upsConfigInputVoltage is the numeric OID of the nominal input voltage.
+const upsConfigInputVoltage = ".1.3.6.1.2.1.33.1.9.1.0"
+
+// UpsConfigTableDeviceEnumerator overrides the default SnmpTable device
+// enumerator for the config table.
+type UpsConfigTableDeviceEnumerator struct {
+	Table *UpsConfigTable // Pointer back to the table.
+}
+
+// DeviceEnumerator overrides the default SnmpTable device enumerator. Columns
+// that the agent does not have are not enumerated.
+func (enumerator UpsConfigTableDeviceEnumerator) DeviceEnumerator(
+	data map[string]interface{}) (devices []*sdk.DeviceConfig, err error) {
+
+	// Get the rack and board ids. Setup the location.
+	rack, board, err := core.GetRackAndBoard(data)
+	if err != nil {
+		return nil, err
+	}
+
+	table := enumerator.Table
+	if len(table.Rows) == 0 {
+		return nil, nil
+	}
+	mib := table.Mib.(*UpsMib)
+	model := mib.UpsIdentityTable.UpsIdentity.Model
+
+	agentReference, err := table.SnmpServerBase.DeviceConfig.AgentReference()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &sdk.DeviceConfig{
+		SchemeVersion: sdk.SchemeVersion{Version: "1.0"},
+		Locations: []*sdk.LocationConfig{
+			{
+				Name:  snmpLocation,
+				Rack:  &sdk.LocationData{Name: rack},
+				Board: &sdk.LocationData{Name: board},
+			},
+		},
+		Devices: []*sdk.DeviceKind{},
+	}
+
+	// Kinds are created for the columns the agent has.
+	kinds := map[string]*sdk.DeviceKind{}
+
+	// This is always a single row table.
+	row := table.Rows[0]
+	for _, configDevice := range upsConfigDevices {
+		if row.RowData[configDevice.column-1].IsNull() {
+			continue
+		}
+
+		key := configDevice.kind + "/" + configDevice.output
+		kind, ok := kinds[key]
+		if !ok {
+			kind = &sdk.DeviceKind{
+				Name: configDevice.kind,
+				Metadata: map[string]string{
+					"model": model,
+				},
+				Outputs: []*sdk.DeviceOutput{
+					{Type: configDevice.output},
+				},
+				Instances: []*sdk.DeviceInstance{},
+			}
+			kinds[key] = kind
+			cfg.Devices = append(cfg.Devices, kind)
+		}
+
+		// deviceData gets shimmed into the DeviceConfig for each synse device.
+		deviceData := map[string]interface{}{
+			"base_oid":   row.BaseOid,
+			"table_name": table.Name,
+			"row":        "0",
+			"column":     fmt.Sprint(configDevice.column),
+			"oid":        fmt.Sprintf(row.BaseOid, configDevice.column), // base_oid and integer column.
+		}
+		for key, value := range configDevice.data {
+			deviceData[key] = value
+		}
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
+		if err != nil {
+			return nil, err
+		}
+
+		device := &sdk.DeviceInstance{
+			Info:     table.ColumnList[configDevice.column-1],
+			Location: snmpLocation,
+			Data:     deviceData,
+		}
+		kind.Instances = append(kind.Instances, device)
+	}
+
+	devices = append(devices, cfg)
+	return devices, err
+}
@@ -19,9 +19,9 @@ func NewUpsControlTable(snmpServerBase *core.SnmpServerBase) (
 		".1.3.6.1.2.1.33.1.8",       // WalkOid
 		[]string{ // Column Names
 			"upsShutdownType",
-			"upsShutdownTypeAfterDelay", // Seconds
-			"upsStartupAfterDelay",      // Seconds
-			"upsRebootWithDuration",     // Seconds
+			"upsShutdownAfterDelay", // Seconds
+			"upsStartupAfterDelay",  // Seconds
+			"upsRebootWithDuration", // Seconds
 			"upsAutoRestart",
 		},
 		snmpServerBase, // snmpServer
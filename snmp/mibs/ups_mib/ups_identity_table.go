@@ -71,7 +71,7 @@ func (table *UpsIdentityTable) loadIdentity() *UpsIdentity { // nolint: gocyclo
 	// Need these variable declarations before the gotos.
 	var snmpRow core.SnmpRow
 	var field string
-	var ok bool
+	var err error
 
 	if table == nil || len(table.Rows) < 1 {
 		log.Warn("No identity information.")
@@ -85,33 +85,33 @@ func (table *UpsIdentityTable) loadIdentity() *UpsIdentity { // nolint: gocyclo
 	}
 
 	// Get each field by column from the row.
-	field, ok = snmpRow.RowData[0].Data.(string)
-	if ok {
+	field, err = snmpRow.RowData[0].Text()
+	if err == nil {
 		manufacturer = field
 	}
 
-	field, ok = snmpRow.RowData[1].Data.(string)
-	if ok {
+	field, err = snmpRow.RowData[1].Text()
+	if err == nil {
 		model = field
 	}
 
-	field, ok = snmpRow.RowData[2].Data.(string)
-	if ok {
+	field, err = snmpRow.RowData[2].Text()
+	if err == nil {
 		upsSoftwareVersion = field
 	}
 
-	field, ok = snmpRow.RowData[3].Data.(string)
-	if ok {
+	field, err = snmpRow.RowData[3].Text()
+	if err == nil {
 		agentSoftwareVersion = field
 	}
 
-	field, ok = snmpRow.RowData[4].Data.(string)
-	if ok {
+	field, err = snmpRow.RowData[4].Text()
+	if err == nil {
 		name = field
 	}
 
-	field, ok = snmpRow.RowData[5].Data.(string)
-	if ok {
+	field, err = snmpRow.RowData[5].Text()
+	if err == nil {
 		attachedDevices = field
 	}
 
@@ -147,7 +147,7 @@ func (enumerator UpsIdentityTableDeviceEnumerator) DeviceEnumerator(
 	mib := table.Mib.(*UpsMib)
 	model := mib.UpsIdentityTable.UpsIdentity.Model
 
-	snmpDeviceConfigMap, err := table.SnmpServerBase.DeviceConfig.ToMap()
+	agentReference, err := table.SnmpServerBase.DeviceConfig.AgentReference()
 	if err != nil {
 		return nil, err
 	}
@@ -182,19 +182,21 @@ func (enumerator UpsIdentityTableDeviceEnumerator) DeviceEnumerator(
 		identityKind,
 	}
 
-	// This is always a single row table.
+	// This is always a single row table. Each column is a DisplayString,
+	// formatted by its textual convention when read.
 
 	// upsIdentManufacturer
 	// deviceData gets shimmed into the DeviceConfig for each synse device.
 	// It varies slightly for each device below.
 	deviceData := map[string]interface{}{
-		"base_oid":   table.Rows[0].BaseOid,
-		"table_name": table.Name,
-		"row":        "0",
-		"column":     "1",
-		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 1), // base_oid and integer column.
-	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		"base_oid":           table.Rows[0].BaseOid,
+		"table_name":         table.Name,
+		"row":                "0",
+		"column":             "1",
+		"oid":                fmt.Sprintf(table.Rows[0].BaseOid, 1), // base_oid and integer column.
+		"textual_convention": core.DisplayString,
+	}
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -208,13 +210,14 @@ func (enumerator UpsIdentityTableDeviceEnumerator) DeviceEnumerator(
 
 	// upsIdentModel -----------------------------------------------------------
 	deviceData = map[string]interface{}{
-		"base_oid":   table.Rows[0].BaseOid,
-		"table_name": table.Name,
-		"row":        "0",
-		"column":     "2",
-		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 2), // base_oid and integer column.
-	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		"base_oid":           table.Rows[0].BaseOid,
+		"table_name":         table.Name,
+		"row":                "0",
+		"column":             "2",
+		"oid":                fmt.Sprintf(table.Rows[0].BaseOid, 2), // base_oid and integer column.
+		"textual_convention": core.DisplayString,
+	}
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -228,13 +231,14 @@ func (enumerator UpsIdentityTableDeviceEnumerator) DeviceEnumerator(
 
 	// upsIdentUPSSoftwareVersion ----------------------------------------------
 	deviceData = map[string]interface{}{
-		"base_oid":   table.Rows[0].BaseOid,
-		"table_name": table.Name,
-		"row":        "0",
-		"column":     "3",
-		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 3), // base_oid and integer column.
-	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		"base_oid":           table.Rows[0].BaseOid,
+		"table_name":         table.Name,
+		"row":                "0",
+		"column":             "3",
+		"oid":                fmt.Sprintf(table.Rows[0].BaseOid, 3), // base_oid and integer column.
+		"textual_convention": core.DisplayString,
+	}
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -248,13 +252,14 @@ func (enumerator UpsIdentityTableDeviceEnumerator) DeviceEnumerator(
 
 	// upsIdentAgentSoftwareVersion ----------------------------------------------
 	deviceData = map[string]interface{}{
-		"base_oid":   table.Rows[0].BaseOid,
-		"table_name": table.Name,
-		"row":        "0",
-		"column":     "4",
-		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 4), // base_oid and integer column.
-	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		"base_oid":           table.Rows[0].BaseOid,
+		"table_name":         table.Name,
+		"row":                "0",
+		"column":             "4",
+		"oid":                fmt.Sprintf(table.Rows[0].BaseOid, 4), // base_oid and integer column.
+		"textual_convention": core.DisplayString,
+	}
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -268,13 +273,14 @@ func (enumerator UpsIdentityTableDeviceEnumerator) DeviceEnumerator(
 
 	// upsIdentName ---------------------------------------------------------------
 	deviceData = map[string]interface{}{
-		"base_oid":   table.Rows[0].BaseOid,
-		"table_name": table.Name,
-		"row":        "0",
-		"column":     "5",
-		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 5), // base_oid and integer column.
-	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		"base_oid":           table.Rows[0].BaseOid,
+		"table_name":         table.Name,
+		"row":                "0",
+		"column":             "5",
+		"oid":                fmt.Sprintf(table.Rows[0].BaseOid, 5), // base_oid and integer column.
+		"textual_convention": core.DisplayString,
+	}
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -288,13 +294,14 @@ func (enumerator UpsIdentityTableDeviceEnumerator) DeviceEnumerator(
 
 	// upsIdentAttachedDevices ----------------------------------------------------
 	deviceData = map[string]interface{}{
-		"base_oid":   table.Rows[0].BaseOid,
-		"table_name": table.Name,
-		"row":        "0",
-		"column":     "6",
-		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 6), // base_oid and integer column.
-	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		"base_oid":           table.Rows[0].BaseOid,
+		"table_name":         table.Name,
+		"row":                "0",
+		"column":             "6",
+		"oid":                fmt.Sprintf(table.Rows[0].BaseOid, 6), // base_oid and integer column.
+		"textual_convention": core.DisplayString,
+	}
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,9 @@
 package mibs
 
 import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
 	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
 )
 
@@ -31,5 +34,149 @@ func NewUpsInputHeadersTable(snmpServerBase *core.SnmpServerBase) (
 	}
 
 	table = &UpsInputHeadersTable{SnmpTable: snmpTable}
+	table.DevEnumerator = UpsInputHeadersTableDeviceEnumerator{table}
 	return table, nil
 }
+
+// UpsInputHeadersTableDeviceEnumerator overrides the default SnmpTable device
+// enumerator for the input headers table.
+type UpsInputHeadersTableDeviceEnumerator struct {
+	Table *UpsInputHeadersTable // Pointer back to the table.
+}
+
+// DeviceEnumerator overrides the default SnmpTable device enumerator. Columns
+// that the agent does not have are not enumerated.
+func (enumerator UpsInputHeadersTableDeviceEnumerator) DeviceEnumerator(
+	data map[string]interface{}) (devices []*sdk.DeviceConfig, err error) {
+
+	// Get the rack and board ids. Setup the location.
+	rack, board, err := core.GetRackAndBoard(data)
+	if err != nil {
+		return nil, err
+	}
+
+	table := enumerator.Table
+	if len(table.Rows) == 0 {
+		return nil, nil
+	}
+	mib := table.Mib.(*UpsMib)
+	model := mib.UpsIdentityTable.UpsIdentity.Model
+
+	agentReference, err := table.SnmpServerBase.DeviceConfig.AgentReference()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &sdk.DeviceConfig{
+		SchemeVersion: sdk.SchemeVersion{Version: "1.0"},
+		Locations: []*sdk.LocationConfig{
+			{
+				Name:  snmpLocation,
+				Rack:  &sdk.LocationData{Name: rack},
+				Board: &sdk.LocationData{Name: board},
+			},
+		},
+		Devices: []*sdk.DeviceKind{},
+	}
+
+	// We will have "count" and "alarm" device kinds.
+	countKind := &sdk.DeviceKind{
+		Name: "count",
+		Metadata: map[string]string{
+			"model": model,
+		},
+		Outputs: []*sdk.DeviceOutput{
+			{Type: "count"},
+		},
+		Instances: []*sdk.DeviceInstance{},
+	}
+
+	alarmKind := &sdk.DeviceKind{
+		Name: "alarm",
+		Metadata: map[string]string{
+			"model": model,
+		},
+		Outputs: []*sdk.DeviceOutput{
+			{Type: "status"},
+		},
+		Instances: []*sdk.DeviceInstance{},
+	}
+
+	// This is always a single row table.
+	row := table.Rows[0]
+
+	// upsInputLineBads
+	// A counter of the times the input went out of tolerance. It reads the
+	// change since the previous reading, and the alarm on it warns when the
+	// count increments. The alarm has no hold, so the warning lasts until the
+	// next reading without an increment, one read interval.
+	if !row.RowData[0].IsNull() {
+		oid := fmt.Sprintf(row.BaseOid, 1) // base_oid and integer column.
+		deviceData := map[string]interface{}{
+			"base_oid":   row.BaseOid,
+			"table_name": table.Name,
+			"row":        "0",
+			"column":     "1",
+			"oid":        oid,
+			"counter":    "delta",
+		}
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
+		if err != nil {
+			return nil, err
+		}
+
+		device := &sdk.DeviceInstance{
+			Info:     "upsInputLineBads",
+			Location: snmpLocation,
+			Data:     deviceData,
+		}
+		countKind.Instances = append(countKind.Instances, device)
+
+		deviceData, err = core.MergeMapStringInterface(agentReference, map[string]interface{}{
+			"alarm_oid":  oid,
+			"alarm_name": "upsInputLineBads alarm",
+			"warning":    0,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		device = &sdk.DeviceInstance{
+			Info:     "upsInputLineBads alarm",
+			Location: snmpLocation,
+			Data:     deviceData,
+		}
+		alarmKind.Instances = append(alarmKind.Instances, device)
+	}
+
+	// upsInputNumLines ----------------------------------------------------------
+	if !row.RowData[1].IsNull() {
+		deviceData := map[string]interface{}{
+			"base_oid":   row.BaseOid,
+			"table_name": table.Name,
+			"row":        "0",
+			"column":     "2",
+			"oid":        fmt.Sprintf(row.BaseOid, 2), // base_oid and integer column.
+		}
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
+		if err != nil {
+			return nil, err
+		}
+
+		device := &sdk.DeviceInstance{
+			Info:     "upsInputNumLines",
+			Location: snmpLocation,
+			Data:     deviceData,
+		}
+		countKind.Instances = append(countKind.Instances, device)
+	}
+
+	for _, kind := range []*sdk.DeviceKind{countKind, alarmKind} {
+		if len(kind.Instances) > 0 {
+			cfg.Devices = append(cfg.Devices, kind)
+		}
+	}
+
+	devices = append(devices, cfg)
+	return devices, err
+}
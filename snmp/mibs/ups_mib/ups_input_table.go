@@ -51,6 +51,11 @@ type UpsInputTableDeviceEnumerator struct {
 func (enumerator UpsInputTableDeviceEnumerator) DeviceEnumerator(
 	data map[string]interface{}) (devices []*sdk.DeviceConfig, err error) {
 
+	// A device per line rather than per column if configured.
+	if core.IsRowDevices(data) {
+		return enumerateRowDevices(enumerator.Table.SnmpTable, data, "upsInputLine", upsInputRowColumns)
+	}
+
 	// Get the rack and board ids. Setup the location.
 	rack, board, err := core.GetRackAndBoard(data)
 	if err != nil {
@@ -61,7 +66,7 @@ func (enumerator UpsInputTableDeviceEnumerator) DeviceEnumerator(
 	mib := table.Mib.(*UpsMib)
 	model := mib.UpsIdentityTable.UpsIdentity.Model
 
-	snmpDeviceConfigMap, err := table.SnmpServerBase.DeviceConfig.ToMap()
+	agentReference, err := table.SnmpServerBase.DeviceConfig.AgentReference()
 	if err != nil {
 		return nil, err
 	}
@@ -140,11 +145,12 @@ func (enumerator UpsInputTableDeviceEnumerator) DeviceEnumerator(
 			"base_oid":   table.Rows[i].BaseOid,
 			"table_name": table.Name,
 			"row":        fmt.Sprintf("%d", i),
+			core.LineKey: table.Rows[i].Index(),
 			"column":     "2",
 			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 2), // base_oid and integer column.
 			"multiplier": float32(0.1),                          // Units are 0.1 Hertz
 		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 		if err != nil {
 			return nil, err
 		}
@@ -161,11 +167,12 @@ func (enumerator UpsInputTableDeviceEnumerator) DeviceEnumerator(
 			"base_oid":   table.Rows[i].BaseOid,
 			"table_name": table.Name,
 			"row":        fmt.Sprintf("%d", i),
+			core.LineKey: table.Rows[i].Index(),
 			"column":     "3",
 			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 3), // base_oid and integer column.
 			// No multiplier needed. Units are RMS Volts.
 		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 		if err != nil {
 			return nil, err
 		}
@@ -182,11 +189,12 @@ func (enumerator UpsInputTableDeviceEnumerator) DeviceEnumerator(
 			"base_oid":   table.Rows[i].BaseOid,
 			"table_name": table.Name,
 			"row":        fmt.Sprintf("%d", i),
+			core.LineKey: table.Rows[i].Index(),
 			"column":     "4",
 			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 4), // base_oid and integer column.
 			"multiplier": float32(0.1),                          // Units are 0.1 RMS Amp
 		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 		if err != nil {
 			return nil, err
 		}
@@ -203,11 +211,12 @@ func (enumerator UpsInputTableDeviceEnumerator) DeviceEnumerator(
 			"base_oid":   table.Rows[i].BaseOid,
 			"table_name": table.Name,
 			"row":        fmt.Sprintf("%d", i),
+			core.LineKey: table.Rows[i].Index(),
 			"column":     "5",
 			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 5), // base_oid and integer column.
 			// Output is in Watts. No multiplier needed.
 		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 		if err != nil {
 			return nil, err
 		}
@@ -42,6 +42,9 @@ func NewUpsMib(server *core.SnmpServerBase) (upsMib *UpsMib, err error) { // nol
 		return nil, fmt.Errorf("NewUpsMib, server is nil")
 	}
 
+	// Enumerations referenced by the synse devices for this MIB.
+	registerEnumerations()
+
 	// Initialize Tables.
 	upsIdentityTable, err := NewUpsIdentityTable(server)
 	if err != nil {
@@ -2,10 +2,13 @@ package mibs
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
 	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/smi"
 )
 
 func FindDeviceInstanceByInfo(devices []*sdk.DeviceConfig, info string) (
@@ -29,24 +32,8 @@ func TestUpsMib(t *testing.T) { // nolint: gocyclo
 	// In order to create the table, we need to create an SNMP Server.
 	// In order to create the SNMP server, we need to have an SnmpClient.
 
-	// Create SecurityParameters for the config that should connect to the emulator.
-	securityParameters, err := core.NewSecurityParameters(
-		"simulator",  // User Name
-		core.SHA,     // Authentication Protocol
-		"auctoritas", // Authentication Passphrase
-		core.AES,     // Privacy Protocol
-		"privatus")   // Privacy Passphrase
-	if err != nil {
-		t.Fatal(err) // Fail the test.
-	}
-
-	// Create a config.
-	config, err := core.NewDeviceConfig(
-		"v3",        // SNMP v3
-		"127.0.0.1", // Endpoint
-		1024,        // Port
-		securityParameters,
-		"public") //  Context name
+	// Create a config that connects to the emulator.
+	config, err := core.GetDeviceConfig(emulator.AgentData())
 	if err != nil {
 		t.Fatal(err) // Fail the test.
 	}
@@ -78,6 +65,25 @@ func TestUpsMib(t *testing.T) { // nolint: gocyclo
 		t.Fatalf("testUpsMib: Expected 19 tables, got %d", tableCount)
 	}
 
+	// Every column name should be defined in UPS-MIB under the table WalkOid.
+	mibs := smi.NewMibs()
+	err = mibs.LoadFile("../../../mibs/UPS-MIB.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range testUpsMib.Tables {
+		for _, column := range table.ColumnList {
+			node, err := mibs.Node("UPS-MIB::" + column)
+			if err != nil {
+				t.Fatalf("Table %v: %v", table.Name, err)
+			}
+			if !strings.HasPrefix(node.Oid.ToString+".", table.WalkOid+".") {
+				t.Fatalf("Table %v: column %v %v is not under %v",
+					table.Name, column, node.Oid.ToString, table.WalkOid)
+			}
+		}
+	}
+
 	// Get the ups identity data from the test MIB.
 	upsIdentity := testUpsMib.UpsIdentityTable.UpsIdentity
 
@@ -158,6 +164,99 @@ func TestUpsMib(t *testing.T) { // nolint: gocyclo
 		t.Fatalf("Expected 12 devices from the UpsInputTable, got %d", instanceCount)
 	}
 
+	// Enumerate UpsConfigTable devices. The emulator has the nominal input
+	// and output voltages and frequencies, but no writable columns.
+	upsConfigTable := testUpsMib.UpsConfigTable
+	devices, err = upsConfigTable.SnmpTable.DevEnumerator.DeviceEnumerator(
+		map[string]interface{}{"rack": "my_pet_rack", "board": "my_pet_board"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, frequencyKind, frequencyInstance := FindDeviceInstanceByInfo(devices, "upsConfigInputFreq")
+	if frequencyInstance == nil || frequencyKind.Name != "frequency" ||
+		frequencyInstance.Data["multiplier"] != float32(0.1) ||
+		frequencyInstance.Data["oid"] != ".1.3.6.1.2.1.33.1.9.2.0" {
+		t.Fatalf("Expected upsConfigInputFreq in 0.1 Hertz, got %+v", frequencyInstance)
+	}
+	_, _, lowBattTimeInstance := FindDeviceInstanceByInfo(devices, "upsConfigLowBattTime")
+	if lowBattTimeInstance != nil {
+		t.Fatalf("Expected no upsConfigLowBattTime, got %+v", lowBattTimeInstance)
+	}
+	instanceCount = 0
+	for _, cfg := range devices {
+		for _, kind := range cfg.Devices {
+			instanceCount += len(kind.Instances)
+		}
+	}
+	if instanceCount != 4 {
+		t.Fatalf("Expected 4 devices from the UpsConfigTable, got %d", instanceCount)
+	}
+
+	// Enumerate UpsInputHeadersTable devices. upsInputLineBads is a delta
+	// count with an alarm that warns when it increments.
+	upsInputHeadersTable := testUpsMib.UpsInputHeadersTable
+	devices, err = upsInputHeadersTable.SnmpTable.DevEnumerator.DeviceEnumerator(
+		map[string]interface{}{"rack": "my_pet_rack", "board": "my_pet_board"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, numLinesKind, numLinesInstance := FindDeviceInstanceByInfo(devices, "upsInputNumLines")
+	if numLinesInstance == nil || numLinesKind.Name != "count" ||
+		numLinesInstance.Data["oid"] != ".1.3.6.1.2.1.33.1.3.2.0" {
+		t.Fatalf("Expected upsInputNumLines count, got %+v", numLinesInstance)
+	}
+	_, lineBadsKind, lineBadsInstance := FindDeviceInstanceByInfo(devices, "upsInputLineBads")
+	if lineBadsInstance == nil || lineBadsKind.Name != "count" ||
+		lineBadsInstance.Data["oid"] != ".1.3.6.1.2.1.33.1.3.1.0" || lineBadsInstance.Data["counter"] != "delta" {
+		t.Fatalf("Expected upsInputLineBads delta count, got %+v", lineBadsInstance)
+	}
+	_, alarmKind, alarmInstance := FindDeviceInstanceByInfo(devices, "upsInputLineBads alarm")
+	if alarmInstance == nil || alarmKind.Name != "alarm" ||
+		alarmInstance.Data["alarm_oid"] != ".1.3.6.1.2.1.33.1.3.1.0" || alarmInstance.Data["warning"] != 0 {
+		t.Fatalf("Expected the upsInputLineBads alarm, got %+v", alarmInstance)
+	}
+
+	// Enumerate UpsBypassTable devices. The emulator has the bypass voltage
+	// of each line, but not its current or power.
+	upsBypassTable := testUpsMib.UpsBypassTable
+	devices, err = upsBypassTable.SnmpTable.DevEnumerator.DeviceEnumerator(
+		map[string]interface{}{"rack": "my_pet_rack", "board": "my_pet_board"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, voltageKind, voltageInstance := FindDeviceInstanceByInfo(devices, "upsBypassVoltage2")
+	if voltageInstance == nil || voltageKind.Name != "voltage" ||
+		voltageInstance.Data["oid"] != ".1.3.6.1.2.1.33.1.5.3.1.2.3" || voltageInstance.Data[core.LineKey] != "3" {
+		t.Fatalf("Expected upsBypassVoltage2 on line 3, got %+v", voltageInstance)
+	}
+	instanceCount = 0
+	for _, cfg := range devices {
+		for _, kind := range cfg.Devices {
+			instanceCount += len(kind.Instances)
+		}
+	}
+	if instanceCount != 3 {
+		t.Fatalf("Expected 3 devices from the UpsBypassTable, got %d", instanceCount)
+	}
+
+	// Enumerate UpsBypassHeadersTable devices.
+	upsBypassHeadersTable := testUpsMib.UpsBypassHeadersTable
+	devices, err = upsBypassHeadersTable.SnmpTable.DevEnumerator.DeviceEnumerator(
+		map[string]interface{}{"rack": "my_pet_rack", "board": "my_pet_board"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, frequencyKind, frequencyInstance = FindDeviceInstanceByInfo(devices, "upsBypassFrequency")
+	if frequencyInstance == nil || frequencyKind.Name != "frequency" ||
+		frequencyInstance.Data["multiplier"] != float32(0.1) ||
+		frequencyInstance.Data["oid"] != ".1.3.6.1.2.1.33.1.5.1.0" {
+		t.Fatalf("Expected upsBypassFrequency in 0.1 Hertz, got %+v", frequencyInstance)
+	}
+	_, numLinesKind, numLinesInstance = FindDeviceInstanceByInfo(devices, "upsBypassNumLines")
+	if numLinesInstance == nil || numLinesKind.Name != "count" {
+		t.Fatalf("Expected upsBypassNumLines count, got %+v", numLinesInstance)
+	}
+
 	// Enumerate the mib.
 	// Testing for bad parameters is in TestDevices.
 	devices, err = testUpsMib.EnumerateDevices(
@@ -172,8 +271,8 @@ func TestUpsMib(t *testing.T) { // nolint: gocyclo
 			instanceCount += len(kind.Instances)
 		}
 	}
-	if instanceCount != 40 {
-		t.Fatalf("Expected 40 devices, got %d", instanceCount)
+	if instanceCount != 52 {
+		t.Fatalf("Expected 52 devices, got %d", instanceCount)
 	}
 
 	fmt.Printf("Dumping devices enumerated from UPS-MIB\n")
@@ -211,6 +310,24 @@ func TestUpsMib(t *testing.T) { // nolint: gocyclo
 		t.Fatalf("Expected oid == [.1.3.6.1.2.1.33.1.1.1.0], got [%v]", manufacturerInstance.Data["oid"])
 	}
 
+	// The identity devices are read as DisplayStrings.
+	for _, kind := range manufacturerDevice.Devices {
+		for _, instance := range kind.Instances {
+			if instance.Data["textual_convention"] != core.DisplayString {
+				t.Fatalf("Expected %v to be a DisplayString, got %+v", instance.Info, instance.Data)
+			}
+		}
+	}
+	result, err := testUpsMib.UpsIdentityTable.SnmpServerBase.SnmpClient.Get(
+		fmt.Sprint(manufacturerInstance.Data["oid"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manufacturer, err := result.FormatTextualConvention(fmt.Sprint(manufacturerInstance.Data["textual_convention"]), "")
+	if err != nil || manufacturer != "Eaton Corporation" {
+		t.Fatalf("Expected manufacturer [Eaton Corporation], got [%v] %v", manufacturer, err)
+	}
+
 	powerDevice, powerDeviceKind, powerInstance := FindDeviceInstanceByInfo(devices, "upsInputTruePower1")
 	fmt.Printf("powerDevice: %+v\n", powerDevice)
 	fmt.Printf("powerDeviceKind: %+v\n", powerDeviceKind)
@@ -227,3 +344,15 @@ func TestUpsMib(t *testing.T) { // nolint: gocyclo
 	}
 	t.Logf("TestUpsMib end")
 }
+
+// TestUpsConfigSettings checks that the writable config columns, and only
+// those, are setting devices.
+func TestUpsConfigSettings(t *testing.T) {
+	for _, configDevice := range upsConfigDevices {
+		writable := configDevice.data["writable"] == true
+		if writable != (configDevice.kind == "setting") {
+			t.Fatalf("Expected column %d, writable %v, to be a setting only if writable, got kind %v",
+				configDevice.column, writable, configDevice.kind)
+		}
+	}
+}
@@ -59,7 +59,7 @@ func (enumerator UpsOutputHeadersTableDeviceEnumerator) DeviceEnumerator(
 	mib := table.Mib.(*UpsMib)
 	model := mib.UpsIdentityTable.UpsIdentity.Model
 
-	snmpDeviceConfigMap, err := table.SnmpServerBase.DeviceConfig.ToMap()
+	agentReference, err := table.SnmpServerBase.DeviceConfig.AgentReference()
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +76,7 @@ func (enumerator UpsOutputHeadersTableDeviceEnumerator) DeviceEnumerator(
 		Devices: []*sdk.DeviceKind{},
 	}
 
-	// We will have "status" and "frequency" device kinds.
+	// We will have "status", "frequency" and "count" device kinds.
 	// There is probably a better way of doing this, but this just gets things to
 	// where they need to be for now.
 	statusKind := &sdk.DeviceKind{
@@ -101,9 +101,21 @@ func (enumerator UpsOutputHeadersTableDeviceEnumerator) DeviceEnumerator(
 		Instances: []*sdk.DeviceInstance{},
 	}
 
+	countKind := &sdk.DeviceKind{
+		Name: "count",
+		Metadata: map[string]string{
+			"model": model,
+		},
+		Outputs: []*sdk.DeviceOutput{
+			{Type: "count"},
+		},
+		Instances: []*sdk.DeviceInstance{},
+	}
+
 	cfg.Devices = []*sdk.DeviceKind{
 		statusKind,
 		frequencyKind,
+		countKind,
 	}
 
 	// This is always a single row table.
@@ -118,18 +130,9 @@ func (enumerator UpsOutputHeadersTableDeviceEnumerator) DeviceEnumerator(
 		"column":     "1",
 		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 1), // base_oid and integer column.
 		// This is an enumeration. We need to translate the integer we read to a string.
-		"enumeration": "true", // Defines that this is an enumeration.
-		// Enumeration data. For now we have map[string]string to work with so the
-		// key is fmt.Sprintf("enumeration%d", reading).
-		"enumeration1": "other",
-		"enumeration2": "none",
-		"enumeration3": "normal",
-		"enumeration4": "bypass",
-		"enumeration5": "battery",
-		"enumeration6": "booster",
-		"enumeration7": "reducer",
-	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		"enumeration": EnumerationUpsOutputSource,
+	}
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +153,7 @@ func (enumerator UpsOutputHeadersTableDeviceEnumerator) DeviceEnumerator(
 		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 2), // base_oid and integer column.
 		"multiplier": float32(0.1),                          // Units are 0.1 Hertz
 	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +173,7 @@ func (enumerator UpsOutputHeadersTableDeviceEnumerator) DeviceEnumerator(
 		"column":     "3",
 		"oid":        fmt.Sprintf(table.Rows[0].BaseOid, 3), // base_oid and integer column.
 	}
-	deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+	deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 	if err != nil {
 		return nil, err
 	}
@@ -180,7 +183,7 @@ func (enumerator UpsOutputHeadersTableDeviceEnumerator) DeviceEnumerator(
 		Location: snmpLocation,
 		Data:     deviceData,
 	}
-	statusKind.Instances = append(statusKind.Instances, device)
+	countKind.Instances = append(countKind.Instances, device)
 
 	devices = append(devices, cfg)
 	return devices, err
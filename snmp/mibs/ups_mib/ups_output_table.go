@@ -51,6 +51,11 @@ type UpsOutputTableDeviceEnumerator struct {
 func (enumerator UpsOutputTableDeviceEnumerator) DeviceEnumerator(
 	data map[string]interface{}) (devices []*sdk.DeviceConfig, err error) {
 
+	// A device per line rather than per column if configured.
+	if core.IsRowDevices(data) {
+		return enumerateRowDevices(enumerator.Table.SnmpTable, data, "upsOutputLine", upsOutputRowColumns)
+	}
+
 	// Get the rack and board ids. Setup the location.
 	rack, board, err := core.GetRackAndBoard(data)
 	if err != nil {
@@ -61,7 +66,7 @@ func (enumerator UpsOutputTableDeviceEnumerator) DeviceEnumerator(
 	mib := table.Mib.(*UpsMib)
 	model := mib.UpsIdentityTable.UpsIdentity.Model
 
-	snmpDeviceConfigMap, err := table.SnmpServerBase.DeviceConfig.ToMap()
+	agentReference, err := table.SnmpServerBase.DeviceConfig.AgentReference()
 	if err != nil {
 		return nil, err
 	}
@@ -78,16 +83,16 @@ func (enumerator UpsOutputTableDeviceEnumerator) DeviceEnumerator(
 		Devices: []*sdk.DeviceKind{},
 	}
 
-	// We will have "status", "voltage", "current", and "temperature" device kinds.
+	// We will have "percentage", "voltage", "current", and "power" device kinds.
 	// There is probably a better way of doing this, but this just gets things to
 	// where they need to be for now.
-	statusKind := &sdk.DeviceKind{
-		Name: "status",
+	percentageKind := &sdk.DeviceKind{
+		Name: "percentage",
 		Metadata: map[string]string{
 			"model": model,
 		},
 		Outputs: []*sdk.DeviceOutput{
-			{Type: "status"},
+			{Type: "percentage"},
 		},
 		Instances: []*sdk.DeviceInstance{},
 	}
@@ -126,7 +131,7 @@ func (enumerator UpsOutputTableDeviceEnumerator) DeviceEnumerator(
 	}
 
 	cfg.Devices = []*sdk.DeviceKind{
-		statusKind,
+		percentageKind,
 		voltageKind,
 		currentKind,
 		powerKind,
@@ -141,11 +146,12 @@ func (enumerator UpsOutputTableDeviceEnumerator) DeviceEnumerator(
 			"base_oid":   table.Rows[i].BaseOid,
 			"table_name": table.Name,
 			"row":        fmt.Sprintf("%d", i),
+			core.LineKey: table.Rows[i].Index(),
 			"column":     "2",
 			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 2), // base_oid and integer column.
 			// No multiplier needed. Units are RMS Volts.
 		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 		if err != nil {
 			return nil, err
 		}
@@ -163,11 +169,12 @@ func (enumerator UpsOutputTableDeviceEnumerator) DeviceEnumerator(
 			"base_oid":   table.Rows[i].BaseOid,
 			"table_name": table.Name,
 			"row":        fmt.Sprintf("%d", i),
+			core.LineKey: table.Rows[i].Index(),
 			"column":     "3",
 			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 3), // base_oid and integer column.
 			"multiplier": float32(0.1),                          // Units are 0.1 RMS Amp
 		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 		if err != nil {
 			return nil, err
 		}
@@ -185,11 +192,12 @@ func (enumerator UpsOutputTableDeviceEnumerator) DeviceEnumerator(
 			"base_oid":   table.Rows[i].BaseOid,
 			"table_name": table.Name,
 			"row":        fmt.Sprintf("%d", i),
+			core.LineKey: table.Rows[i].Index(),
 			"column":     "4",
 			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 4), // base_oid and integer column.
 			// Output is in Watts. No multiplier needed.
 		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 		if err != nil {
 			return nil, err
 		}
@@ -207,10 +215,11 @@ func (enumerator UpsOutputTableDeviceEnumerator) DeviceEnumerator(
 			"base_oid":   table.Rows[i].BaseOid,
 			"table_name": table.Name,
 			"row":        fmt.Sprintf("%d", i),
+			core.LineKey: table.Rows[i].Index(),
 			"column":     "5",
 			"oid":        fmt.Sprintf(table.Rows[i].BaseOid, 5), // base_oid and integer column.
 		}
-		deviceData, err = core.MergeMapStringInterface(snmpDeviceConfigMap, deviceData)
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
 		if err != nil {
 			return nil, err
 		}
@@ -220,7 +229,7 @@ func (enumerator UpsOutputTableDeviceEnumerator) DeviceEnumerator(
 			Location: snmpLocation,
 			Data:     deviceData,
 		}
-		statusKind.Instances = append(statusKind.Instances, device)
+		percentageKind.Instances = append(percentageKind.Instances, device)
 	}
 
 	devices = append(devices, cfg)
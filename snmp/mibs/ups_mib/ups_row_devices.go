@@ -0,0 +1,115 @@
+package mibs
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// upsRowColumn is a column of a UPS line table that is an output of a row
+// device.
+type upsRowColumn struct {
+	column     int     // One based column of the table.
+	output     string  // Output type.
+	multiplier float32 // Zero for none.
+}
+
+// The outputs of the row devices of each line table, with the multipliers of
+// the devices for each column.
+var (
+	upsInputRowColumns = []upsRowColumn{
+		{column: 2, output: "frequency", multiplier: 0.1}, // Units are 0.1 Hertz
+		{column: 3, output: "voltage"},
+		{column: 4, output: "current", multiplier: 0.1}, // Units are 0.1 RMS Amp
+		{column: 5, output: "watts.power"},
+	}
+	upsOutputRowColumns = []upsRowColumn{
+		{column: 2, output: "voltage"},
+		{column: 3, output: "current", multiplier: 0.1}, // Units are 0.1 RMS Amp
+		{column: 4, output: "watts.power"},
+		{column: 5, output: "percentage"},
+	}
+	upsBypassRowColumns = []upsRowColumn{
+		{column: 2, output: "voltage"},
+		{column: 3, output: "current", multiplier: 0.1}, // Units are 0.1 RMS Amp
+		{column: 4, output: "watts.power"},
+	}
+)
+
+// enumerateRowDevices creates a row device for each line of a UPS line table,
+// with an output per column, rather than a device per column. The devices
+// are named by the prefix and the row. See core.RowDevicesKey.
+func enumerateRowDevices(table *core.SnmpTable, data map[string]interface{},
+	prefix string, columns []upsRowColumn) (devices []*sdk.DeviceConfig, err error) {
+
+	// Get the rack and board ids. Setup the location.
+	rack, board, err := core.GetRackAndBoard(data)
+	if err != nil {
+		return nil, err
+	}
+
+	mib := table.Mib.(*UpsMib)
+	model := mib.UpsIdentityTable.UpsIdentity.Model
+
+	agentReference, err := table.SnmpServerBase.DeviceConfig.AgentReference()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &sdk.DeviceConfig{
+		SchemeVersion: sdk.SchemeVersion{Version: "1.0"},
+		Locations: []*sdk.LocationConfig{
+			{
+				Name:  snmpLocation,
+				Rack:  &sdk.LocationData{Name: rack},
+				Board: &sdk.LocationData{Name: board},
+			},
+		},
+		Devices: []*sdk.DeviceKind{},
+	}
+
+	rowKind := &sdk.DeviceKind{
+		Name: "row",
+		Metadata: map[string]string{
+			"model": model,
+		},
+		Outputs:   []*sdk.DeviceOutput{},
+		Instances: []*sdk.DeviceInstance{},
+	}
+	for _, column := range columns {
+		rowKind.Outputs = append(rowKind.Outputs, &sdk.DeviceOutput{Type: column.output})
+	}
+	cfg.Devices = []*sdk.DeviceKind{rowKind}
+
+	for i := 0; i < len(table.Rows); i++ {
+		// deviceData gets shimmed into the DeviceConfig for each synse device.
+		deviceData := map[string]interface{}{
+			"base_oid":   table.Rows[i].BaseOid,
+			"table_name": table.Name,
+			"row":        fmt.Sprintf("%d", i),
+			core.LineKey: table.Rows[i].Index(),
+		}
+		for _, column := range columns {
+			// base_oid and integer column.
+			deviceData[core.RowOidPrefix+column.output] = fmt.Sprintf(table.Rows[i].BaseOid, column.column)
+			if column.multiplier != 0 {
+				deviceData[core.RowMultiplierPrefix+column.output] = column.multiplier
+			}
+		}
+		deviceData, err = core.MergeMapStringInterface(agentReference, deviceData)
+		if err != nil {
+			return nil, err
+		}
+
+		device := &sdk.DeviceInstance{
+			Info:     fmt.Sprintf("%v%d", prefix, i),
+			Location: snmpLocation,
+			Data:     deviceData,
+		}
+		rowKind.Instances = append(rowKind.Instances, device)
+	}
+
+	devices = append(devices, cfg)
+	return devices, nil
+}
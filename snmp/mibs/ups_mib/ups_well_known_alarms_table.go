@@ -27,7 +27,7 @@ func NewUpsWellKnownAlarmsTable(snmpServerBase *core.SnmpServerBase) (
 			"upsAlarmOutputBad",
 			"upsAlarmOutputOverload",
 			"upsAlarmOnBypass",
-			"upsAlarmOnBypassBad",
+			"upsAlarmBypassBad",
 			"upsAlarmOutputOffAsRequested",
 			"upsAlarmUpsOffAsRequested",
 			"upsAlarmChargerFailed",
@@ -36,7 +36,7 @@ func NewUpsWellKnownAlarmsTable(snmpServerBase *core.SnmpServerBase) (
 			"upsAlarmFanFailure",
 			"upsAlarmFuseFailure",
 			"upsAlarmGeneralFault",
-			"upsAlarmUpsDiagnosticTestFailed",
+			"upsAlarmDiagnosticTestFailed",
 			"upsAlarmCommunicationsLost",
 			"upsAlarmAwaitingPower",
 			"upsAlarmShutdownPending",
@@ -0,0 +1,81 @@
+// Package record captures the data of a live SNMP agent as snmpwalk output
+// that core.ReplayTransport, the simulator and snmpsim replay.
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	logger "github.com/Sirupsen/logrus"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// DefaultRoot is the root OID that records the whole tree.
+const DefaultRoot = ".1.3.6.1"
+
+// Walk walks each root OID and returns the results in OID order without
+// duplicates or null values. Roots may be symbolic if MIBs are loaded, for
+// example UPS-MIB::upsMIB.
+func Walk(client *core.SnmpClient, roots []string) ([]core.ReadResult, error) {
+	if len(roots) == 0 {
+		roots = []string{DefaultRoot}
+	}
+
+	byOid := map[string]core.ReadResult{}
+	for _, root := range roots {
+		results, err := client.Walk(root)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("Recorded %d OIDs under %v", len(results), root)
+
+		for _, result := range results {
+			if result.IsNull() {
+				continue
+			}
+			oid, err := core.NewOid(result.Oid)
+			if err != nil {
+				return nil, err
+			}
+			byOid[oid.ToString] = result
+		}
+	}
+
+	results := make([]core.ReadResult, 0, len(byOid))
+	for _, result := range byOid {
+		results = append(results, result)
+	}
+	Sort(results)
+	return results, nil
+}
+
+// Sort sorts results in OID order.
+func Sort(results []core.ReadResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, errA := core.NewOid(results[i].Oid)
+		b, errB := core.NewOid(results[j].Oid)
+		if errA != nil || errB != nil {
+			return results[i].Oid < results[j].Oid
+		}
+		return core.CompareOids(a.ToSlice, b.ToSlice) < 0
+	})
+}
+
+// Write writes results as snmpwalk output, one line per result. Values that
+// snmpwalk output can not represent are skipped with a warning.
+func Write(writer io.Writer, results []core.ReadResult) error {
+	buffered := bufio.NewWriter(writer)
+	for _, result := range results {
+		line, err := core.FormatSnmpWalkLine(result)
+		if err != nil {
+			logger.Warnf("Skipping %v: %v", result.Oid, err)
+			continue
+		}
+		if _, err = fmt.Fprintln(buffered, line); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
@@ -0,0 +1,137 @@
+package record
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/simulator/simtest"
+)
+
+// record walks the emulator data in the simulator.
+func record(t *testing.T, roots ...string) []core.ReadResult {
+	agent := simtest.NewAgent(t, "../../emulator/data")
+	defer agent.Close() // nolint: errcheck
+
+	client, err := core.NewSnmpClient(simtest.DeviceConfig(t, agent, "public"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Walk(client, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
+
+// TestRecordRoundTrip records the emulator data and checks that the snmpwalk
+// output parses back to the same data.
+func TestRecordRoundTrip(t *testing.T) {
+	file, err := os.Open("../../emulator/data/public.snmpwalk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close() // nolint: errcheck
+	expected, err := core.ParseSnmpWalk(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := record(t)
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+
+	var output bytes.Buffer
+	if err = Write(&output, results); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := core.ParseSnmpWalk(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != len(expected) {
+		t.Fatalf("Expected %d parsed results, got %d", len(expected), len(parsed))
+	}
+	for i := range expected {
+		want := core.NewReadResult(expected[i])
+		got := core.NewReadResult(parsed[i])
+		if got.Oid != want.Oid || got.Type != want.Type || got.Format() != want.Format() {
+			t.Fatalf("Expected %+v, got %+v", want, got)
+		}
+	}
+}
+
+// TestRecordRoots checks that only the roots are recorded, once each.
+func TestRecordRoots(t *testing.T) {
+	results := record(t, ".1.3.6.1.2.1.33.1.2", ".1.3.6.1.2.1.33.1.2.3")
+	if len(results) != 6 {
+		t.Fatalf("Expected 6 results, got %d: %+v", len(results), results)
+	}
+	if results[0].Oid != ".1.3.6.1.2.1.33.1.2.2.0" {
+		t.Fatalf("Expected the results in OID order, got %+v", results)
+	}
+}
+
+// find returns the text of the result with the OID.
+func find(t *testing.T, results []core.ReadResult, oid string) string {
+	for _, result := range results {
+		if result.Oid == oid {
+			return result.Format()
+		}
+	}
+	t.Fatalf("No result for %v", oid)
+	return ""
+}
+
+// TestRedact redacts the emulator data.
+func TestRedact(t *testing.T) {
+	results, err := Redact(record(t), Redaction{
+		Serials:   true,
+		Names:     true,
+		Addresses: true,
+		Oids:      []string{".1.3.6.1.2.1.1.3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	if err = Write(&output, results); err != nil {
+		t.Fatal(err)
+	}
+	text := output.String()
+	for _, original := range []string{"PowerXpert-00-20-85-F1-56-DE", "00-20-85-F1-56-DE", "10.193.3.201", "Your Location", "00 20 85 F1 56 DE"} {
+		if strings.Contains(text, original) {
+			t.Errorf("Expected %q to be redacted", original)
+		}
+	}
+
+	sysName := find(t, results, ".1.3.6.1.2.1.1.5.0")
+	if !strings.HasPrefix(sysName, "name-") {
+		t.Errorf("Expected a name placeholder for sysName, got %q", sysName)
+	}
+	if sysDescr := find(t, results, ".1.3.6.1.2.1.1.1.0"); !strings.Contains(sysDescr, "Linux "+sysName+" ") {
+		t.Errorf("Expected %q in sysDescr, got %q", sysName, sysDescr)
+	}
+	if sysUpTime := find(t, results, ".1.3.6.1.2.1.1.3.0"); sysUpTime != "0" {
+		t.Errorf("Expected sysUpTime 0, got %q", sysUpTime)
+	}
+
+	// The address is replaced in the index and the value alike, and loopback
+	// is left alone.
+	if address := find(t, results, ".1.3.6.1.2.1.4.20.1.1.198.18.0.3"); address != "198.18.0.3" {
+		t.Errorf("Expected the placeholder address, got %q", address)
+	}
+	if address := find(t, results, ".1.3.6.1.2.1.4.20.1.1.127.0.0.1"); address != "127.0.0.1" {
+		t.Errorf("Expected the loopback address, got %q", address)
+	}
+	if uris := find(t, results, ".1.3.6.1.2.1.47.1.1.1.1.18.1"); !strings.HasPrefix(uris, "http://198.18.0.3  http://198.18.0.3/") {
+		t.Errorf("Expected the placeholder address in entPhysicalUris, got %q", uris)
+	}
+	if !strings.Contains(text, ".1.3.6.1.2.1.2.2.1.6.2 = Hex-STRING: 02 00 00 00 00 01") {
+		t.Errorf("Expected the placeholder MAC address for ifPhysAddress.2")
+	}
+}
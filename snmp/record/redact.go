@@ -0,0 +1,322 @@
+package record
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/soniah/gosnmp"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// Redaction selects the data that Redact replaces with placeholders.
+type Redaction struct {
+	// Serials replaces serial numbers and asset IDs.
+	Serials bool
+	// Names replaces system, UPS and entity names, contacts and locations.
+	Names bool
+	// Addresses replaces IPv4 and MAC addresses in values, table indexes and
+	// text.
+	Addresses bool
+	// Oids are more OIDs whose values, and the values under them, are
+	// replaced. They may be symbolic if MIBs are loaded.
+	Oids []string
+}
+
+// serialOids are the columns and scalars with serial numbers.
+var serialOids = []string{
+	".1.3.6.1.2.1.47.1.1.1.1.11", // ENTITY-MIB::entPhysicalSerialNum
+	".1.3.6.1.2.1.47.1.1.1.1.15", // ENTITY-MIB::entPhysicalAssetID
+}
+
+// nameOids are the columns and scalars with names, contacts and locations.
+var nameOids = []string{
+	".1.3.6.1.2.1.1.4",           // SNMPv2-MIB::sysContact
+	".1.3.6.1.2.1.1.5",           // SNMPv2-MIB::sysName
+	".1.3.6.1.2.1.1.6",           // SNMPv2-MIB::sysLocation
+	".1.3.6.1.2.1.31.1.1.1.18",   // IF-MIB::ifAlias
+	".1.3.6.1.2.1.33.1.1.5",      // UPS-MIB::upsIdentName
+	".1.3.6.1.2.1.33.1.1.6",      // UPS-MIB::upsIdentAttachedDevices
+	".1.3.6.1.2.1.47.1.1.1.1.14", // ENTITY-MIB::entPhysicalAlias
+}
+
+// macOids are the columns with MAC addresses.
+var macOids = []string{
+	".1.3.6.1.2.1.2.2.1.6",  // IF-MIB::ifPhysAddress
+	".1.3.6.1.2.1.3.1.1.2",  // RFC1213-MIB::atPhysAddress
+	".1.3.6.1.2.1.4.22.1.2", // IP-MIB::ipNetToMediaPhysAddress
+	".1.3.6.1.2.1.4.35.1.4", // IP-MIB::ipNetToPhysicalPhysAddress
+}
+
+// firstIndexArc is the first arc of an OID that can be part of a table index.
+// Addresses in the OID before it are not replaced. Example: the 7 in
+// .1.3.6.1.2.1.4.20.1.1.10.193.3.201 is the earliest an address can start.
+const firstIndexArc = 7
+
+// minTextLength is the shortest name or serial that is replaced where it
+// appears in other text, so that short values such as "/" or "1" do not
+// mangle every string.
+const minTextLength = 4
+
+// redactedAddressBase is the first placeholder IPv4 address, in the
+// benchmarking range of RFC 2544 so it can not be a real address.
+var redactedAddressBase = net.IPv4(198, 18, 0, 1).To4()
+
+// redactor holds the placeholders so that each original value has the same
+// placeholder everywhere.
+type redactor struct {
+	redaction Redaction
+	serials   [][]uint64
+	names     [][]uint64
+	macs      [][]uint64
+	oids      [][]uint64
+
+	text      map[string]string // Serials and names.
+	counts    map[string]int    // Placeholders by prefix.
+	addresses map[string]string // IPv4 addresses.
+	patterns  map[string]*regexp.Regexp
+	macText   map[string]string // MAC addresses as octets.
+}
+
+// Redact returns a copy of results with the data selected by redaction
+// replaced by placeholders. The same original value always gets the same
+// placeholder, so table indexes and references still match. The results are
+// sorted again since replacing addresses in table indexes changes the OIDs.
+func Redact(results []core.ReadResult, redaction Redaction) ([]core.ReadResult, error) {
+	r := &redactor{
+		redaction: redaction,
+		text:      map[string]string{},
+		counts:    map[string]int{},
+		addresses: map[string]string{},
+		patterns:  map[string]*regexp.Regexp{},
+		macText:   map[string]string{},
+	}
+	var err error
+	if r.serials, err = parseOids(serialOids); err != nil {
+		return nil, err
+	}
+	if r.names, err = parseOids(nameOids); err != nil {
+		return nil, err
+	}
+	if r.macs, err = parseOids(macOids); err != nil {
+		return nil, err
+	}
+	for _, oid := range redaction.Oids {
+		resolved, err := core.ResolveOid(oid)
+		if err != nil {
+			return nil, err
+		}
+		parsed, _ := core.NewOid(resolved) // Resolved OIDs are valid.
+		r.oids = append(r.oids, parsed.ToSlice)
+	}
+
+	oids := make([][]uint64, len(results))
+	for i, result := range results {
+		oid, err := core.NewOid(result.Oid)
+		if err != nil {
+			return nil, err
+		}
+		oids[i] = oid.ToSlice
+		r.collect(oid.ToSlice, result)
+	}
+	for address := range r.addresses {
+		r.patterns[address] = regexp.MustCompile(`\b` + regexp.QuoteMeta(address) + `\b`)
+	}
+
+	redacted := make([]core.ReadResult, len(results))
+	for i, result := range results {
+		redacted[i] = r.redact(oids[i], result)
+	}
+	Sort(redacted)
+	return redacted, nil
+}
+
+// collect assigns placeholders to the values that are redacted.
+func (r *redactor) collect(oid []uint64, result core.ReadResult) {
+	switch {
+	case r.redaction.Serials && underAny(oid, r.serials):
+		r.placeholder(result, "SERIAL")
+	case r.redaction.Names && underAny(oid, r.names):
+		r.placeholder(result, "name")
+	case r.redaction.Addresses && underAny(oid, r.macs):
+		if octets, err := result.Bytes(); err == nil && len(octets) == 6 {
+			if _, ok := r.macText[string(octets)]; !ok {
+				n := len(r.macText) + 1
+				r.macText[string(octets)] = string([]byte{0x02, 0, 0, 0, byte(n >> 8), byte(n)})
+			}
+		}
+	case r.redaction.Addresses && result.Type == gosnmp.IPAddress:
+		address, err := result.Text()
+		if err == nil && isRedactedAddress(address) {
+			if _, ok := r.addresses[address]; !ok {
+				r.addresses[address] = placeholderAddress(len(r.addresses))
+			}
+		}
+	}
+}
+
+// placeholder assigns a numbered placeholder to a text value.
+func (r *redactor) placeholder(result core.ReadResult, prefix string) {
+	text, err := result.Text()
+	if err != nil || strings.TrimSpace(text) == "" {
+		return
+	}
+	if _, ok := r.text[text]; !ok {
+		r.counts[prefix]++
+		r.text[text] = fmt.Sprintf("%v-%d", prefix, r.counts[prefix])
+	}
+}
+
+// redact redacts one result.
+func (r *redactor) redact(oid []uint64, result core.ReadResult) core.ReadResult {
+	if r.redaction.Addresses {
+		result.Oid = r.redactIndex(oid)
+	}
+
+	if underAny(oid, r.oids) {
+		switch result.Type {
+		case gosnmp.OctetString, gosnmp.Opaque:
+			result.Data = "redacted"
+		case gosnmp.IPAddress:
+			result.Data = "0.0.0.0"
+		case gosnmp.Integer:
+			result.Data = 0
+		case gosnmp.Counter32, gosnmp.Gauge32:
+			result.Data = uint(0)
+		case gosnmp.TimeTicks:
+			result.Data = uint32(0)
+		case gosnmp.Counter64:
+			result.Data = uint64(0)
+		}
+		return result
+	}
+
+	switch result.Type {
+	case gosnmp.IPAddress:
+		if address, err := result.Text(); err == nil {
+			if placeholder, ok := r.addresses[address]; ok {
+				result.Data = placeholder
+			}
+		}
+	case gosnmp.OctetString:
+		octets, err := result.Bytes()
+		if err != nil {
+			break
+		}
+		if placeholder, ok := r.macText[string(octets)]; ok {
+			result.Data = []byte(placeholder)
+			break
+		}
+		if text := r.redactText(string(octets)); text != string(octets) {
+			result.Data = text
+		}
+	}
+	return result
+}
+
+// redactIndex replaces addresses in the index part of an OID.
+func (r *redactor) redactIndex(oid []uint64) string {
+	redacted := append([]uint64{}, oid...)
+	for i := firstIndexArc; i+4 <= len(redacted); i++ {
+		if redacted[i] > 255 || redacted[i+1] > 255 || redacted[i+2] > 255 || redacted[i+3] > 255 {
+			continue
+		}
+		address := fmt.Sprintf("%d.%d.%d.%d", redacted[i], redacted[i+1], redacted[i+2], redacted[i+3])
+		placeholder, ok := r.addresses[address]
+		if !ok {
+			continue
+		}
+		for j, octet := range net.ParseIP(placeholder).To4() {
+			redacted[i+j] = uint64(octet)
+		}
+		i += 3
+	}
+	result, _ := core.NewOidFromSlice(redacted) // No error.
+	return result.ToString
+}
+
+// redactText replaces names, serials and addresses that appear in text, such
+// as the host name in sysDescr.
+func (r *redactor) redactText(text string) string {
+	// Whole values first, then the longest, so that one original that
+	// contains another is replaced whole.
+	if placeholder, ok := r.text[text]; ok {
+		return placeholder
+	}
+	originals := make([]string, 0, len(r.text))
+	for original := range r.text {
+		originals = append(originals, original)
+	}
+	sort.Slice(originals, func(i, j int) bool { return len(originals[i]) > len(originals[j]) })
+	for _, original := range originals {
+		if len(original) < minTextLength {
+			continue
+		}
+		text = strings.Replace(text, original, r.text[original], -1)
+	}
+
+	for octets, placeholder := range r.macText {
+		for _, separator := range []string{"-", ":", ""} {
+			for _, format := range []string{"%02X", "%02x"} {
+				text = strings.Replace(text, formatMac(octets, format, separator),
+					formatMac(placeholder, format, separator), -1)
+			}
+		}
+	}
+
+	for address, pattern := range r.patterns {
+		text = pattern.ReplaceAllLiteralString(text, r.addresses[address])
+	}
+	return text
+}
+
+// formatMac formats MAC address octets with a format per octet and a
+// separator.
+func formatMac(octets string, format string, separator string) string {
+	parts := make([]string, len(octets))
+	for i := 0; i < len(octets); i++ {
+		parts[i] = fmt.Sprintf(format, octets[i])
+	}
+	return strings.Join(parts, separator)
+}
+
+// isRedactedAddress returns false for addresses that identify nothing, such
+// as 0.0.0.0, loopback, netmasks and multicast.
+func isRedactedAddress(address string) bool {
+	ip := net.ParseIP(address).To4()
+	return ip != nil && ip[0] != 0 && ip[0] != 127 && ip[0] < 224
+}
+
+// placeholderAddress is the nth placeholder address.
+func placeholderAddress(n int) string {
+	base := uint32(redactedAddressBase[0])<<24 | uint32(redactedAddressBase[1])<<16 |
+		uint32(redactedAddressBase[2])<<8 | uint32(redactedAddressBase[3])
+	address := base + uint32(n)
+	return strconv.Itoa(int(address>>24)) + "." + strconv.Itoa(int(address>>16&0xff)) + "." +
+		strconv.Itoa(int(address>>8&0xff)) + "." + strconv.Itoa(int(address&0xff))
+}
+
+// parseOids parses numeric OIDs.
+func parseOids(oids []string) (parsed [][]uint64, err error) {
+	for _, oid := range oids {
+		o, err := core.NewOid(oid)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, o.ToSlice)
+	}
+	return parsed, nil
+}
+
+// underAny returns true if the OID is one of the prefixes or under one.
+func underAny(oid []uint64, prefixes [][]uint64) bool {
+	for _, prefix := range prefixes {
+		if core.CompareOids(oid, prefix) == 0 || core.HasOidPrefix(oid, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package servers
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/devices"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// AlarmsKey is the dynamicRegistration key of the alarms of an agent. An
+// alarm is a status device with thresholds on the value of another device of
+// the agent, enumerated or declared:
+//
+//	alarms:
+//	  - oid: UPS-MIB::upsEstimatedChargeRemaining.0 # Required. May be symbolic.
+//	    info: batteryChargeLow # Name of the alarm. Default the OID name and alarm.
+//	    condition: below       # above (default) or below.
+//	    warning: 40
+//	    critical: 20
+//	    hysteresis: 2          # Default 0.
+//	    hold: 30s              # Default 0.
+//
+// A device may have several alarms with different names. The status is ok,
+// warning or critical. See devices.SnmpAlarm.
+const AlarmsKey = "alarms"
+
+// alarmKeys are the keys of an alarm that are not device data.
+var alarmKeys = map[string]bool{"oid": true, "info": true}
+
+// AlarmDevices creates the alarm devices in a dynamicRegistration entry.
+// oidMap has the devices of the agent by numeric OID. Each alarm must be on
+// one of them, and the alarms of a device must have different names. alarmed
+// has the enumerated alarms by alarmRuleKey.
+func AlarmDevices(data map[string]interface{}, oidMap map[string]*sdk.DeviceInstance,
+	alarmed map[string]bool) ([]*sdk.DeviceConfig, error) {
+	declarations, ok := data[AlarmsKey]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := declarations.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%v should be a list, got %T", AlarmsKey, declarations)
+	}
+
+	location, err := agentLocation(data)
+	if err != nil {
+		return nil, err
+	}
+	agentID := core.AgentID(data)
+	kind := &sdk.DeviceKind{
+		Name:      "alarm",
+		Metadata:  map[string]string{},
+		Outputs:   []*sdk.DeviceOutput{{Type: "status"}},
+		Instances: []*sdk.DeviceInstance{},
+	}
+	if model, _ := data["model"].(string); model != "" {
+		kind.Metadata["model"] = model
+	}
+	hasAlarm := map[string]bool{}
+	for oid := range alarmed {
+		hasAlarm[oid] = true
+	}
+
+	for i, item := range list {
+		declaration, err := stringMap(item)
+		if err != nil {
+			return nil, fmt.Errorf("%v[%d]: %v", AlarmsKey, i, err)
+		}
+		instance, numericOid, err := alarmInstance(declaration, agentID)
+		if err != nil {
+			return nil, fmt.Errorf("%v[%d]: %v", AlarmsKey, i, err)
+		}
+		if _, ok := oidMap[numericOid]; !ok {
+			return nil, fmt.Errorf("%v[%d]: %v is not a device of agent %v",
+				AlarmsKey, i, core.OidDisplay(numericOid), agentID)
+		}
+		key := alarmRuleKey(numericOid, instance)
+		if hasAlarm[key] {
+			return nil, fmt.Errorf("%v[%d]: %v already has an alarm %v",
+				AlarmsKey, i, core.OidDisplay(numericOid), instance.Info)
+		}
+		hasAlarm[key] = true
+		kind.Instances = append(kind.Instances, instance)
+	}
+
+	return []*sdk.DeviceConfig{{
+		SchemeVersion: sdk.SchemeVersion{Version: "1.0"},
+		Locations:     []*sdk.LocationConfig{location},
+		Devices:       []*sdk.DeviceKind{kind},
+	}}, nil
+}
+
+// alarmInstance creates the device instance for an alarm and returns the
+// numeric OID of the device it is on.
+func alarmInstance(declaration map[string]interface{}, agentID string) (
+	instance *sdk.DeviceInstance, numericOid string, err error) {
+
+	oid, ok := declaration["oid"].(string)
+	if !ok || oid == "" {
+		return nil, "", fmt.Errorf("oid is required")
+	}
+	if numericOid, err = core.ResolveOid(oid); err != nil {
+		return nil, "", err
+	}
+
+	info := core.OidName(numericOid) + " alarm"
+	if i, ok := declaration["info"]; ok {
+		info = fmt.Sprint(i)
+	}
+
+	deviceData := map[string]interface{}{core.AgentKey: agentID, "alarm_oid": oid, "alarm_name": info}
+	for key, value := range declaration {
+		if !alarmKeys[key] {
+			deviceData[key] = value
+		}
+	}
+	if err = devices.CheckAlarm(deviceData); err != nil {
+		return nil, "", err
+	}
+
+	return &sdk.DeviceInstance{
+		Info:     info,
+		Location: snmpLocation,
+		Data:     deviceData,
+	}, numericOid, nil
+}
+
+// alarmRuleKey identifies an alarm instance by the numeric OID of the device
+// it is on and its name, which defaults to its info as in the alarm handler.
+func alarmRuleKey(numericOid string, instance *sdk.DeviceInstance) string {
+	name := devices.AlarmName(instance.Data)
+	if name == "" {
+		name = instance.Info
+	}
+	return numericOid + "/" + name
+}
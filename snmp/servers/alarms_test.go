@@ -0,0 +1,138 @@
+package servers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// alarmAgent is a declared agent with alarms, as the YAML of the plugin
+// configuration parses.
+func alarmAgent(alarms ...map[interface{}]interface{}) map[string]interface{} {
+	data := declaredAgent(
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.5.0", "kind": "temperature"},
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.1.0"},
+	)
+	list := []interface{}{}
+	for _, alarm := range alarms {
+		list = append(list, alarm)
+	}
+	data[AlarmsKey] = list
+	return data
+}
+
+// TestAlarmDevices enumerates alarms on declared devices.
+func TestAlarmDevices(t *testing.T) {
+	data := alarmAgent(
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.5.0", "critical": 35, "hold": "1m", "info": "ambientHot"},
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.1.0", "condition": "below", "warning": 10},
+	)
+
+	deviceConfigs, err := EnumerateDevices(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deviceConfigs) != 3 {
+		t.Fatalf("Expected the declared devices, the alarms and the inventory, got %d device configs", len(deviceConfigs))
+	}
+	kind := deviceConfigs[1].Devices[0]
+	if kind.Name != "alarm" || kind.Outputs[0].Type != "status" || len(kind.Instances) != 2 {
+		t.Fatalf("Expected two alarm devices with the status output, got %+v", kind)
+	}
+
+	hot := kind.Instances[0]
+	if hot.Info != "ambientHot" || hot.Data["alarm_oid"] != ".1.3.6.1.4.1.534.1.6.5.0" || hot.Data["alarm_name"] != "ambientHot" ||
+		hot.Data["critical"] != 35 || hot.Data["hold"] != "1m" || hot.Data[core.AgentKey] != "pdu-1" {
+		t.Fatalf("Expected the alarm data with an agent reference, got %+v", hot)
+	}
+	if _, ok := hot.Data["oid"]; ok {
+		t.Fatalf("Expected no oid in alarm data, got %+v", hot.Data)
+	}
+
+	// Alarms sort after the two devices in config order.
+	if hot.SortOrdinal != 3 || kind.Instances[1].SortOrdinal != 4 {
+		t.Fatalf("Expected sort ordinals 3 and 4, got %d and %d",
+			hot.SortOrdinal, kind.Instances[1].SortOrdinal)
+	}
+}
+
+// TestAlarmDevicesSameDevice enumerates two alarms on the same device.
+func TestAlarmDevicesSameDevice(t *testing.T) {
+	data := alarmAgent(
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.5.0", "warning": 30, "info": "ambientWarm"},
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.5.0", "condition": "below", "critical": 5, "info": "ambientCold"},
+	)
+
+	deviceConfigs, err := EnumerateDevices(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kind := deviceConfigs[1].Devices[0]
+	if len(kind.Instances) != 2 {
+		t.Fatalf("Expected two alarm devices, got %+v", kind)
+	}
+	if kind.Instances[0].Data["alarm_name"] != "ambientWarm" || kind.Instances[1].Data["alarm_name"] != "ambientCold" {
+		t.Fatalf("Expected the alarm names, got %+v and %+v", kind.Instances[0].Data, kind.Instances[1].Data)
+	}
+}
+
+// TestAlarmDevicesErrors checks bad alarms.
+func TestAlarmDevicesErrors(t *testing.T) {
+	cases := []struct {
+		alarms   []map[interface{}]interface{}
+		expected string
+	}{
+		{[]map[interface{}]interface{}{{"warning": 1}}, "oid is required"},
+		{[]map[interface{}]interface{}{{"oid": ".1.3.6.1.2.1.1.3.0", "warning": 1}}, "is not a device of agent pdu-1"},
+		{[]map[interface{}]interface{}{{"oid": ".1.3.6.1.4.1.534.1.6.1.0"}}, "needs a warning or critical threshold"},
+		{[]map[interface{}]interface{}{
+			{"oid": ".1.3.6.1.4.1.534.1.6.1.0", "warning": 1},
+			{"oid": ".1.3.6.1.4.1.534.1.6.1.0", "critical": 2},
+		}, "already has an alarm"},
+	}
+	for _, c := range cases {
+		_, err := EnumerateDevices(alarmAgent(c.alarms...))
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Fatalf("Expected error %q for %v, got %v", c.expected, c.alarms, err)
+		}
+	}
+}
+
+// TestAlarmDevicesEnumerated checks that an alarm does not have the name of an
+// enumerated alarm on the same device, such as UPS-MIB::upsInputLineBads.0.
+func TestAlarmDevicesEnumerated(t *testing.T) {
+	data := alarmAgent(map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.1.0", "warning": 1, "info": "outletLoad"})
+	deviceConfigs, err := DeclaredDevices(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oidMap, _, _, err := mapOidsToInstances(deviceConfigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alarmed := map[string]bool{".1.3.6.1.4.1.534.1.6.1.0/outletLoad": true}
+	_, err = AlarmDevices(data, oidMap, alarmed)
+	if err == nil || !strings.Contains(err.Error(), "already has an alarm") {
+		t.Fatalf("Expected an error for the enumerated alarm, got %v", err)
+	}
+
+	// The UPS-MIB enumerates the upsInputLineBads alarm. Another name is
+	// another alarm on the same device.
+	data = declaredAgent()
+	data["model"] = "PXGMS UPS + EATON 93PM"
+	data[AlarmsKey] = []interface{}{
+		map[interface{}]interface{}{"oid": ".1.3.6.1.2.1.33.1.3.1.0", "warning": 1, "info": "upsInputLineBads alarm"},
+	}
+	_, err = EnumerateDevices(data)
+	if err == nil || !strings.Contains(err.Error(), "already has an alarm upsInputLineBads alarm") {
+		t.Fatalf("Expected an error for the upsInputLineBads alarm, got %v", err)
+	}
+	data[AlarmsKey] = []interface{}{
+		map[interface{}]interface{}{"oid": ".1.3.6.1.2.1.33.1.3.1.0", "critical": 10, "info": "upsInputLineBadsHigh"},
+	}
+	if _, err = EnumerateDevices(data); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,205 @@
+package servers
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// DevicesKey is the dynamicRegistration key of the devices declared for an
+// agent by OID, for values that no MIB implementation enumerates:
+//
+//	devices:
+//	  - oid: .1.3.6.1.4.1.534.1.6.1.0 # Required. May be symbolic.
+//	    kind: temperature             # Device handler. Default raw.
+//	    output: temperature           # Output type of raw devices. Default raw.
+//	    info: ambientTemperature      # Default the OID name.
+//	    multiplier: 0.1
+//	    enumeration: UPS-MIB::upsBatteryStatus
+//
+// A computed device has an expression over other values of the agent instead
+// of an OID. See core.Expression.
+//
+//	devices:
+//	  - kind: computed
+//	    expression: sum({UPS-MIB::upsInputTruePower})
+//	    output: watts.power # Default raw.
+//	    info: totalInputPower # Default the expression.
+//
+// Other keys, such as counter or textual_convention, are device data for the
+// handler. A declared device reads from the agent of its entry, or from the
+// agent named by an agent key.
+const DevicesKey = "devices"
+
+// kindOutputs are the output types of the device handlers. A duration device
+// in minutes has the minutes.duration output instead.
+var kindOutputs = map[string]string{
+	"current":     "current",
+	"frequency":   "frequency",
+	"identity":    "identity",
+	"power":       "watts.power",
+	"computed":    "raw",
+	"count":       "count",
+	"duration":    "seconds.duration",
+	"percentage":  "percentage",
+	"raw":         "raw",
+	"status":      "status",
+	"temperature": "temperature",
+	"voltage":     "voltage",
+}
+
+// declarationKeys are the keys of a declaration that are not device data.
+var declarationKeys = map[string]bool{"kind": true, "output": true, "info": true}
+
+// DeclaredDevices creates the synse devices declared by OID in a
+// dynamicRegistration entry. The devices refer to the agent instead of
+// holding its connection settings.
+func DeclaredDevices(data map[string]interface{}) ([]*sdk.DeviceConfig, error) {
+	declarations, ok := data[DevicesKey]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := declarations.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%v should be a list, got %T", DevicesKey, declarations)
+	}
+
+	location, err := agentLocation(data)
+	if err != nil {
+		return nil, err
+	}
+	agentID := core.AgentID(data)
+	model, _ := data["model"].(string)
+	cfg := &sdk.DeviceConfig{
+		SchemeVersion: sdk.SchemeVersion{Version: "1.0"},
+		Locations:     []*sdk.LocationConfig{location},
+		Devices:       []*sdk.DeviceKind{},
+	}
+	kinds := map[string]*sdk.DeviceKind{}
+
+	for i, item := range list {
+		declaration, err := stringMap(item)
+		if err != nil {
+			return nil, fmt.Errorf("%v[%d]: %v", DevicesKey, i, err)
+		}
+		kindName, output, instance, err := declaredInstance(declaration, agentID)
+		if err != nil {
+			return nil, fmt.Errorf("%v[%d]: %v", DevicesKey, i, err)
+		}
+
+		key := kindName + "/" + output
+		kind, ok := kinds[key]
+		if !ok {
+			kind = &sdk.DeviceKind{
+				Name:      kindName,
+				Metadata:  map[string]string{},
+				Outputs:   []*sdk.DeviceOutput{{Type: output}},
+				Instances: []*sdk.DeviceInstance{},
+			}
+			if model != "" {
+				kind.Metadata["model"] = model
+			}
+			kinds[key] = kind
+			cfg.Devices = append(cfg.Devices, kind)
+		}
+		kind.Instances = append(kind.Instances, instance)
+	}
+	return []*sdk.DeviceConfig{cfg}, nil
+}
+
+// declaredInstance creates the device instance for a declaration.
+func declaredInstance(declaration map[string]interface{}, agentID string) (
+	kind string, output string, instance *sdk.DeviceInstance, err error) {
+
+	kind = "raw"
+	if k, ok := declaration["kind"]; ok {
+		kind = fmt.Sprint(k)
+	}
+	output, ok := kindOutputs[kind]
+	if !ok {
+		return "", "", nil, fmt.Errorf("unsupported kind %v", kind)
+	}
+	if o, ok := declaration["output"]; ok {
+		if kind != "raw" && kind != "computed" && fmt.Sprint(o) != output &&
+			!(kind == "duration" && fmt.Sprint(o) == "minutes.duration") {
+			return "", "", nil, fmt.Errorf("kind %v has output %v, not %v", kind, output, o)
+		}
+		output = fmt.Sprint(o)
+	}
+
+	var info string
+	if kind == "computed" {
+		expression, ok := declaration["expression"].(string)
+		if !ok || expression == "" {
+			return "", "", nil, fmt.Errorf("expression is required")
+		}
+		if _, ok = declaration["oid"]; ok {
+			return "", "", nil, fmt.Errorf("a computed device has an expression, not an oid")
+		}
+		if _, err = core.ParseExpression(expression); err != nil {
+			return "", "", nil, err
+		}
+		info = expression
+	} else {
+		oid, ok := declaration["oid"].(string)
+		if !ok || oid == "" {
+			return "", "", nil, fmt.Errorf("oid is required")
+		}
+		if _, err = core.ResolveOid(oid); err != nil {
+			return "", "", nil, err
+		}
+		info = core.OidName(oid)
+	}
+	if i, ok := declaration["info"]; ok {
+		info = fmt.Sprint(i)
+	}
+
+	deviceData := map[string]interface{}{core.AgentKey: agentID}
+	for key, value := range declaration {
+		if declarationKeys[key] {
+			continue
+		}
+		if key == "multiplier" {
+			if value, err = toFloat32(value); err != nil {
+				return "", "", nil, err
+			}
+		}
+		deviceData[key] = value
+	}
+
+	return kind, output, &sdk.DeviceInstance{
+		Info:     info,
+		Location: snmpLocation,
+		Data:     deviceData,
+	}, nil
+}
+
+// stringMap converts a map from YAML to a map with string keys.
+func stringMap(value interface{}) (map[string]interface{}, error) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, nil
+	case map[interface{}]interface{}:
+		converted := map[string]interface{}{}
+		for key, v := range m {
+			converted[fmt.Sprint(key)] = v
+		}
+		return converted, nil
+	}
+	return nil, fmt.Errorf("expected a map, got %T", value)
+}
+
+// toFloat32 converts a number from YAML to the float32 of enumerated
+// multipliers.
+func toFloat32(value interface{}) (float32, error) {
+	switch v := value.(type) {
+	case float32:
+		return v, nil
+	case float64:
+		return float32(v), nil
+	case int:
+		return float32(v), nil
+	}
+	return 0, fmt.Errorf("multiplier should be a number, got %T %v", value, value)
+}
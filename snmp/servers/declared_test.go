@@ -0,0 +1,122 @@
+package servers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// declaredAgent is an agent without a model with devices declared by OID, as
+// the YAML of the plugin configuration parses.
+func declaredAgent(devices ...map[interface{}]interface{}) map[string]interface{} {
+	list := []interface{}{}
+	for _, device := range devices {
+		list = append(list, device)
+	}
+	return emulator.AgentData(map[string]interface{}{
+		"agent":    "pdu-1",
+		DevicesKey: list,
+	})
+}
+
+// TestDeclaredDevices enumerates an agent with only declared devices.
+func TestDeclaredDevices(t *testing.T) {
+	data := declaredAgent(
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.5.0", "kind": "temperature", "multiplier": 0.1, "info": "ambient"},
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.1.0"},
+		map[interface{}]interface{}{"oid": ".1.3.6.1.2.1.1.3.0", "output": "temperature"},
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.6.0", "kind": "temperature", "multiplier": 1},
+	)
+
+	deviceConfigs, err := EnumerateDevices(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deviceConfigs) != 2 {
+		t.Fatalf("Expected the declared devices and the inventory, got %d device configs", len(deviceConfigs))
+	}
+	kinds := deviceConfigs[0].Devices
+	if len(kinds) != 3 {
+		t.Fatalf("Expected temperature, raw and raw temperature kinds, got %d", len(kinds))
+	}
+
+	temperature := kinds[0]
+	if temperature.Name != "temperature" || temperature.Outputs[0].Type != "temperature" ||
+		len(temperature.Instances) != 2 {
+		t.Fatalf("Expected two temperature devices, got %+v", temperature)
+	}
+	instance := temperature.Instances[0]
+	if instance.Info != "ambient" || instance.Data["multiplier"] != float32(0.1) ||
+		instance.Data[core.AgentKey] != "pdu-1" || instance.Data["endpoint"] != nil {
+		t.Fatalf("Expected the declared device with an agent reference, got %+v", instance)
+	}
+	if temperature.Instances[1].Data["multiplier"] != float32(1) {
+		t.Fatalf("Expected multiplier 1, got %+v", temperature.Instances[1].Data)
+	}
+
+	raw := kinds[1]
+	if raw.Name != "raw" || raw.Outputs[0].Type != "raw" || raw.Instances[0].Info != ".1.3.6.1.4.1.534.1.6.1.0" {
+		t.Fatalf("Expected a raw device named by its OID, got %+v", raw)
+	}
+	if kinds[2].Name != "raw" || kinds[2].Outputs[0].Type != "temperature" {
+		t.Fatalf("Expected a raw device with the temperature output, got %+v", kinds[2])
+	}
+
+	// Sort ordinals are in OID order.
+	if raw.Instances[0].SortOrdinal != 2 || kinds[2].Instances[0].SortOrdinal != 1 {
+		t.Fatalf("Expected sort ordinals 2 and 1, got %d and %d",
+			raw.Instances[0].SortOrdinal, kinds[2].Instances[0].SortOrdinal)
+	}
+}
+
+// TestDeclaredDevicesErrors checks bad declarations.
+func TestDeclaredDevicesErrors(t *testing.T) {
+	cases := []struct {
+		device   map[interface{}]interface{}
+		expected string
+	}{
+		{map[interface{}]interface{}{"kind": "raw"}, "oid is required"},
+		{map[interface{}]interface{}{"oid": ".1.3.6.1", "kind": "humidity"}, "unsupported kind humidity"},
+		{map[interface{}]interface{}{"oid": ".1.3.6.1", "kind": "voltage", "output": "current"}, "kind voltage has output voltage"},
+		{map[interface{}]interface{}{"oid": ".1.3.6.1", "multiplier": "x"}, "multiplier should be a number"},
+	}
+	for _, c := range cases {
+		_, err := DeclaredDevices(declaredAgent(c.device))
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Fatalf("Expected error %q for %v, got %v", c.expected, c.device, err)
+		}
+	}
+}
+
+// TestDeclaredComputedDevice declares a computed device.
+func TestDeclaredComputedDevice(t *testing.T) {
+	data := declaredAgent(
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.1.0"},
+		map[interface{}]interface{}{"kind": "computed", "expression": "sum({.1.3.6.1.2.1.33.1.3.3.1.5})", "output": "watts.power"},
+	)
+
+	deviceConfigs, err := EnumerateDevices(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	computed := deviceConfigs[0].Devices[1]
+	if computed.Name != "computed" || computed.Outputs[0].Type != "watts.power" {
+		t.Fatalf("Expected a computed device with the watts.power output, got %+v", computed)
+	}
+	instance := computed.Instances[0]
+	if instance.Info != "sum({.1.3.6.1.2.1.33.1.3.3.1.5})" || instance.SortOrdinal != 2 {
+		t.Fatalf("Expected the computed device named by its expression after the OID, got %+v", instance)
+	}
+
+	for _, device := range []map[interface{}]interface{}{
+		{"kind": "computed"},
+		{"kind": "computed", "expression": "sum(", "output": "watts.power"},
+		{"kind": "computed", "expression": "1", "oid": ".1.3.6.1"},
+	} {
+		if _, err = DeclaredDevices(declaredAgent(device)); err == nil {
+			t.Fatalf("Expected an error for %v", device)
+		}
+	}
+}
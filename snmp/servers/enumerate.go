@@ -0,0 +1,198 @@
+package servers
+
+import (
+	"fmt"
+
+	logger "github.com/Sirupsen/logrus"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// EnumerateDevices enumerates the synse devices of the SNMP server in a
+// dynamicRegistration entry, including the devices declared by OID, and sets
+// their sort ordinals in OID order, followed by the alarms and the inventory
+// device of the agent. This is the plugin's device enumerator. snmpctl
+// enumerate calls it for a dry run.
+func EnumerateDevices(data map[string]interface{}) (deviceConfigs []*sdk.DeviceConfig, err error) {
+	fields, tags, err := metadataConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	naming, err := namingConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the MIB from the configuration still. An agent without a model only
+	// has the devices declared by OID.
+	// Factory class for initializing servers via config is TODO:
+	if model, _ := data["model"].(string); model != "" {
+		logger.Info("SNMP Plugin initializing UPS.")
+		pxgmsUps, err := NewPxgmsUps(data)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create NewPxgmUps: %v", err)
+		}
+		logger.Infof("Initialized PxgmsUps: %+v\n", pxgmsUps)
+		deviceConfigs = pxgmsUps.DeviceConfigs
+	}
+
+	declared, err := DeclaredDevices(data)
+	if err != nil {
+		return nil, err
+	}
+	deviceConfigs = append(deviceConfigs, declared...)
+
+	// First get a map of each OID to each device instance.
+	oidMap, oidList, computed, err := mapOidsToInstances(deviceConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an OidTrie and sort it.
+	oidTrie, err := core.NewOidTrie(&oidList)
+	if err != nil {
+		return nil, err
+	}
+	sorted, err := oidTrie.Sort()
+	if err != nil {
+		return nil, err
+	}
+
+	// Shim in the sort ordinal to the DeviceInstance Data.
+	for ordinal := 0; ordinal < len(sorted); ordinal++ { // Zero based in list.
+		oidMap[sorted[ordinal].ToString].SortOrdinal = int32(ordinal + 1) // One based sort ordinal.
+	}
+
+	// Computed devices and enumerated alarms sort after the devices with OIDs
+	// in enumeration order. A configured alarm may not have the name of an
+	// enumerated alarm on the same device.
+	ordinal := int32(len(sorted))
+	alarmed := map[string]bool{}
+	for _, instance := range computed {
+		ordinal++
+		instance.SortOrdinal = ordinal
+		if alarmOid, ok := instance.Data["alarm_oid"]; ok {
+			numericOid, err := core.ResolveOid(fmt.Sprint(alarmOid))
+			if err != nil {
+				return nil, err
+			}
+			alarmed[alarmRuleKey(numericOid, instance)] = true
+		}
+	}
+
+	// Alarms are on the devices above and sort after them in config order.
+	alarms, err := AlarmDevices(data, oidMap, alarmed)
+	if err != nil {
+		return nil, err
+	}
+	for _, alarm := range alarms {
+		for _, instance := range alarm.Devices[0].Instances {
+			ordinal++
+			instance.SortOrdinal = ordinal
+		}
+	}
+	deviceConfigs = append(deviceConfigs, alarms...)
+
+	// The inventory is read once, for the inventory device, the metadata and
+	// the naming templates.
+	var inventory *core.Inventory
+	if inventoryEnabled(data) || needsInventory(fields) || naming != nil {
+		if inventory, err = readInventory(data); err != nil {
+			return nil, err
+		}
+	}
+
+	// The inventory device of the agent sorts last.
+	inventoryDevices, err := InventoryDevices(data, inventory)
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range inventoryDevices {
+		ordinal++
+		cfg.Devices[0].Instances[0].SortOrdinal = ordinal
+	}
+	deviceConfigs = append(deviceConfigs, inventoryDevices...)
+
+	AddMetadata(deviceConfigs, data, fields, tags, inventory)
+	if err = applyNaming(deviceConfigs, data, naming, tags, inventory); err != nil {
+		return nil, err
+	}
+	return deviceConfigs, nil
+}
+
+// mapOidsToInstances creates a map of SNMP OID to device instances and a list
+// of OIDs so that Synse can determine the sort order for SNMP devices in a
+// scan. In this case the OID is a string. Computed devices have an expression
+// instead of an OID, and enumerated alarms the OID of another device. Both are
+// returned separately. Row devices have several OIDs.
+func mapOidsToInstances(deviceConfigs []*sdk.DeviceConfig) (
+	oidMap map[string]*sdk.DeviceInstance, oidList []string, computed []*sdk.DeviceInstance, err error) {
+
+	oidMap = map[string]*sdk.DeviceInstance{}
+	// Iterate from the device config to each device instance.
+	for i := 0; i < len(deviceConfigs); i++ {
+		devices := deviceConfigs[i].Devices
+		for j := 0; j < len(devices); j++ {
+			device := devices[j]
+			for k := 0; k < len(device.Instances); k++ {
+				instance := device.Instances[k]
+				if _, ok := instance.Data["expression"]; ok {
+					computed = append(computed, instance)
+					continue
+				}
+				if _, ok := instance.Data["alarm_oid"]; ok {
+					computed = append(computed, instance)
+					continue
+				}
+
+				// A row device sorts by its lowest OID. Each of its OIDs maps to
+				// it, so that alarms can be on its columns.
+				if rowOids := core.RowOids(instance.Data); len(rowOids) > 0 {
+					sortOid, err := core.RowSortOid(instance.Data)
+					if err != nil {
+						return nil, []string{}, nil, err
+					}
+					for _, oid := range rowOids {
+						numericOid, err := core.ResolveOid(oid)
+						if err != nil {
+							return nil, []string{}, nil, err
+						}
+						if _, exists := oidMap[numericOid]; exists {
+							return nil, []string{}, nil, fmt.Errorf(
+								"oid %v already exists. Should not be duplicated", core.OidDisplay(numericOid))
+						}
+						oidMap[numericOid] = instance
+					}
+					oidList = append(oidList, sortOid)
+					continue
+				}
+
+				// Check for errors and add the oid as a string and a pointer to the
+				// instance to the map value.
+				oidData, ok := instance.Data["oid"]
+				if !ok {
+					return nil, []string{}, nil, fmt.Errorf(
+						"oid is not a key in instance data, instance.Data: %+v", core.RedactData(instance.Data))
+				}
+				oidStr, ok := oidData.(string)
+				if !ok {
+					return nil, []string{}, nil, fmt.Errorf("oid data is not a string, %T, %+v", oidData, oidData)
+				}
+				// The oid may be symbolic. Sort on the numeric OID.
+				oidStr, err = core.ResolveOid(oidStr)
+				if err != nil {
+					return nil, []string{}, nil, err
+				}
+				_, exists := oidMap[oidStr]
+				if exists {
+					return nil, []string{}, nil, fmt.Errorf(
+						"oid %v already exists. Should not be duplicated", core.OidDisplay(oidStr))
+				}
+				oidMap[oidStr] = instance
+				oidList = append(oidList, oidStr)
+			}
+		}
+	}
+	return oidMap, oidList, computed, nil
+}
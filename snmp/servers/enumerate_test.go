@@ -0,0 +1,128 @@
+package servers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestEnumerateDevices checks that every device has a sort ordinal in OID
+// order.
+func TestEnumerateDevices(t *testing.T) {
+	data := emulator.AgentData(map[string]interface{}{
+		"model": "PXGMS UPS + EATON 93PM",
+	})
+
+	deviceConfigs, err := EnumerateDevices(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oids := map[int32]string{}
+	computed := map[int32]string{}
+	var inventoryOrdinal, lastOrdinal int32
+	for _, deviceConfig := range deviceConfigs {
+		for _, kind := range deviceConfig.Devices {
+			for _, instance := range kind.Instances {
+				if _, ok := oids[instance.SortOrdinal]; ok || instance.SortOrdinal < 1 {
+					t.Fatalf("Bad or duplicate sort ordinal %d for %v", instance.SortOrdinal, instance.Info)
+				}
+				if instance.SortOrdinal > lastOrdinal {
+					lastOrdinal = instance.SortOrdinal
+				}
+				if kind.Name == "inventory" {
+					inventoryOrdinal = instance.SortOrdinal
+					continue
+				}
+				oid, ok := instance.Data["oid"].(string)
+				if !ok {
+					computed[instance.SortOrdinal] = instance.Info
+					continue
+				}
+				oids[instance.SortOrdinal] = oid
+			}
+		}
+	}
+	if len(oids) == 0 {
+		t.Fatal("Expected devices")
+	}
+
+	// The emulator UPS has three lines, so it has computed devices, and the
+	// alarm on upsInputLineBads. They sort after the devices with OIDs.
+	if len(computed) != 6 {
+		t.Fatalf("Expected 6 computed devices, got %v", computed)
+	}
+	for ordinal := range computed {
+		if ordinal <= int32(len(oids)) {
+			t.Fatalf("Expected computed device %v after the %d devices with OIDs", computed[ordinal], len(oids))
+		}
+	}
+
+	// The inventory of the agent sorts last.
+	if inventoryOrdinal == 0 || inventoryOrdinal != lastOrdinal {
+		t.Fatalf("Expected the inventory device last, got ordinal %d of %d", inventoryOrdinal, lastOrdinal)
+	}
+
+	for ordinal := int32(2); ordinal <= int32(len(oids)); ordinal++ {
+		previous, _ := core.NewOid(oids[ordinal-1])
+		oid, _ := core.NewOid(oids[ordinal])
+		if core.CompareOids(previous.ToSlice, oid.ToSlice) >= 0 {
+			t.Fatalf("Expected %v before %v", oids[ordinal-1], oids[ordinal])
+		}
+	}
+}
+
+// TestEnumerateRowDevices enumerates a device per UPS line, with an alarm on
+// one of the columns of a line.
+func TestEnumerateRowDevices(t *testing.T) {
+	data := emulator.AgentData(map[string]interface{}{
+		"model":            "PXGMS UPS + EATON 93PM",
+		core.RowDevicesKey: true,
+		AlarmsKey: []interface{}{
+			map[interface{}]interface{}{"oid": ".1.3.6.1.2.1.33.1.4.4.1.2.1", "condition": "below", "critical": 100},
+		},
+	})
+
+	deviceConfigs, err := EnumerateDevices(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []string
+	ordinals := map[int32]bool{}
+	for _, deviceConfig := range deviceConfigs {
+		for _, kind := range deviceConfig.Devices {
+			for _, instance := range kind.Instances {
+				if ordinals[instance.SortOrdinal] || instance.SortOrdinal < 1 {
+					t.Fatalf("Bad or duplicate sort ordinal %d for %v", instance.SortOrdinal, instance.Info)
+				}
+				ordinals[instance.SortOrdinal] = true
+				if kind.Name == "row" {
+					rows = append(rows, instance.Info)
+				}
+				oid, _ := instance.Data["oid"].(string)
+				if strings.HasPrefix(oid, ".1.3.6.1.2.1.33.1.3.3.1.") || strings.HasPrefix(oid, ".1.3.6.1.2.1.33.1.4.4.1.") ||
+					strings.HasPrefix(oid, ".1.3.6.1.2.1.33.1.5.3.1.") {
+					t.Fatalf("Expected no device per column with row devices, got %v", instance.Info)
+				}
+			}
+		}
+	}
+
+	// The emulator UPS has three input, output and bypass lines.
+	expected := []string{
+		"upsInputLine0", "upsInputLine1", "upsInputLine2",
+		"upsOutputLine0", "upsOutputLine1", "upsOutputLine2",
+		"upsBypassLine0", "upsBypassLine1", "upsBypassLine2",
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected row devices %v, got %v", expected, rows)
+	}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Fatalf("Expected row devices %v, got %v", expected, rows)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package servers
+
+import (
+	"encoding/json"
+
+	logger "github.com/Sirupsen/logrus"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/devices"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// InventoryKey is the dynamicRegistration key that turns the inventory device
+// of an agent off when false:
+//
+//	inventory: false
+const InventoryKey = "inventory"
+
+// inventoryEnabled returns whether or not the agent in a dynamicRegistration
+// entry has an inventory device.
+func inventoryEnabled(data map[string]interface{}) bool {
+	enabled, ok := data[InventoryKey].(bool)
+	return !ok || enabled
+}
+
+// readInventory reads the identity of the agent in a dynamicRegistration
+// entry and its equipment. An agent that can not be read has no inventory
+// rather than failing enumeration, since its declared devices need no
+// request.
+func readInventory(data map[string]interface{}) (*core.Inventory, error) {
+	agent, err := core.RegisterAgent(data)
+	if err != nil {
+		return nil, err
+	}
+	inventory, err := core.ReadInventory(agent.Client)
+	if err != nil {
+		logger.Warnf("SNMP Plugin unable to read the inventory of agent %v: %v", agent.ID, err)
+		return nil, nil
+	}
+	return inventory, nil
+}
+
+// InventoryDevices creates the inventory device of the agent in a
+// dynamicRegistration entry, with the inventory read at this enumeration.
+// There is none if inventory is nil.
+func InventoryDevices(data map[string]interface{}, inventory *core.Inventory) ([]*sdk.DeviceConfig, error) {
+	if inventory == nil || !inventoryEnabled(data) {
+		return nil, nil
+	}
+	record, err := json.Marshal(inventory)
+	if err != nil {
+		return nil, err
+	}
+	location, err := agentLocation(data)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := &sdk.DeviceKind{
+		Name:     "inventory",
+		Metadata: map[string]string{},
+		Outputs:  []*sdk.DeviceOutput{{Type: "inventory"}},
+		Instances: []*sdk.DeviceInstance{{
+			Info:     "inventory",
+			Location: snmpLocation,
+			Data: map[string]interface{}{
+				core.AgentKey:        core.AgentID(data),
+				devices.InventoryKey: string(record),
+			},
+		}},
+	}
+	if model, _ := data["model"].(string); model != "" {
+		kind.Metadata["model"] = model
+	}
+
+	return []*sdk.DeviceConfig{{
+		SchemeVersion: sdk.SchemeVersion{Version: "1.0"},
+		Locations:     []*sdk.LocationConfig{location},
+		Devices:       []*sdk.DeviceKind{kind},
+	}}, nil
+}
@@ -0,0 +1,48 @@
+package servers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/devices"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestInventoryDevices creates the inventory device of an agent.
+func TestInventoryDevices(t *testing.T) {
+	data := declaredAgent()
+	read, err := readInventory(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deviceConfigs, err := InventoryDevices(data, read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deviceConfigs) != 1 || len(deviceConfigs[0].Devices) != 1 {
+		t.Fatalf("Expected one inventory device config, got %+v", deviceConfigs)
+	}
+	kind := deviceConfigs[0].Devices[0]
+	if kind.Name != "inventory" || kind.Outputs[0].Type != "inventory" || len(kind.Instances) != 1 {
+		t.Fatalf("Expected one inventory device, got %+v", kind)
+	}
+	instance := kind.Instances[0]
+	if instance.Data[core.AgentKey] != "pdu-1" {
+		t.Fatalf("Expected an agent reference, got %+v", instance.Data)
+	}
+
+	var inventory core.Inventory
+	if err = json.Unmarshal([]byte(instance.Data[devices.InventoryKey].(string)), &inventory); err != nil {
+		t.Fatal(err)
+	}
+	if inventory.Model != "PXGMS UPS + EATON 93PM" || inventory.System.Name != "PowerXpert-00-20-85-F1-56-DE" {
+		t.Fatalf("Unexpected inventory %+v", inventory)
+	}
+
+	// The inventory can be turned off.
+	data[InventoryKey] = false
+	if deviceConfigs, err = InventoryDevices(data, read); err != nil || len(deviceConfigs) != 0 {
+		t.Fatalf("Expected no inventory device, got %+v, %v", deviceConfigs, err)
+	}
+}
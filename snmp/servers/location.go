@@ -0,0 +1,91 @@
+package servers
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// snmpLocation is the name of the location of the devices of an agent, as in
+// the UPS-MIB device configs.
+const snmpLocation = "snmp-location"
+
+// agentLocation is the location of the devices of the agent in a
+// dynamicRegistration entry: the rack and board of the entry, site and ups
+// by default.
+//
+//	rack: dc-1
+//	board: ups-1
+//
+// The UPS-MIB devices, declared devices, alarms and the inventory of an agent
+// are all at this location. Agents with devices on the same OIDs need a rack
+// or board of their own. See CheckDeviceIDs.
+func agentLocation(data map[string]interface{}) (*sdk.LocationConfig, error) {
+	location := map[string]interface{}{"rack": "site", "board": "ups"}
+	for _, key := range []string{"rack", "board"} {
+		if value, ok := data[key]; ok {
+			location[key] = value
+		}
+	}
+	rack, board, err := core.GetRackAndBoard(location)
+	if err != nil {
+		return nil, err
+	}
+	return &sdk.LocationConfig{
+		Name:  snmpLocation,
+		Rack:  &sdk.LocationData{Name: rack},
+		Board: &sdk.LocationData{Name: board},
+	}, nil
+}
+
+// CheckDeviceIDs returns an error if a device of an agent would have the same
+// synse device ID as a device of another registered agent. A device ID is made
+// from the rack and board of the device and its identifier.
+func CheckDeviceIDs(agentID string, deviceConfigs []*sdk.DeviceConfig,
+	identifier func(map[string]interface{}) string) error {
+
+	owners := map[string]string{}
+	for _, agent := range core.Agents() {
+		if agent.ID == agentID {
+			continue
+		}
+		enumerated, _ := agent.Devices()
+		for id := range deviceIDs(enumerated, identifier) {
+			owners[id] = agent.ID
+		}
+	}
+	for id, info := range deviceIDs(deviceConfigs, identifier) {
+		if owner, ok := owners[id]; ok {
+			return fmt.Errorf("device %v of agent %v has the same rack, board and identifier as a device of agent %v: %v. "+
+				"Set the rack or board of one of the agents", info, agentID, owner, id)
+		}
+	}
+	return nil
+}
+
+// deviceIDs returns the info of each device by rack, board and identifier.
+func deviceIDs(deviceConfigs []*sdk.DeviceConfig, identifier func(map[string]interface{}) string) map[string]string {
+	ids := map[string]string{}
+	for _, cfg := range deviceConfigs {
+		locations := map[string]*sdk.LocationConfig{}
+		for _, location := range cfg.Locations {
+			locations[location.Name] = location
+		}
+		for _, kind := range cfg.Devices {
+			for _, instance := range kind.Instances {
+				var rack, board string
+				if location, ok := locations[instance.Location]; ok {
+					if location.Rack != nil {
+						rack = location.Rack.Name
+					}
+					if location.Board != nil {
+						board = location.Board.Name
+					}
+				}
+				ids[fmt.Sprintf("%v/%v/%v", rack, board, identifier(instance.Data))] = instance.Info
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,103 @@
+package servers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestAgentLocation checks that every device of an agent is at the rack and
+// board of its entry.
+func TestAgentLocation(t *testing.T) {
+	data := declaredAgent(map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.5.0"})
+	data["model"] = "PXGMS UPS + EATON 93PM"
+	data["rack"] = "dc-1"
+	data["board"] = "ups-2"
+	data[AlarmsKey] = []interface{}{
+		map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.5.0", "critical": 40},
+	}
+
+	deviceConfigs, err := EnumerateDevices(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]bool{}
+	for _, deviceConfig := range deviceConfigs {
+		if len(deviceConfig.Locations) != 1 {
+			t.Fatalf("Expected one location, got %+v", deviceConfig.Locations)
+		}
+		location := deviceConfig.Locations[0]
+		if location.Name != snmpLocation || location.Rack.Name != "dc-1" || location.Board.Name != "ups-2" {
+			t.Fatalf("Expected %v at rack dc-1 and board ups-2, got %+v %+v %+v",
+				snmpLocation, location, location.Rack, location.Board)
+		}
+		for _, kind := range deviceConfig.Devices {
+			kinds[kind.Name] = true
+		}
+	}
+	for _, kind := range []string{"voltage", "raw", "alarm", "inventory"} {
+		if !kinds[kind] {
+			t.Fatalf("Expected UPS-MIB, declared, alarm and inventory devices, got kinds %v", kinds)
+		}
+	}
+
+	// The default location.
+	location, err := agentLocation(map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location.Rack.Name != "site" || location.Board.Name != "ups" {
+		t.Fatalf("Expected rack site and board ups, got %+v %+v", location.Rack, location.Board)
+	}
+
+	_, err = agentLocation(map[string]interface{}{"rack": 3})
+	if err == nil || !strings.Contains(err.Error(), "rack is not a string") {
+		t.Fatalf("Expected a rack error, got %v", err)
+	}
+}
+
+// TestCheckDeviceIDs checks that two agents declaring the same OID at the
+// same rack and board collide, and do not at another board.
+func TestCheckDeviceIDs(t *testing.T) {
+	identifier := func(data map[string]interface{}) string { return fmt.Sprint(data["oid"]) }
+	declared := map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.5.0", "info": "ambient"}
+
+	first := declaredAgent(declared)
+	first["agent"] = "collision-1"
+	agent, err := core.RegisterAgent(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deviceConfigs, err := DeclaredDevices(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent.SetDevices(deviceConfigs)
+
+	// The same agent may be enumerated again.
+	if err = CheckDeviceIDs("collision-1", deviceConfigs, identifier); err != nil {
+		t.Fatal(err)
+	}
+
+	second := declaredAgent(declared)
+	second["agent"] = "collision-2"
+	deviceConfigs, err = DeclaredDevices(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CheckDeviceIDs("collision-2", deviceConfigs, identifier)
+	if err == nil || !strings.Contains(err.Error(), "agent collision-1") {
+		t.Fatalf("Expected a collision with agent collision-1, got %v", err)
+	}
+
+	second["board"] = "pdu-2"
+	deviceConfigs, err = DeclaredDevices(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = CheckDeviceIDs("collision-2", deviceConfigs, identifier); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,172 @@
+package servers
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// MetadataKey is the dynamicRegistration key of the identity fields of an
+// agent to copy into the metadata of each of its device kinds, in addition
+// to the model. TagsKey is the key of static metadata for the agent:
+//
+//	metadata: [manufacturer, firmware, endpoint, sysName, upsName, line]
+//	tags:
+//	  site: dc-1
+//	  feed: A
+//
+// The fields are:
+//
+//	agent: The agent ID.
+//	endpoint: The endpoint and port of the agent.
+//	manufacturer, firmware, serialNumber: From the inventory.
+//	sysName: SNMPv2-MIB::sysName.0.
+//	upsName: UPS-MIB::upsIdentName.0.
+//	line: The line of a UPS input, output or bypass device, such as 1.
+//	  Devices of different lines are in different device kinds.
+//
+// Fields that the agent does not have are left out.
+const (
+	MetadataKey = "metadata"
+	TagsKey     = "tags"
+)
+
+// metadataFields are the fields that can be in MetadataKey.
+var metadataFields = map[string]bool{
+	"agent":        true,
+	"endpoint":     true,
+	"manufacturer": true,
+	"firmware":     true,
+	"serialNumber": true,
+	"sysName":      true,
+	"upsName":      true,
+	"line":         true,
+}
+
+// metadataConfig parses the metadata fields and the tags of a
+// dynamicRegistration entry.
+func metadataConfig(data map[string]interface{}) (fields map[string]bool, tags map[string]string, err error) {
+	fields = map[string]bool{}
+	if value, ok := data[MetadataKey]; ok {
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("%v should be a list, got %T", MetadataKey, value)
+		}
+		for _, item := range list {
+			field := fmt.Sprint(item)
+			if !metadataFields[field] {
+				return nil, nil, fmt.Errorf("%v: unknown field %v", MetadataKey, field)
+			}
+			fields[field] = true
+		}
+	}
+
+	tags = map[string]string{}
+	if value, ok := data[TagsKey]; ok {
+		m, err := stringMap(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%v: %v", TagsKey, err)
+		}
+		for key, tag := range m {
+			if metadataFields[key] || key == "model" {
+				return nil, nil, fmt.Errorf("%v: %v is a metadata field", TagsKey, key)
+			}
+			tags[key] = fmt.Sprint(tag)
+		}
+	}
+	return fields, tags, nil
+}
+
+// needsInventory returns whether or not metadata fields are read from the
+// inventory of the agent.
+func needsInventory(fields map[string]bool) bool {
+	for _, field := range []string{"manufacturer", "firmware", "serialNumber", "sysName", "upsName"} {
+		if fields[field] {
+			return true
+		}
+	}
+	return false
+}
+
+// AddMetadata adds the metadata fields and the tags of a dynamicRegistration
+// entry to the device kinds of the agent. inventory may be nil. Kinds are
+// split by line when the line field is configured.
+func AddMetadata(deviceConfigs []*sdk.DeviceConfig, data map[string]interface{},
+	fields map[string]bool, tags map[string]string, inventory *core.Inventory) {
+
+	metadata := map[string]string{}
+	for key, tag := range tags {
+		metadata[key] = tag
+	}
+	values := map[string]string{
+		"agent":    core.AgentID(data),
+		"endpoint": fmt.Sprintf("%v:%v", data["endpoint"], data["port"]),
+	}
+	if inventory != nil {
+		values["manufacturer"] = inventory.Manufacturer
+		values["firmware"] = inventory.Firmware
+		values["serialNumber"] = inventory.SerialNumber
+		values["sysName"] = inventory.System.Name
+		if inventory.Ups != nil {
+			values["upsName"] = inventory.Ups.Name
+		}
+	}
+	for field := range fields {
+		if values[field] != "" {
+			metadata[field] = values[field]
+		}
+	}
+
+	for _, cfg := range deviceConfigs {
+		var kinds []*sdk.DeviceKind
+		for _, kind := range cfg.Devices {
+			if kind.Metadata == nil {
+				kind.Metadata = map[string]string{}
+			}
+			for key, value := range metadata {
+				kind.Metadata[key] = value
+			}
+			if fields["line"] {
+				kinds = append(kinds, splitLines(kind)...)
+			} else {
+				kinds = append(kinds, kind)
+			}
+		}
+		cfg.Devices = kinds
+	}
+}
+
+// splitLines splits a device kind into a kind per line, in the order of the
+// first instance of each line, with the line in the metadata. Instances
+// without a line stay in the kind.
+func splitLines(kind *sdk.DeviceKind) []*sdk.DeviceKind {
+	var kinds []*sdk.DeviceKind
+	lines := map[string]*sdk.DeviceKind{}
+	for _, instance := range kind.Instances {
+		line := ""
+		if value, ok := instance.Data[core.LineKey]; ok {
+			line = fmt.Sprint(value)
+		}
+		lineKind, ok := lines[line]
+		if !ok {
+			copied := *kind
+			lineKind = &copied
+			lineKind.Metadata = map[string]string{}
+			lineKind.Instances = []*sdk.DeviceInstance{}
+			for key, value := range kind.Metadata {
+				lineKind.Metadata[key] = value
+			}
+			if line != "" {
+				lineKind.Metadata["line"] = line
+			}
+			lines[line] = lineKind
+			kinds = append(kinds, lineKind)
+		}
+		lineKind.Instances = append(lineKind.Instances, instance)
+	}
+	if len(kinds) == 0 {
+		return []*sdk.DeviceKind{kind}
+	}
+	return kinds
+}
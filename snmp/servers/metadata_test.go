@@ -0,0 +1,79 @@
+package servers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestAddMetadata enumerates the emulator UPS with identity metadata, a kind
+// per line and tags.
+func TestAddMetadata(t *testing.T) {
+	data := emulator.AgentData(map[string]interface{}{
+		"agent":     "ups-1",
+		"model":     "PXGMS UPS + EATON 93PM",
+		MetadataKey: []interface{}{"manufacturer", "firmware", "endpoint", "sysName", "upsName", "line"},
+		TagsKey:     map[interface{}]interface{}{"site": "dc-1", "feed": "A"},
+	})
+
+	deviceConfigs, err := EnumerateDevices(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := map[string]bool{}
+	for _, deviceConfig := range deviceConfigs {
+		for _, kind := range deviceConfig.Devices {
+			metadata := kind.Metadata
+			if metadata["site"] != "dc-1" || metadata["feed"] != "A" ||
+				metadata["manufacturer"] != "Eaton Corporation" || metadata["firmware"] != "INV: 1.44.0000" ||
+				metadata["endpoint"] != "127.0.0.1:1024" || metadata["sysName"] != "PowerXpert-00-20-85-F1-56-DE" ||
+				metadata["upsName"] != "ID: EM111UXX06, Msg: 9PL15N0000E40R2" {
+				t.Fatalf("Unexpected metadata of kind %v: %v", kind.Name, metadata)
+			}
+
+			// The instances of a kind are on the line of the kind, if any.
+			for _, instance := range kind.Instances {
+				line, _ := instance.Data[core.LineKey].(string)
+				if line != metadata["line"] {
+					t.Fatalf("Expected %v on line %q, got line %q", instance.Info, metadata["line"], line)
+				}
+			}
+			if _, ok := metadata["phase"]; ok {
+				t.Fatalf("Expected no phase, got %v", metadata)
+			}
+			if line := metadata["line"]; line != "" {
+				lines[line] = true
+			}
+		}
+	}
+
+	// The emulator UPS has three lines.
+	if len(lines) != 3 || !lines["1"] || !lines["2"] || !lines["3"] {
+		t.Fatalf("Expected lines 1, 2 and 3, got %v", lines)
+	}
+}
+
+// TestAddMetadataErrors checks bad metadata configuration.
+func TestAddMetadataErrors(t *testing.T) {
+	cases := []struct {
+		key      string
+		value    interface{}
+		expected string
+	}{
+		{MetadataKey, "line", "should be a list"},
+		{MetadataKey, []interface{}{"color"}, "unknown field color"},
+		{TagsKey, []interface{}{"site"}, "expected a map"},
+		{TagsKey, map[interface{}]interface{}{"line": "1"}, "line is a metadata field"},
+	}
+	for _, c := range cases {
+		data := declaredAgent()
+		data[c.key] = c.value
+		_, err := EnumerateDevices(data)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Fatalf("Expected error %q for %v, got %v", c.expected, c.value, err)
+		}
+	}
+}
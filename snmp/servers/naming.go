@@ -0,0 +1,178 @@
+package servers
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// NamingKey is the dynamicRegistration key of the templates for the info,
+// rack and board of the devices of an agent:
+//
+//	naming:
+//	  info: "{{.SysName}}-{{.Info}}"
+//	  rack: "{{.Tags.site}}"
+//	  board: "ups-{{.Agent}}"
+//
+// The templates are Go text/template templates of a DeviceName. Devices
+// without a template keep their info, or the rack and board of the agent. Devices with another
+// rack or board are in a location named snmp-location/rack/board.
+const NamingKey = "naming"
+
+// DeviceName are the fields of the naming templates for a device.
+type DeviceName struct {
+	Info    string            // The info the device was enumerated with, such as upsOutputVoltage0.
+	Kind    string            // The device kind, such as voltage.
+	Agent   string            // The agent ID.
+	SysName string            // SNMPv2-MIB::sysName.0.
+	Ups     core.InventoryUps // UPS-MIB::upsIdent, such as .Ups.Name.
+	Table   string            // The table of the device, such as UPS-MIB-UPS-Output-Table.
+	Row     string            // The row of the device in its table, from 0.
+	Line    string            // The line of a UPS input, output or bypass device, from 1.
+	Rack    string            // The rack the device was enumerated with.
+	Board   string            // The board the device was enumerated with.
+	Tags    map[string]string // The tags of the agent. See TagsKey.
+}
+
+// namingTemplates are the parsed templates of NamingKey. A template is nil if
+// it is not configured.
+type namingTemplates struct {
+	info  *template.Template
+	rack  *template.Template
+	board *template.Template
+}
+
+// namingConfig parses the naming templates of a dynamicRegistration entry. It
+// returns nil if there are none.
+func namingConfig(data map[string]interface{}) (*namingTemplates, error) {
+	value, ok := data[NamingKey]
+	if !ok {
+		return nil, nil
+	}
+	m, err := stringMap(value)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", NamingKey, err)
+	}
+
+	templates := &namingTemplates{}
+	for key, text := range m {
+		var target **template.Template
+		switch key {
+		case "info":
+			target = &templates.info
+		case "rack":
+			target = &templates.rack
+		case "board":
+			target = &templates.board
+		default:
+			return nil, fmt.Errorf("%v: unknown key %v. Expected info, rack or board", NamingKey, key)
+		}
+		*target, err = template.New(key).Option("missingkey=error").Parse(fmt.Sprint(text))
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", NamingKey, err)
+		}
+	}
+	return templates, nil
+}
+
+// applyNaming sets the info and location of the devices of an agent from the
+// naming templates. inventory may be nil.
+func applyNaming(deviceConfigs []*sdk.DeviceConfig, data map[string]interface{},
+	templates *namingTemplates, tags map[string]string, inventory *core.Inventory) error {
+
+	if templates == nil {
+		return nil
+	}
+	name := DeviceName{Agent: core.AgentID(data), Tags: tags}
+	if inventory != nil {
+		name.SysName = inventory.System.Name
+		if inventory.Ups != nil {
+			name.Ups = *inventory.Ups
+		}
+	}
+
+	for _, cfg := range deviceConfigs {
+		locations := map[string]*sdk.LocationConfig{}
+		for _, location := range cfg.Locations {
+			locations[location.Name] = location
+		}
+
+		for _, kind := range cfg.Devices {
+			for _, instance := range kind.Instances {
+				name.Info = instance.Info
+				name.Kind = kind.Name
+				name.Table = dataString(instance.Data, "table_name")
+				name.Row = dataString(instance.Data, "row")
+				name.Line = dataString(instance.Data, core.LineKey)
+				name.Rack, name.Board = "", ""
+				if location, ok := locations[instance.Location]; ok {
+					if location.Rack != nil {
+						name.Rack = location.Rack.Name
+					}
+					if location.Board != nil {
+						name.Board = location.Board.Name
+					}
+				}
+
+				info, err := executeNaming(templates.info, name, name.Info)
+				if err != nil {
+					return err
+				}
+				rack, err := executeNaming(templates.rack, name, name.Rack)
+				if err != nil {
+					return err
+				}
+				board, err := executeNaming(templates.board, name, name.Board)
+				if err != nil {
+					return err
+				}
+				instance.Info = info
+
+				if rack == name.Rack && board == name.Board {
+					continue
+				}
+				locationName := fmt.Sprintf("snmp-location/%v/%v", rack, board)
+				if _, ok := locations[locationName]; !ok {
+					location := &sdk.LocationConfig{
+						Name:  locationName,
+						Rack:  &sdk.LocationData{Name: rack},
+						Board: &sdk.LocationData{Name: board},
+					}
+					locations[locationName] = location
+					cfg.Locations = append(cfg.Locations, location)
+				}
+				instance.Location = locationName
+			}
+		}
+	}
+	return nil
+}
+
+// executeNaming executes a naming template, or returns the default if there
+// is no template. The result may not be empty.
+func executeNaming(t *template.Template, name DeviceName, defaultValue string) (string, error) {
+	if t == nil {
+		return defaultValue, nil
+	}
+	var buffer bytes.Buffer
+	if err := t.Execute(&buffer, name); err != nil {
+		return "", fmt.Errorf("%v: %v", NamingKey, err)
+	}
+	if buffer.Len() == 0 {
+		return "", fmt.Errorf("%v: %v of %v is empty", NamingKey, t.Name(), name.Info)
+	}
+	return buffer.String(), nil
+}
+
+// dataString returns a device data value as a string, or the empty string if
+// there is none.
+func dataString(data map[string]interface{}, key string) string {
+	value, ok := data[key]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
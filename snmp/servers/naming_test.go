@@ -0,0 +1,83 @@
+package servers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vapor-ware/synse-sdk/sdk"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+)
+
+// TestNaming enumerates the emulator UPS with naming templates.
+func TestNaming(t *testing.T) {
+	data := emulator.AgentData(map[string]interface{}{
+		"agent": "ups-1",
+		"model": "PXGMS UPS + EATON 93PM",
+		TagsKey: map[interface{}]interface{}{"site": "dc-1"},
+		NamingKey: map[interface{}]interface{}{
+			"info":  "{{.SysName}}/{{.Info}}",
+			"rack":  "{{.Tags.site}}",
+			"board": "{{if .Line}}line-{{.Line}}{{else}}{{.Board}}{{end}}",
+		},
+	})
+
+	deviceConfigs, err := EnumerateDevices(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var voltage *sdk.DeviceInstance
+	var voltageConfig *sdk.DeviceConfig
+	for _, deviceConfig := range deviceConfigs {
+		locations := map[string]*sdk.LocationConfig{}
+		for _, location := range deviceConfig.Locations {
+			locations[location.Name] = location
+		}
+		for _, kind := range deviceConfig.Devices {
+			for _, instance := range kind.Instances {
+				if !strings.HasPrefix(instance.Info, "PowerXpert-00-20-85-F1-56-DE/") {
+					t.Fatalf("Expected the info prefixed by sysName, got %v", instance.Info)
+				}
+				location, ok := locations[instance.Location]
+				if !ok || location.Rack.Name != "dc-1" {
+					t.Fatalf("Expected %v in rack dc-1, got %+v", instance.Info, location)
+				}
+				if instance.Info == "PowerXpert-00-20-85-F1-56-DE/upsOutputVoltage0" {
+					voltage, voltageConfig = instance, deviceConfig
+				}
+			}
+		}
+	}
+
+	// The first output line is on board line-1.
+	if voltage == nil || voltage.Location != "snmp-location/dc-1/line-1" {
+		t.Fatalf("Expected upsOutputVoltage0 on board line-1, got %+v", voltage)
+	}
+	for _, location := range voltageConfig.Locations {
+		if location.Name == voltage.Location && location.Board.Name != "line-1" {
+			t.Fatalf("Expected board line-1, got %+v", location.Board)
+		}
+	}
+}
+
+// TestNamingErrors checks bad naming templates.
+func TestNamingErrors(t *testing.T) {
+	cases := []struct {
+		naming   interface{}
+		expected string
+	}{
+		{"{{.Info}}", "expected a map"},
+		{map[interface{}]interface{}{"name": "{{.Info}}"}, "unknown key name"},
+		{map[interface{}]interface{}{"info": "{{.Info"}, "unclosed action"},
+		{map[interface{}]interface{}{"rack": "{{.Tags.site}}"}, "site"},
+		{map[interface{}]interface{}{"board": "{{.Row}}"}, "board of"},
+	}
+	for _, c := range cases {
+		data := declaredAgent(map[interface{}]interface{}{"oid": ".1.3.6.1.4.1.534.1.6.1.0"})
+		data[NamingKey] = c.naming
+		_, err := EnumerateDevices(data)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Fatalf("Expected error %q for %v, got %v", c.expected, c.naming, err)
+		}
+	}
+}
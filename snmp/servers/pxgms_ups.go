@@ -32,7 +32,7 @@ type PxgmsUps struct {
 // version:v3
 func NewPxgmsUps(data map[string]interface{}) (ups *PxgmsUps, err error) { // nolint: gocyclo
 
-	logger.Debug("NewPxgmUps start. data: %+v", data)
+	logger.Debugf("NewPxgmUps start. data: %+v", core.RedactData(data))
 
 	// FIXME (etd): Sorta a hack just to get things moving, but adding in a check against
 	// the model here. There could probably be something at a higher level that checks this
@@ -41,45 +41,58 @@ func NewPxgmsUps(data map[string]interface{}) (ups *PxgmsUps, err error) { // no
 	// now only support one and only one model.
 	// We intend to be able to share SNMP MIBs across models and this won't work at all.
 	model := data["model"].(string)
-	logger.Debugf("model is: [%m]", model)
+	logger.Debugf("model is: [%v]", model)
 	if !strings.HasPrefix(model, "PXGMS UPS") {
 		return nil, fmt.Errorf("only PXGMS UPS models are currently supported")
 	}
 
-	// Create the SNMP DeviceConfig,
-	snmpDeviceConfig, err := core.GetDeviceConfig(data)
+	// Register the agent. This parses the SNMP DeviceConfig and creates the
+	// client that the devices read with.
+	agent, err := core.RegisterAgent(data)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("snmpDeviceConfig: %+v\n", snmpDeviceConfig)
-
-	// Create SNMP client.
-	snmpClient, err := core.NewSnmpClient(snmpDeviceConfig)
-	if err != nil {
-		return nil, err
-	}
-	fmt.Printf("snmpClient: %+v\n", snmpClient)
+	snmpDeviceConfig := agent.DeviceConfig
+	snmpClient := agent.Client
+	logger.Debugf("snmpDeviceConfig: %+v", snmpDeviceConfig)
+	logger.Debugf("snmpClient: %+v", snmpClient)
 
 	// Create SnmpServerBase.
 	snmpServerBase, err := core.NewSnmpServerBase(snmpClient, snmpDeviceConfig)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("snmpServerBase: %+v\n", snmpServerBase)
+	logger.Debugf("snmpServerBase: %+v", snmpServerBase)
 
 	// Create the UpsMib.
 	upsMib, err := mibs.NewUpsMib(snmpServerBase)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("upsMib: %+v\n", upsMib)
+	logger.Debugf("upsMib: %+v", upsMib)
+	agent.AddMib(upsMib.SnmpMib)
 
 	// Enumerate the mib.
-	snmpDevices, err := upsMib.EnumerateDevices(
-		map[string]interface{}{"rack": "site", "board": "ups"})
+	devicesLocation, err := agentLocation(data)
+	if err != nil {
+		return nil, err
+	}
+	location := map[string]interface{}{
+		"rack":  devicesLocation.Rack.Name,
+		"board": devicesLocation.Board.Name,
+	}
+	if core.IsRowDevices(data) {
+		location[core.RowDevicesKey] = true
+	}
+	snmpDevices, err := upsMib.EnumerateDevices(location)
+	if err != nil {
+		return nil, err
+	}
+	computedDevices, err := upsMib.EnumerateComputedDevices(location)
 	if err != nil {
 		return nil, err
 	}
+	snmpDevices = append(snmpDevices, computedDevices...)
 
 	// Output enumerated devices.
 	for i := 0; i < len(snmpDevices); i++ {
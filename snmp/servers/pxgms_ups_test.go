@@ -3,6 +3,8 @@ package servers
 import (
 	"fmt"
 	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
 )
 
 // TestPxgmsUps is the first PxgmsUps test.
@@ -10,17 +12,7 @@ func TestPxgmsUps(t *testing.T) {
 	fmt.Printf("TestPxgmUps start\n")
 	fmt.Printf("t: %+v\n", t)
 
-	data := make(map[string]interface{})
-	data["contextName"] = "public"
-	data["endpoint"] = "127.0.0.1"
-	data["userName"] = "simulator"
-	data["privacyProtocol"] = "AES"
-	data["privacyPassphrase"] = "privatus"
-	data["port"] = 1024
-	data["authenticationProtocol"] = "SHA"
-	data["authenticationPassphrase"] = "auctoritas"
-	data["model"] = "PXGMS UPS + EATON 93PM"
-	data["version"] = "v3"
+	data := emulator.AgentData(map[string]interface{}{"model": "PXGMS UPS + EATON 93PM"})
 
 	pxgmsUps, err := NewPxgmsUps(data)
 	if err != nil {
@@ -0,0 +1,736 @@
+package simulator
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/soniah/gosnmp"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// Mode is how the agent answers requests. Scenarios change the mode to
+// exercise the plugin's error handling.
+type Mode int
+
+const (
+	// ModeNormal answers requests.
+	ModeNormal Mode = iota
+	// ModeTimeout drops all requests so that clients time out.
+	ModeTimeout
+	// ModeAuthFailure answers SNMPv3 requests with a wrong digest report and
+	// drops SNMPv1 and SNMPv2c requests like a bad community.
+	ModeAuthFailure
+)
+
+// ParseMode parses a mode name, normal, timeout or auth-failure.
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "normal":
+		return ModeNormal, nil
+	case "timeout":
+		return ModeTimeout, nil
+	case "auth-failure":
+		return ModeAuthFailure, nil
+	}
+	return ModeNormal, fmt.Errorf("Unknown mode [%v]", name)
+}
+
+// SNMP error status values, RFC 3416.
+const (
+	errorNoSuchName  = 2 // SNMPv1
+	errorBadValue    = 3 // SNMPv1
+	errorGenErr      = 5
+	errorWrongType   = 7
+	errorNoCreation  = 11
+	errorNotWritable = 17
+)
+
+// OIDs the agent sends that are not MIB data.
+const (
+	oidSysUpTime           = ".1.3.6.1.2.1.1.3.0"
+	oidSnmpTrapOID         = ".1.3.6.1.6.3.1.1.4.1.0"
+	oidSnmpUnknownContexts = ".1.3.6.1.6.3.12.1.1.5.0"
+)
+
+// maxBulkVarbinds bounds a GetBulk response so it fits in a UDP datagram.
+const maxBulkVarbinds = 200
+
+// DefaultCommunity is the community of traps and the context of scenarios
+// when none is configured.
+const DefaultCommunity = "public"
+
+// defaultEngineID is the engine ID when none is configured, RFC 3411 text
+// format under the net-snmp enterprise number.
+var defaultEngineID = append([]byte{0x80, 0x00, 0x1f, 0x88, 0x04}, "synse-simulator"...)
+
+// Config is the configuration for an Agent.
+type Config struct {
+	// Data is the MIB data by SNMPv3 context name. SNMPv1 and SNMPv2c
+	// communities select the data the same way, like snmpsim.
+	Data map[string][]gosnmp.SnmpPDU
+	// Users are the SNMPv3 users.
+	Users []User
+	// EngineID is the SNMPv3 authoritative engine ID. Empty is a default.
+	EngineID []byte
+	// EngineBoots is the SNMPv3 engine boots. Zero is one.
+	EngineBoots int64
+	// Writable are the OIDs that accept SET requests. Everything under each
+	// OID is writable.
+	Writable []string
+	// TrapTargets are host:port addresses that get SNMPv2c traps.
+	TrapTargets []string
+	// TrapCommunity is the community of the traps. Empty is public.
+	TrapCommunity string
+}
+
+// record is an OID and its value.
+type record struct {
+	oid []uint64
+	pdu gosnmp.SnmpPDU
+}
+
+// dataset is the records of a context in OID order.
+type dataset []record
+
+// search returns the index of the first record at or after the OID.
+func (data dataset) search(oid []uint64) int {
+	return sort.Search(len(data), func(i int) bool {
+		return core.CompareOids(data[i].oid, oid) >= 0
+	})
+}
+
+// Agent is an SNMP agent that serves data from snmpwalk or snmprec files
+// over UDP. It supports SNMPv1, SNMPv2c and SNMPv3 with the USM
+// authentication and privacy protocols of the plugin.
+type Agent struct {
+	config   Config
+	engineID []byte
+	boots    int64
+	started  time.Time
+	users    map[string]*usmUser
+	writable [][]uint64
+
+	mutex    sync.RWMutex
+	contexts map[string]dataset
+	mode     Mode
+	stats    map[string]uint
+
+	saltCounter uint64 // Accessed atomically.
+	requestID   uint32 // Accessed atomically.
+
+	conn *net.UDPConn
+	done chan struct{}
+	wait sync.WaitGroup
+}
+
+// NewAgent creates an Agent. It does not listen until Listen is called.
+func NewAgent(config Config) (*Agent, error) {
+	agent := &Agent{
+		config:   config,
+		engineID: config.EngineID,
+		boots:    config.EngineBoots,
+		started:  time.Now(),
+		users:    map[string]*usmUser{},
+		contexts: map[string]dataset{},
+		stats:    map[string]uint{},
+		done:     make(chan struct{}),
+	}
+	if len(agent.engineID) == 0 {
+		agent.engineID = defaultEngineID
+	}
+	if agent.boots == 0 {
+		agent.boots = 1
+	}
+	if agent.config.TrapCommunity == "" {
+		agent.config.TrapCommunity = DefaultCommunity
+	}
+
+	for _, user := range config.Users {
+		localized, err := newUsmUser(user, agent.engineID)
+		if err != nil {
+			return nil, err
+		}
+		agent.users[user.Name] = localized
+	}
+
+	for _, writable := range config.Writable {
+		oid, err := core.NewOid(writable)
+		if err != nil {
+			return nil, fmt.Errorf("Bad writable OID %v: %v", writable, err)
+		}
+		agent.writable = append(agent.writable, oid.ToSlice)
+	}
+
+	for contextName, pdus := range config.Data {
+		data := make(dataset, 0, len(pdus))
+		for _, pdu := range pdus {
+			oid, err := core.NewOid(pdu.Name)
+			if err != nil {
+				return nil, err
+			}
+			pdu.Name = "." + oid.ToString
+			data = append(data, record{oid: oid.ToSlice, pdu: pdu})
+		}
+		sort.SliceStable(data, func(i, j int) bool {
+			return core.CompareOids(data[i].oid, data[j].oid) < 0
+		})
+		agent.contexts[contextName] = data
+	}
+	return agent, nil
+}
+
+// Listen starts serving on a UDP address such as 127.0.0.1:1024. Port zero
+// picks a free port, see Addr.
+func (agent *Agent) Listen(address string) error {
+	udpAddress, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return err
+	}
+	agent.conn, err = net.ListenUDP("udp", udpAddress)
+	if err != nil {
+		return err
+	}
+	log.Infof("SNMP simulator listening on %v", agent.conn.LocalAddr())
+
+	agent.wait.Add(1)
+	go agent.serve()
+	return nil
+}
+
+// Addr is the address the agent listens on.
+func (agent *Agent) Addr() *net.UDPAddr {
+	return agent.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// Done is closed when the agent is closed.
+func (agent *Agent) Done() <-chan struct{} {
+	return agent.done
+}
+
+// Close stops the agent.
+func (agent *Agent) Close() error {
+	select {
+	case <-agent.done:
+		return nil
+	default:
+	}
+	close(agent.done)
+	if agent.conn == nil {
+		return nil
+	}
+	err := agent.conn.Close()
+	agent.wait.Wait()
+	return err
+}
+
+// SetMode sets how the agent answers requests.
+func (agent *Agent) SetMode(mode Mode) {
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+	agent.mode = mode
+}
+
+// Mode gets how the agent answers requests.
+func (agent *Agent) Mode() Mode {
+	agent.mutex.RLock()
+	defer agent.mutex.RUnlock()
+	return agent.mode
+}
+
+// Get gets the value of an OID in a context.
+func (agent *Agent) Get(contextName string, oid string) (pdu gosnmp.SnmpPDU, found bool, err error) {
+	parsed, err := core.NewOid(oid)
+	if err != nil {
+		return pdu, false, err
+	}
+	agent.mutex.RLock()
+	defer agent.mutex.RUnlock()
+	data := agent.contexts[contextName]
+	i := data.search(parsed.ToSlice)
+	if i < len(data) && core.CompareOids(data[i].oid, parsed.ToSlice) == 0 {
+		return data[i].pdu, true, nil
+	}
+	return pdu, false, nil
+}
+
+// Set sets the value of an OID in a context, adding the OID or the context if
+// they do not exist.
+func (agent *Agent) Set(contextName string, pdu gosnmp.SnmpPDU) error {
+	oid, err := core.NewOid(pdu.Name)
+	if err != nil {
+		return err
+	}
+	pdu.Name = "." + oid.ToString
+	if _, err = encodeVarbind(pdu); err != nil {
+		return err
+	}
+
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+	agent.contexts[contextName] = agent.contexts[contextName].set(record{oid: oid.ToSlice, pdu: pdu})
+	return nil
+}
+
+// Delete removes an OID from a context. It is not an error if the OID does
+// not exist.
+func (agent *Agent) Delete(contextName string, oid string) error {
+	parsed, err := core.NewOid(oid)
+	if err != nil {
+		return err
+	}
+
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+	data := agent.contexts[contextName]
+	i := data.search(parsed.ToSlice)
+	if i < len(data) && core.CompareOids(data[i].oid, parsed.ToSlice) == 0 {
+		agent.contexts[contextName] = append(data[:i], data[i+1:]...)
+	}
+	return nil
+}
+
+// set replaces or inserts a record.
+func (data dataset) set(r record) dataset {
+	i := data.search(r.oid)
+	if i < len(data) && core.CompareOids(data[i].oid, r.oid) == 0 {
+		data[i] = r
+		return data
+	}
+	data = append(data, record{})
+	copy(data[i+1:], data[i:])
+	data[i] = r
+	return data
+}
+
+// SendTrap sends an SNMPv2c trap to the trap targets. sysUpTime.0 and
+// snmpTrapOID.0 are added before the varbinds.
+func (agent *Agent) SendTrap(trapOid string, varbinds []gosnmp.SnmpPDU) error {
+	trap := &pdu{
+		tag:       tagTrapV2,
+		requestID: int64(atomic.AddUint32(&agent.requestID, 1) & 0x7fffffff),
+		varbinds: append([]gosnmp.SnmpPDU{
+			{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(time.Since(agent.started) / (10 * time.Millisecond))},
+			{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: trapOid},
+		}, varbinds...),
+	}
+	raw, err := encodeCommunityMessage(version2c, agent.config.TrapCommunity, trap)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range agent.config.TrapTargets {
+		conn, err := net.Dial("udp", target)
+		if err != nil {
+			return err
+		}
+		_, err = conn.Write(raw)
+		err2 := conn.Close()
+		if err != nil {
+			return err
+		}
+		if err2 != nil {
+			return err2
+		}
+	}
+	return nil
+}
+
+// engineTime is the SNMPv3 engine time in seconds.
+func (agent *Agent) engineTime() int64 {
+	return int64(time.Since(agent.started) / time.Second)
+}
+
+// serve reads and answers requests until the connection is closed.
+func (agent *Agent) serve() {
+	defer agent.wait.Done()
+	buffer := make([]byte, 65535)
+	for {
+		n, address, err := agent.conn.ReadFromUDP(buffer)
+		if err != nil {
+			select {
+			case <-agent.done:
+			default:
+				log.Errorf("SNMP simulator read failed: %v", err)
+			}
+			return
+		}
+		response, err := agent.handle(append([]byte{}, buffer[:n]...))
+		if err != nil {
+			log.Debugf("SNMP simulator dropped request from %v: %v", address, err)
+			continue
+		}
+		if response == nil {
+			continue
+		}
+		if _, err = agent.conn.WriteToUDP(response, address); err != nil {
+			log.Errorf("SNMP simulator write to %v failed: %v", address, err)
+		}
+	}
+}
+
+// handle answers a request. A nil response without error drops the request.
+func (agent *Agent) handle(raw []byte) ([]byte, error) {
+	mode := agent.Mode()
+	if mode == ModeTimeout {
+		return nil, nil
+	}
+
+	msg, err := decodeMessage(raw)
+	if err != nil {
+		return nil, err
+	}
+	if msg.version == version3 {
+		return agent.handleV3(raw, msg, mode)
+	}
+
+	if mode == ModeAuthFailure {
+		return nil, nil
+	}
+	response, err := agent.process(msg.version, msg.community, msg.pdu)
+	if err != nil || response == nil {
+		return nil, err
+	}
+	return encodeCommunityMessage(msg.version, msg.community, response)
+}
+
+// handleV3 authenticates, decrypts and answers an SNMPv3 request, RFC 3414
+// section 3.2.
+func (agent *Agent) handleV3(raw []byte, msg *message, mode Mode) ([]byte, error) { // nolint: gocyclo
+	// Discovery.
+	if !bytes.Equal(msg.engineID, agent.engineID) {
+		return agent.report(msg, nil, usmStatsUnknownEngineIDs)
+	}
+
+	user, ok := agent.users[msg.userName]
+	if !ok {
+		return agent.report(msg, nil, usmStatsUnknownUserNames)
+	}
+	if msg.msgFlags&flagPriv != 0 && msg.msgFlags&flagAuth == 0 {
+		return nil, fmt.Errorf("Invalid msgFlags 0x%02x", msg.msgFlags)
+	}
+	if (msg.msgFlags&flagAuth != 0 && user.AuthenticationProtocol == core.NoAuthentication) ||
+		(msg.msgFlags&flagPriv != 0 && user.PrivacyProtocol == core.NoPrivacy) {
+		return agent.report(msg, nil, usmStatsUnsupportedSecLevels)
+	}
+
+	if msg.msgFlags&flagAuth != 0 {
+		if mode == ModeAuthFailure || len(msg.authParameters) != authParametersLength {
+			return agent.report(msg, nil, usmStatsWrongDigests)
+		}
+		zeroed := append([]byte{}, raw...)
+		copy(zeroed[msg.authOffset:msg.authOffset+authParametersLength], make([]byte, authParametersLength))
+		if !hmac.Equal(user.digest(zeroed), msg.authParameters) {
+			return agent.report(msg, nil, usmStatsWrongDigests)
+		}
+
+		timeDifference := msg.engineTime - agent.engineTime()
+		if msg.engineBoots != agent.boots || timeDifference > timeWindow || timeDifference < -timeWindow {
+			return agent.report(msg, user, usmStatsNotInTimeWindows)
+		}
+	}
+
+	if msg.encrypted != nil {
+		if msg.msgFlags&flagPriv == 0 {
+			return nil, fmt.Errorf("Encrypted scoped PDU without privacy")
+		}
+		if err := decryptScopedPDU(user, msg); err != nil {
+			return agent.report(msg, nil, usmStatsDecryptionErrors)
+		}
+	}
+
+	if !agent.hasContext(msg.contextName) {
+		return agent.report(msg, nil, oidSnmpUnknownContexts)
+	}
+	response, err := agent.process(msg.version, msg.contextName, msg.pdu)
+	if err != nil || response == nil {
+		return nil, err
+	}
+	return agent.encodeV3Response(msg, user, msg.msgFlags&(flagAuth|flagPriv), response)
+}
+
+// report answers an SNMPv3 request with a Report PDU and increments the
+// counter. The report is authenticated if a user is given.
+func (agent *Agent) report(msg *message, user *usmUser, counter string) ([]byte, error) {
+	if msg.msgFlags&flagReportable == 0 {
+		return nil, fmt.Errorf("Not reportable: %v", counter)
+	}
+
+	agent.mutex.Lock()
+	agent.stats[counter]++
+	count := agent.stats[counter]
+	agent.mutex.Unlock()
+
+	var requestID int64
+	if msg.pdu != nil {
+		requestID = msg.pdu.requestID
+	}
+	report := &pdu{
+		tag:       tagReport,
+		requestID: requestID,
+		varbinds:  []gosnmp.SnmpPDU{{Name: counter, Type: gosnmp.Counter32, Value: count}},
+	}
+
+	var flags byte
+	if user != nil {
+		flags = flagAuth
+	}
+	return agent.encodeV3Response(msg, user, flags, report)
+}
+
+// encodeV3Response encodes, encrypts and authenticates an SNMPv3 response at
+// the security level of the flags.
+func (agent *Agent) encodeV3Response(request *message, user *usmUser, flags byte, response *pdu) ([]byte, error) {
+	msg := &message{
+		msgID:          request.msgID,
+		msgMaxSize:     request.msgMaxSize,
+		msgFlags:       flags,
+		engineID:       agent.engineID,
+		engineBoots:    agent.boots,
+		engineTime:     agent.engineTime(),
+		userName:       request.userName,
+		privParameters: []byte{},
+	}
+
+	msgData, err := encodeScopedPDU(agent.engineID, request.contextName, response)
+	if err != nil {
+		return nil, err
+	}
+	if flags&flagPriv != 0 {
+		counter := atomic.AddUint64(&agent.saltCounter, 1)
+		ciphertext, salt, err := user.encrypt(msgData, counter, msg.engineBoots, msg.engineTime)
+		if err != nil {
+			return nil, err
+		}
+		msg.privParameters = salt
+		msgData = encodeOctets(ciphertext)
+	}
+
+	raw, authOffset := encodeV3Message(msg, msgData)
+	if flags&flagAuth != 0 {
+		copy(raw[authOffset:], user.digest(raw))
+	}
+	return raw, nil
+}
+
+// hasContext returns true if there is data for a context.
+func (agent *Agent) hasContext(contextName string) bool {
+	agent.mutex.RLock()
+	defer agent.mutex.RUnlock()
+	_, ok := agent.contexts[contextName]
+	return ok
+}
+
+// process answers a request PDU from the data of a context. A nil response
+// drops the request.
+func (agent *Agent) process(version int64, contextName string, request *pdu) (*pdu, error) {
+	if request.tag == tagSetRequest {
+		return agent.set(version, contextName, request)
+	}
+
+	agent.mutex.RLock()
+	defer agent.mutex.RUnlock()
+	data, ok := agent.contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("No data for community or context [%v]", contextName)
+	}
+
+	response := &pdu{tag: tagResponse, requestID: request.requestID}
+	oids, err := requestOids(request)
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.tag {
+	case tagGetRequest:
+		for i, oid := range oids {
+			pdu, found := data.get(oid)
+			if !found && version == version1 {
+				return errorResponse(request, errorNoSuchName, i), nil
+			}
+			response.varbinds = append(response.varbinds, pdu)
+		}
+
+	case tagGetNextRequest:
+		for i, oid := range oids {
+			pdu, found := data.next(oid)
+			if !found && version == version1 {
+				return errorResponse(request, errorNoSuchName, i), nil
+			}
+			response.varbinds = append(response.varbinds, pdu)
+		}
+
+	case tagGetBulkRequest:
+		if version == version1 {
+			return errorResponse(request, errorGenErr, 0), nil
+		}
+		response.varbinds = data.bulk(oids, int(request.errorStatus), int(request.errorIndex))
+
+	default:
+		return nil, fmt.Errorf("Unsupported request PDU type 0x%02x", request.tag)
+	}
+	return response, nil
+}
+
+// set answers a SetRequest. All varbinds are checked before any is set.
+func (agent *Agent) set(version int64, contextName string, request *pdu) (*pdu, error) {
+	oids, err := requestOids(request)
+	if err != nil {
+		return nil, err
+	}
+
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+	data, ok := agent.contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("No data for community or context [%v]", contextName)
+	}
+
+	for i, oid := range oids {
+		if !agent.isWritable(oid) {
+			if version == version1 {
+				return errorResponse(request, errorNoSuchName, i), nil
+			}
+			return errorResponse(request, errorNotWritable, i), nil
+		}
+		existing, found := data.get(oid)
+		if !found {
+			if version == version1 {
+				return errorResponse(request, errorNoSuchName, i), nil
+			}
+			return errorResponse(request, errorNoCreation, i), nil
+		}
+		_, err := encodeVarbind(request.varbinds[i])
+		if existing.Type != request.varbinds[i].Type || err != nil {
+			if version == version1 {
+				return errorResponse(request, errorBadValue, i), nil
+			}
+			return errorResponse(request, errorWrongType, i), nil
+		}
+	}
+
+	for i, oid := range oids {
+		data = data.set(record{oid: oid, pdu: request.varbinds[i]})
+	}
+	agent.contexts[contextName] = data
+	return &pdu{tag: tagResponse, requestID: request.requestID, varbinds: request.varbinds}, nil
+}
+
+// isWritable returns true if SET is allowed on an OID.
+func (agent *Agent) isWritable(oid []uint64) bool {
+	for _, writable := range agent.writable {
+		if core.CompareOids(oid, writable) == 0 || core.HasOidPrefix(oid, writable) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestOids parses the OIDs of the varbinds in a request.
+func requestOids(request *pdu) ([][]uint64, error) {
+	oids := make([][]uint64, len(request.varbinds))
+	for i, varbind := range request.varbinds {
+		oid, err := core.NewOid(varbind.Name)
+		if err != nil {
+			return nil, err
+		}
+		oids[i] = oid.ToSlice
+	}
+	return oids, nil
+}
+
+// errorResponse is a response with an error status for the varbind at index,
+// which is zero based. The varbinds are the request's.
+func errorResponse(request *pdu, status int64, index int) *pdu {
+	return &pdu{
+		tag:         tagResponse,
+		requestID:   request.requestID,
+		errorStatus: status,
+		errorIndex:  int64(index + 1),
+		varbinds:    request.varbinds,
+	}
+}
+
+// get gets an OID. If it is not found the result is NoSuchObject or
+// NoSuchInstance.
+func (data dataset) get(oid []uint64) (gosnmp.SnmpPDU, bool) {
+	i := data.search(oid)
+	if i < len(data) && core.CompareOids(data[i].oid, oid) == 0 {
+		return data[i].pdu, true
+	}
+
+	name := oidName(oid)
+	object := oid[:len(oid)-1]
+	if (i > 0 && core.HasOidPrefix(data[i-1].oid, object)) ||
+		(i < len(data) && core.HasOidPrefix(data[i].oid, object)) {
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.NoSuchInstance}, false
+	}
+	return gosnmp.SnmpPDU{Name: name, Type: gosnmp.NoSuchObject}, false
+}
+
+// nextIndex returns the index of the first record after an OID.
+func (data dataset) nextIndex(oid []uint64) int {
+	i := data.search(oid)
+	if i < len(data) && core.CompareOids(data[i].oid, oid) == 0 {
+		i++
+	}
+	return i
+}
+
+// next gets the first OID after an OID. If there is none the result is
+// EndOfMibView.
+func (data dataset) next(oid []uint64) (gosnmp.SnmpPDU, bool) {
+	if i := data.nextIndex(oid); i < len(data) {
+		return data[i].pdu, true
+	}
+	return gosnmp.SnmpPDU{Name: oidName(oid), Type: gosnmp.EndOfMibView}, false
+}
+
+// bulk answers a GetBulk, RFC 3416 section 4.2.3.
+func (data dataset) bulk(oids [][]uint64, nonRepeaters int, maxRepetitions int) (varbinds []gosnmp.SnmpPDU) {
+	if nonRepeaters < 0 {
+		nonRepeaters = 0
+	}
+	if nonRepeaters > len(oids) {
+		nonRepeaters = len(oids)
+	}
+	for _, oid := range oids[:nonRepeaters] {
+		pdu, _ := data.next(oid)
+		varbinds = append(varbinds, pdu)
+	}
+
+	repeaters := append([][]uint64{}, oids[nonRepeaters:]...)
+	for repetition := 0; repetition < maxRepetitions && len(repeaters) > 0; repetition++ {
+		if len(varbinds)+len(repeaters) > maxBulkVarbinds {
+			break
+		}
+		ended := true
+		for i, oid := range repeaters {
+			next := data.nextIndex(oid)
+			if next >= len(data) {
+				varbinds = append(varbinds, gosnmp.SnmpPDU{Name: oidName(oid), Type: gosnmp.EndOfMibView})
+				continue
+			}
+			varbinds = append(varbinds, data[next].pdu)
+			repeaters[i] = data[next].oid
+			ended = false
+		}
+		if ended {
+			break
+		}
+	}
+	return varbinds
+}
+
+// oidName formats an OID like gosnmp.
+func oidName(oid []uint64) string {
+	name, _ := core.NewOidFromSlice(oid) // nolint: gas
+	return name.ToString
+}
@@ -0,0 +1,369 @@
+package simulator
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/soniah/gosnmp"
+)
+
+// This file contains the subset of BER, X.690, needed for SNMP messages. The
+// gosnmp Asn1BER values are the BER tags, so PDU types convert directly.
+
+// BER tags used in SNMP messages that are not gosnmp.Asn1BER values.
+const (
+	tagSequence = 0x30
+)
+
+// PDU tags, RFC 3416.
+const (
+	tagGetRequest     = 0xa0
+	tagGetNextRequest = 0xa1
+	tagResponse       = 0xa2
+	tagSetRequest     = 0xa3
+	tagTrapV1         = 0xa4
+	tagGetBulkRequest = 0xa5
+	tagInformRequest  = 0xa6
+	tagTrapV2         = 0xa7
+	tagReport         = 0xa8
+)
+
+// berReader reads BER TLVs from a buffer. Offsets are into the full message
+// so that the USM authentication parameters can be located for HMAC.
+type berReader struct {
+	data []byte // The full message.
+	pos  int    // The next TLV.
+	end  int    // The end of this reader's content.
+}
+
+// newBerReader creates a berReader over a full message.
+func newBerReader(data []byte) *berReader {
+	return &berReader{data: data, end: len(data)}
+}
+
+// done returns true if there are no more TLVs.
+func (reader *berReader) done() bool {
+	return reader.pos >= reader.end
+}
+
+// next reads the next TLV. start is the offset of the content.
+func (reader *berReader) next() (tag byte, content []byte, start int, err error) {
+	if reader.pos+2 > reader.end {
+		return 0, nil, 0, fmt.Errorf("Truncated BER at offset %d", reader.pos)
+	}
+	tag = reader.data[reader.pos]
+	length := int(reader.data[reader.pos+1])
+	start = reader.pos + 2
+	if length&0x80 != 0 {
+		octets := length & 0x7f
+		if octets == 0 || octets > 4 || start+octets > reader.end {
+			return 0, nil, 0, fmt.Errorf("Bad BER length at offset %d", reader.pos)
+		}
+		length = 0
+		for i := 0; i < octets; i++ {
+			length = length<<8 | int(reader.data[start+i])
+		}
+		start += octets
+	}
+	if length < 0 || start+length > reader.end {
+		return 0, nil, 0, fmt.Errorf("BER length %d overruns message at offset %d", length, reader.pos)
+	}
+	reader.pos = start + length
+	return tag, reader.data[start : start+length], start, nil
+}
+
+// expect reads the next TLV and checks the tag.
+func (reader *berReader) expect(expected byte) (content []byte, start int, err error) {
+	tag, content, start, err := reader.next()
+	if err != nil {
+		return nil, 0, err
+	}
+	if tag != expected {
+		return nil, 0, fmt.Errorf("Expected BER tag 0x%02x, got 0x%02x", expected, tag)
+	}
+	return content, start, nil
+}
+
+// enter reads a constructed TLV with the expected tag and returns a reader
+// over its content.
+func (reader *berReader) enter(expected byte) (*berReader, error) {
+	content, start, err := reader.expect(expected)
+	if err != nil {
+		return nil, err
+	}
+	return &berReader{data: reader.data, pos: start, end: start + len(content)}, nil
+}
+
+// integer reads an INTEGER.
+func (reader *berReader) integer() (int64, error) {
+	content, _, err := reader.expect(byte(gosnmp.Integer))
+	if err != nil {
+		return 0, err
+	}
+	return decodeInteger(content)
+}
+
+// octets reads an OCTET STRING.
+func (reader *berReader) octets() ([]byte, error) {
+	content, _, err := reader.expect(byte(gosnmp.OctetString))
+	return content, err
+}
+
+// decodeInteger decodes two's complement content.
+func decodeInteger(content []byte) (int64, error) {
+	if len(content) == 0 || len(content) > 8 {
+		return 0, fmt.Errorf("Bad INTEGER length %d", len(content))
+	}
+	value := int64(int8(content[0])) // Sign extend.
+	for _, b := range content[1:] {
+		value = value<<8 | int64(b)
+	}
+	return value, nil
+}
+
+// decodeUnsigned decodes unsigned content, which may have a leading zero.
+func decodeUnsigned(content []byte) (uint64, error) {
+	if len(content) == 0 || len(content) > 9 || (len(content) == 9 && content[0] != 0) {
+		return 0, fmt.Errorf("Bad unsigned length %d", len(content))
+	}
+	var value uint64
+	for _, b := range content {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
+
+// decodeOid decodes OBJECT IDENTIFIER content to a string with a leading
+// period, the way gosnmp names OIDs.
+func decodeOid(content []byte) (string, error) {
+	if len(content) == 0 {
+		return "", fmt.Errorf("Empty OBJECT IDENTIFIER")
+	}
+	var arcs []uint64
+	var arc uint64
+	for i, b := range content {
+		if arc > (1<<57)-1 {
+			return "", fmt.Errorf("OBJECT IDENTIFIER arc overflow")
+		}
+		arc = arc<<7 | uint64(b&0x7f)
+		if b&0x80 != 0 {
+			if i == len(content)-1 {
+				return "", fmt.Errorf("Truncated OBJECT IDENTIFIER")
+			}
+			continue
+		}
+		if len(arcs) == 0 {
+			// The first arc encodes the first two.
+			first := arc / 40
+			if first > 2 {
+				first = 2
+			}
+			arcs = append(arcs, first, arc-first*40)
+		} else {
+			arcs = append(arcs, arc)
+		}
+		arc = 0
+	}
+
+	var buffer strings.Builder
+	for _, arc := range arcs {
+		buffer.WriteString(".")
+		buffer.WriteString(strconv.FormatUint(arc, 10))
+	}
+	return buffer.String(), nil
+}
+
+// decodeVarbind decodes the value of a varbind to a gosnmp.SnmpPDU with the
+// same Go types as core.ParseSnmpWalk.
+func decodeVarbind(name string, tag byte, content []byte) (pdu gosnmp.SnmpPDU, err error) { // nolint: gocyclo
+	pdu.Name = name
+	pdu.Type = gosnmp.Asn1BER(tag)
+	switch pdu.Type {
+	case gosnmp.Integer:
+		var i int64
+		i, err = decodeInteger(content)
+		pdu.Value = int(i)
+	case gosnmp.OctetString, gosnmp.Opaque:
+		pdu.Value = append([]byte{}, content...)
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+		pdu.Value = nil
+	case gosnmp.ObjectIdentifier:
+		pdu.Value, err = decodeOid(content)
+	case gosnmp.IPAddress:
+		if len(content) != net.IPv4len {
+			return pdu, fmt.Errorf("Bad IpAddress length %d", len(content))
+		}
+		pdu.Value = net.IP(content).String()
+	case gosnmp.Counter32, gosnmp.Gauge32:
+		var u uint64
+		u, err = decodeUnsigned(content)
+		if u > 0xffffffff {
+			err = fmt.Errorf("%v overflow", pdu.Type)
+		}
+		pdu.Value = uint(u)
+	case gosnmp.TimeTicks:
+		var u uint64
+		u, err = decodeUnsigned(content)
+		if u > 0xffffffff {
+			err = fmt.Errorf("TimeTicks overflow")
+		}
+		pdu.Value = uint32(u)
+	case gosnmp.Counter64:
+		pdu.Value, err = decodeUnsigned(content)
+	default:
+		return pdu, fmt.Errorf("Unsupported varbind type 0x%02x", tag)
+	}
+	return pdu, err
+}
+
+// encodeLength encodes a BER definite length.
+func encodeLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+	var octets []byte
+	for ; length > 0; length >>= 8 {
+		octets = append([]byte{byte(length)}, octets...)
+	}
+	return append([]byte{0x80 | byte(len(octets))}, octets...)
+}
+
+// tlv encodes a TLV.
+func tlv(tag byte, content ...[]byte) []byte {
+	length := 0
+	for _, c := range content {
+		length += len(c)
+	}
+	result := append([]byte{tag}, encodeLength(length)...)
+	for _, c := range content {
+		result = append(result, c...)
+	}
+	return result
+}
+
+// encodeInteger encodes an INTEGER.
+func encodeInteger(value int64) []byte {
+	return tlv(byte(gosnmp.Integer), integerContent(value))
+}
+
+// integerContent is the minimal two's complement content of a value.
+func integerContent(value int64) []byte {
+	content := []byte{byte(value)}
+	for value >= 0x80 || value < -0x80 {
+		value >>= 8
+		content = append([]byte{byte(value)}, content...)
+	}
+	return content
+}
+
+// unsignedContent is the minimal content of an unsigned value, with a leading
+// zero if the high bit is set.
+func unsignedContent(value uint64) []byte {
+	content := []byte{byte(value)}
+	for value >= 0x80 {
+		value >>= 8
+		content = append([]byte{byte(value)}, content...)
+	}
+	if content[0] == 0 && len(content) > 1 && content[1]&0x80 == 0 {
+		content = content[1:]
+	}
+	return content
+}
+
+// encodeOctets encodes an OCTET STRING.
+func encodeOctets(value []byte) []byte {
+	return tlv(byte(gosnmp.OctetString), value)
+}
+
+// oidContent encodes an OBJECT IDENTIFIER string, with or without the
+// leading period.
+func oidContent(oid string) ([]byte, error) {
+	fields := strings.Split(strings.TrimPrefix(oid, "."), ".")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("OBJECT IDENTIFIER %v has fewer than two arcs", oid)
+	}
+	arcs := make([]uint64, len(fields))
+	for i, field := range fields {
+		arc, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Bad OBJECT IDENTIFIER %v", oid)
+		}
+		arcs[i] = arc
+	}
+	if arcs[0] > 2 || (arcs[0] < 2 && arcs[1] >= 40) {
+		return nil, fmt.Errorf("Bad OBJECT IDENTIFIER %v", oid)
+	}
+
+	arcs = append([]uint64{arcs[0]*40 + arcs[1]}, arcs[2:]...)
+	var content []byte
+	for _, arc := range arcs {
+		encoded := []byte{byte(arc & 0x7f)}
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			encoded = append([]byte{byte(arc&0x7f) | 0x80}, encoded...)
+		}
+		content = append(content, encoded...)
+	}
+	return content, nil
+}
+
+// encodeVarbind encodes a varbind, SEQUENCE { name, value }.
+func encodeVarbind(pdu gosnmp.SnmpPDU) ([]byte, error) { // nolint: gocyclo
+	name, err := oidContent(pdu.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte
+	switch pdu.Type {
+	case gosnmp.Integer:
+		i, ok := pdu.Value.(int)
+		if !ok {
+			return nil, fmt.Errorf("%v: Integer value is %T", pdu.Name, pdu.Value)
+		}
+		value = integerContent(int64(i))
+	case gosnmp.OctetString, gosnmp.Opaque:
+		switch v := pdu.Value.(type) {
+		case []byte:
+			value = v
+		case string:
+			value = []byte(v)
+		default:
+			return nil, fmt.Errorf("%v: %v value is %T", pdu.Name, pdu.Type, pdu.Value)
+		}
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+		value = []byte{}
+	case gosnmp.ObjectIdentifier:
+		oid, ok := pdu.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v: ObjectIdentifier value is %T", pdu.Name, pdu.Value)
+		}
+		if value, err = oidContent(oid); err != nil {
+			return nil, err
+		}
+	case gosnmp.IPAddress:
+		address, ok := pdu.Value.(string)
+		ip := net.ParseIP(address).To4()
+		if !ok || ip == nil {
+			return nil, fmt.Errorf("%v: Bad IpAddress %v", pdu.Name, pdu.Value)
+		}
+		value = ip
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64:
+		var u uint64
+		switch v := pdu.Value.(type) {
+		case uint:
+			u = uint64(v)
+		case uint32:
+			u = uint64(v)
+		case uint64:
+			u = v
+		default:
+			return nil, fmt.Errorf("%v: %v value is %T", pdu.Name, pdu.Type, pdu.Value)
+		}
+		value = unsignedContent(u)
+	default:
+		return nil, fmt.Errorf("%v: Unsupported type %v", pdu.Name, pdu.Type)
+	}
+	return tlv(tagSequence, tlv(byte(gosnmp.ObjectIdentifier), name), tlv(byte(pdu.Type), value)), nil
+}
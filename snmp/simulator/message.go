@@ -0,0 +1,315 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/soniah/gosnmp"
+)
+
+// SNMP message versions on the wire.
+const (
+	version1  = 0
+	version2c = 1
+	version3  = 3
+)
+
+// securityModelUSM is the User-based Security Model.
+const securityModelUSM = 3
+
+// pdu is a decoded SNMP PDU. For GetBulkRequest errorStatus is non-repeaters
+// and errorIndex is max-repetitions.
+type pdu struct {
+	tag         byte
+	requestID   int64
+	errorStatus int64
+	errorIndex  int64
+	varbinds    []gosnmp.SnmpPDU
+}
+
+// message is a decoded SNMP message, RFC 3412 for SNMPv3.
+type message struct {
+	version   int64
+	community string // SNMPv1 and SNMPv2c.
+
+	// SNMPv3 header and USM security parameters.
+	msgID          int64
+	msgMaxSize     int64
+	msgFlags       byte
+	securityModel  int64
+	engineID       []byte
+	engineBoots    int64
+	engineTime     int64
+	userName       string
+	authParameters []byte
+	authOffset     int // Offset of authParameters in the raw message.
+	privParameters []byte
+	encrypted      []byte // Encrypted scoped PDU. Nil if not encrypted.
+
+	// SNMPv3 scoped PDU.
+	contextEngineID []byte
+	contextName     string
+
+	pdu *pdu // Nil if the scoped PDU is still encrypted.
+}
+
+// decodeMessage decodes an SNMP message. An SNMPv3 message with privacy is
+// decoded up to the encrypted scoped PDU.
+func decodeMessage(raw []byte) (*message, error) {
+	reader, err := newBerReader(raw).enter(tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	msg := &message{}
+	if msg.version, err = reader.integer(); err != nil {
+		return nil, err
+	}
+
+	switch msg.version {
+	case version1, version2c:
+		community, err := reader.octets()
+		if err != nil {
+			return nil, err
+		}
+		msg.community = string(community)
+		msg.pdu, err = decodePDU(reader)
+		return msg, err
+	case version3:
+		return msg, decodeV3(reader, msg)
+	}
+	return nil, fmt.Errorf("Unsupported SNMP version %d", msg.version)
+}
+
+// decodeV3 decodes the rest of an SNMPv3 message after the version.
+func decodeV3(reader *berReader, msg *message) (err error) { // nolint: gocyclo
+	header, err := reader.enter(tagSequence)
+	if err != nil {
+		return err
+	}
+	if msg.msgID, err = header.integer(); err != nil {
+		return err
+	}
+	if msg.msgMaxSize, err = header.integer(); err != nil {
+		return err
+	}
+	flags, err := header.octets()
+	if err != nil {
+		return err
+	}
+	if len(flags) != 1 {
+		return fmt.Errorf("Bad msgFlags length %d", len(flags))
+	}
+	msg.msgFlags = flags[0]
+	if msg.securityModel, err = header.integer(); err != nil {
+		return err
+	}
+	if msg.securityModel != securityModelUSM {
+		return fmt.Errorf("Unsupported security model %d", msg.securityModel)
+	}
+
+	// The security parameters are a BER SEQUENCE in an OCTET STRING.
+	content, start, err := reader.expect(byte(gosnmp.OctetString))
+	if err != nil {
+		return err
+	}
+	outer := &berReader{data: reader.data, pos: start, end: start + len(content)}
+	usm, err := outer.enter(tagSequence)
+	if err != nil {
+		return err
+	}
+	if msg.engineID, err = usm.octets(); err != nil {
+		return err
+	}
+	if msg.engineBoots, err = usm.integer(); err != nil {
+		return err
+	}
+	if msg.engineTime, err = usm.integer(); err != nil {
+		return err
+	}
+	userName, err := usm.octets()
+	if err != nil {
+		return err
+	}
+	msg.userName = string(userName)
+	if msg.authParameters, msg.authOffset, err = usm.expect(byte(gosnmp.OctetString)); err != nil {
+		return err
+	}
+	if msg.privParameters, err = usm.octets(); err != nil {
+		return err
+	}
+
+	tag, data, start, err := reader.next()
+	if err != nil {
+		return err
+	}
+	switch tag {
+	case byte(gosnmp.OctetString):
+		msg.encrypted = data
+		return nil
+	case tagSequence:
+		return decodeScopedPDU(&berReader{data: reader.data, pos: start, end: start + len(data)}, msg)
+	}
+	return fmt.Errorf("Unexpected msgData tag 0x%02x", tag)
+}
+
+// decryptScopedPDU decrypts and decodes the encrypted scoped PDU of a message.
+func decryptScopedPDU(user *usmUser, msg *message) error {
+	plaintext, err := user.decrypt(msg.encrypted, msg.privParameters, msg.engineBoots, msg.engineTime)
+	if err != nil {
+		return err
+	}
+	// DES pads the plaintext, so only the first TLV is the scoped PDU.
+	scoped, err := newBerReader(plaintext).enter(tagSequence)
+	if err != nil {
+		return err
+	}
+	return decodeScopedPDU(scoped, msg)
+}
+
+// tlvHeaderLength is the length of the tag and length of a TLV.
+func tlvHeaderLength(contentLength int) int {
+	return 1 + len(encodeLength(contentLength))
+}
+
+// decodeScopedPDU decodes the content of a scoped PDU SEQUENCE.
+func decodeScopedPDU(scoped *berReader, msg *message) (err error) {
+	if msg.contextEngineID, err = scoped.octets(); err != nil {
+		return err
+	}
+	contextName, err := scoped.octets()
+	if err != nil {
+		return err
+	}
+	msg.contextName = string(contextName)
+	msg.pdu, err = decodePDU(scoped)
+	return err
+}
+
+// decodePDU decodes a PDU. Only the request PDUs and traps are accepted.
+func decodePDU(reader *berReader) (*pdu, error) {
+	tag, content, start, err := reader.next()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case tagGetRequest, tagGetNextRequest, tagSetRequest, tagGetBulkRequest,
+		tagInformRequest, tagTrapV2, tagResponse, tagReport:
+	default:
+		return nil, fmt.Errorf("Unsupported PDU type 0x%02x", tag)
+	}
+
+	body := &berReader{data: reader.data, pos: start, end: start + len(content)}
+	result := &pdu{tag: tag}
+	if result.requestID, err = body.integer(); err != nil {
+		return nil, err
+	}
+	if result.errorStatus, err = body.integer(); err != nil {
+		return nil, err
+	}
+	if result.errorIndex, err = body.integer(); err != nil {
+		return nil, err
+	}
+
+	varbinds, err := body.enter(tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	for !varbinds.done() {
+		varbind, err := varbinds.enter(tagSequence)
+		if err != nil {
+			return nil, err
+		}
+		name, _, err := varbind.expect(byte(gosnmp.ObjectIdentifier))
+		if err != nil {
+			return nil, err
+		}
+		oid, err := decodeOid(name)
+		if err != nil {
+			return nil, err
+		}
+		valueTag, value, _, err := varbind.next()
+		if err != nil {
+			return nil, err
+		}
+		decoded, err := decodeVarbind(oid, valueTag, value)
+		if err != nil {
+			return nil, err
+		}
+		result.varbinds = append(result.varbinds, decoded)
+	}
+	return result, nil
+}
+
+// encodePDU encodes a PDU.
+func encodePDU(p *pdu) ([]byte, error) {
+	var varbinds []byte
+	for _, varbind := range p.varbinds {
+		encoded, err := encodeVarbind(varbind)
+		if err != nil {
+			return nil, err
+		}
+		varbinds = append(varbinds, encoded...)
+	}
+	return tlv(p.tag,
+		encodeInteger(p.requestID),
+		encodeInteger(p.errorStatus),
+		encodeInteger(p.errorIndex),
+		tlv(tagSequence, varbinds)), nil
+}
+
+// encodeCommunityMessage encodes an SNMPv1 or SNMPv2c message.
+func encodeCommunityMessage(version int64, community string, p *pdu) ([]byte, error) {
+	encoded, err := encodePDU(p)
+	if err != nil {
+		return nil, err
+	}
+	return tlv(tagSequence, encodeInteger(version), encodeOctets([]byte(community)), encoded), nil
+}
+
+// encodeScopedPDU encodes an SNMPv3 scoped PDU.
+func encodeScopedPDU(contextEngineID []byte, contextName string, p *pdu) ([]byte, error) {
+	encoded, err := encodePDU(p)
+	if err != nil {
+		return nil, err
+	}
+	return tlv(tagSequence, encodeOctets(contextEngineID), encodeOctets([]byte(contextName)), encoded), nil
+}
+
+// encodeV3Message encodes an SNMPv3 message around msgData, which is either a
+// scoped PDU or an encrypted scoped PDU OCTET STRING. If the message is
+// authenticated, the authentication parameters are zeros and authOffset is
+// their offset for the digest.
+func encodeV3Message(msg *message, msgData []byte) (raw []byte, authOffset int) {
+	authParameters := []byte{}
+	if msg.msgFlags&flagAuth != 0 {
+		authParameters = make([]byte, authParametersLength)
+	}
+
+	header := tlv(tagSequence,
+		encodeInteger(msg.msgID),
+		encodeInteger(msg.msgMaxSize),
+		encodeOctets([]byte{msg.msgFlags}),
+		encodeInteger(securityModelUSM))
+
+	var usmPrefix []byte
+	usmPrefix = append(usmPrefix, encodeOctets(msg.engineID)...)
+	usmPrefix = append(usmPrefix, encodeInteger(msg.engineBoots)...)
+	usmPrefix = append(usmPrefix, encodeInteger(msg.engineTime)...)
+	usmPrefix = append(usmPrefix, encodeOctets([]byte(msg.userName))...)
+	authTLV := encodeOctets(authParameters)
+	privTLV := encodeOctets(msg.privParameters)
+	usm := tlv(tagSequence, usmPrefix, authTLV, privTLV)
+	securityParameters := encodeOctets(usm)
+
+	version := encodeInteger(version3)
+	raw = tlv(tagSequence, version, header, securityParameters, msgData)
+
+	// The authentication parameters follow the message header, the version,
+	// the msgGlobalData header, the security parameters OCTET STRING and
+	// SEQUENCE headers and the USM fields before them.
+	bodyLength := len(version) + len(header) + len(securityParameters) + len(msgData)
+	authOffset = tlvHeaderLength(bodyLength) + len(version) + len(header) +
+		tlvHeaderLength(len(usm)) +
+		tlvHeaderLength(len(usmPrefix)+len(authTLV)+len(privTLV)) +
+		len(usmPrefix) + tlvHeaderLength(len(authParameters))
+	return raw, authOffset
+}
@@ -0,0 +1,398 @@
+package simulator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/soniah/gosnmp"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultRampInterval is how often a ramp updates its value when the step has
+// no interval.
+const DefaultRampInterval = time.Second
+
+// Scenario is a script of changes to the agent's data over time, such as a
+// utility power failure on a UPS. Example:
+//
+//	name: power-loss
+//	steps:
+//	  - after: 10s
+//	    description: Utility power fails.
+//	    set:
+//	      - oid: UPS-MIB::upsOutputSource.0
+//	        value: "INTEGER: battery(5)"
+//	    trap:
+//	      oid: UPS-MIB::upsTrapOnBattery
+//	  - ramp:
+//	      - oid: .1.3.6.1.2.1.33.1.2.4.0
+//	        to: 20
+//	        over: 1m
+//	    interval: 5s
+type Scenario struct {
+	// Name is the name of the scenario.
+	Name string `yaml:"name"`
+	// Description describes the scenario.
+	Description string `yaml:"description"`
+	// Context is the context name or community of the data to change.
+	// Empty is public.
+	Context string `yaml:"context"`
+	// Repeat runs the steps again after the last one until the agent closes.
+	Repeat bool `yaml:"repeat"`
+	// Steps are run in order.
+	Steps []ScenarioStep `yaml:"steps"`
+}
+
+// ScenarioStep is a step of a Scenario. The actions run in the order of the
+// fields: mode, set, unset, trap, ramp.
+type ScenarioStep struct {
+	// After is the delay after the previous step.
+	After time.Duration `yaml:"after"`
+	// Description is logged when the step runs.
+	Description string `yaml:"description"`
+	// Mode changes how the agent answers requests, normal, timeout or
+	// auth-failure.
+	Mode string `yaml:"mode"`
+	// Set sets values.
+	Set []ScenarioValue `yaml:"set"`
+	// Unset removes OIDs, for example the rows of alarms that cleared.
+	Unset []string `yaml:"unset"`
+	// Trap sends a trap.
+	Trap *ScenarioTrap `yaml:"trap"`
+	// Ramp changes numeric values linearly. The step ends when the ramps do.
+	Ramp []ScenarioRamp `yaml:"ramp"`
+	// Interval is how often the ramps update their values.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// ScenarioValue is an OID and a value. The value is in snmpwalk syntax, for
+// example INTEGER: 5. A value without a type has the type of the existing
+// value of the OID.
+type ScenarioValue struct {
+	Oid   string `yaml:"oid"`
+	Value string `yaml:"value"`
+}
+
+// ScenarioTrap is an SNMPv2c trap.
+type ScenarioTrap struct {
+	Oid      string          `yaml:"oid"`
+	Varbinds []ScenarioValue `yaml:"varbinds"`
+}
+
+// ScenarioRamp changes the numeric value of an OID linearly from its current
+// value to a target over a duration.
+type ScenarioRamp struct {
+	Oid  string        `yaml:"oid"`
+	To   float64       `yaml:"to"`
+	Over time.Duration `yaml:"over"`
+}
+
+// LoadScenario loads a Scenario from a YAML file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse scenario %v: %v", path, err)
+	}
+	return scenario, nil
+}
+
+// ParseScenario parses a Scenario from YAML.
+func ParseScenario(data []byte) (*Scenario, error) {
+	scenario := &Scenario{}
+	if err := yaml.UnmarshalStrict(data, scenario); err != nil {
+		return nil, err
+	}
+	if scenario.Context == "" {
+		scenario.Context = DefaultCommunity
+	}
+	if len(scenario.Steps) == 0 {
+		return nil, fmt.Errorf("Scenario %v has no steps", scenario.Name)
+	}
+
+	for i, step := range scenario.Steps {
+		if step.Mode != "" {
+			if _, err := ParseMode(step.Mode); err != nil {
+				return nil, fmt.Errorf("Step %d: %v", i, err)
+			}
+		}
+		if step.Trap != nil && step.Trap.Oid == "" {
+			return nil, fmt.Errorf("Step %d: Trap has no oid", i)
+		}
+		for _, ramp := range step.Ramp {
+			if ramp.Oid == "" || ramp.Over <= 0 {
+				return nil, fmt.Errorf("Step %d: Ramp needs an oid and a positive over", i)
+			}
+		}
+		if step.Interval < 0 || step.After < 0 {
+			return nil, fmt.Errorf("Step %d: Negative duration", i)
+		}
+	}
+	return scenario, nil
+}
+
+// Run runs the scenario on an agent. It returns when the steps are done, or
+// when the agent is closed if the scenario repeats.
+func (scenario *Scenario) Run(agent *Agent) error {
+	for {
+		for i, step := range scenario.Steps {
+			if !wait(agent, step.After) {
+				return nil
+			}
+			log.Infof("Scenario %v step %d: %v", scenario.Name, i, step.Description)
+			if err := scenario.runStep(agent, step); err != nil {
+				return fmt.Errorf("Scenario %v step %d: %v", scenario.Name, i, err)
+			}
+		}
+		if !scenario.Repeat {
+			return nil
+		}
+	}
+}
+
+// runStep runs the actions of a step.
+func (scenario *Scenario) runStep(agent *Agent, step ScenarioStep) error { // nolint: gocyclo
+	if step.Mode != "" {
+		mode, err := ParseMode(step.Mode)
+		if err != nil {
+			return err
+		}
+		agent.SetMode(mode)
+	}
+
+	for _, value := range step.Set {
+		pdu, err := scenario.resolveValue(agent, value)
+		if err != nil {
+			return err
+		}
+		if err = agent.Set(scenario.Context, pdu); err != nil {
+			return err
+		}
+	}
+
+	for _, oid := range step.Unset {
+		resolved, err := core.ResolveOid(oid)
+		if err != nil {
+			return err
+		}
+		if err = agent.Delete(scenario.Context, resolved); err != nil {
+			return err
+		}
+	}
+
+	if step.Trap != nil {
+		trapOid, err := core.ResolveOid(step.Trap.Oid)
+		if err != nil {
+			return err
+		}
+		var varbinds []gosnmp.SnmpPDU
+		for _, value := range step.Trap.Varbinds {
+			pdu, err := scenario.resolveValue(agent, value)
+			if err != nil {
+				return err
+			}
+			varbinds = append(varbinds, pdu)
+		}
+		if err = agent.SendTrap(trapOid, varbinds); err != nil {
+			return err
+		}
+	}
+
+	if len(step.Ramp) > 0 {
+		return scenario.ramp(agent, step)
+	}
+	return nil
+}
+
+// ramp runs the ramps of a step.
+func (scenario *Scenario) ramp(agent *Agent, step ScenarioStep) error {
+	interval := step.Interval
+	if interval == 0 {
+		interval = DefaultRampInterval
+	}
+
+	type rampState struct {
+		pdu   gosnmp.SnmpPDU
+		from  float64
+		ramp  ScenarioRamp
+		steps int
+	}
+	var ramps []rampState
+	longest := 0
+	for _, ramp := range step.Ramp {
+		oid, err := core.ResolveOid(ramp.Oid)
+		if err != nil {
+			return err
+		}
+		pdu, found, err := agent.Get(scenario.Context, oid)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("Unable to ramp %v, it has no value", ramp.Oid)
+		}
+		from, err := numericValue(pdu)
+		if err != nil {
+			return err
+		}
+		steps := int(ramp.Over / interval)
+		if steps < 1 {
+			steps = 1
+		}
+		if steps > longest {
+			longest = steps
+		}
+		ramps = append(ramps, rampState{pdu: pdu, from: from, ramp: ramp, steps: steps})
+	}
+
+	for k := 1; k <= longest; k++ {
+		if !wait(agent, interval) {
+			return nil
+		}
+		for _, state := range ramps {
+			if k > state.steps {
+				continue
+			}
+			value := state.from + (state.ramp.To-state.from)*float64(k)/float64(state.steps)
+			pdu, err := setNumericValue(state.pdu, value)
+			if err != nil {
+				return err
+			}
+			if err = agent.Set(scenario.Context, pdu); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveValue resolves the OID and parses the value of a ScenarioValue.
+func (scenario *Scenario) resolveValue(agent *Agent, value ScenarioValue) (pdu gosnmp.SnmpPDU, err error) {
+	oid, err := core.ResolveOid(value.Oid)
+	if err != nil {
+		return pdu, err
+	}
+
+	if hasSnmpWalkType(value.Value) {
+		pdu, err = core.ParseSnmpWalkValue(value.Value)
+	} else {
+		existing, found, _ := agent.Get(scenario.Context, oid)
+		if !found {
+			return pdu, fmt.Errorf("%v has no value, the value needs a type", value.Oid)
+		}
+		pdu, err = parseValueAs(existing.Type, value.Value)
+	}
+	if err != nil {
+		return pdu, fmt.Errorf("%v: %v", value.Oid, err)
+	}
+	pdu.Name = oid
+	return pdu, nil
+}
+
+// snmpWalkTypes are the snmpwalk type names by ASN.1 type.
+var snmpWalkTypes = map[gosnmp.Asn1BER]string{
+	gosnmp.Integer:          "INTEGER",
+	gosnmp.OctetString:      "STRING",
+	gosnmp.Counter32:        "Counter32",
+	gosnmp.Gauge32:          "Gauge32",
+	gosnmp.Counter64:        "Counter64",
+	gosnmp.TimeTicks:        "Timeticks",
+	gosnmp.IPAddress:        "IpAddress",
+	gosnmp.ObjectIdentifier: "OID",
+}
+
+// hasSnmpWalkType returns true if the value starts with an snmpwalk type.
+func hasSnmpWalkType(value string) bool {
+	if strings.HasPrefix(value, "Hex-STRING:") {
+		return true
+	}
+	for _, name := range snmpWalkTypes {
+		if strings.HasPrefix(value, name+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseValueAs parses an untyped value as an ASN.1 type. Strings need not be
+// quoted.
+func parseValueAs(asn1Type gosnmp.Asn1BER, value string) (gosnmp.SnmpPDU, error) {
+	name, ok := snmpWalkTypes[asn1Type]
+	if !ok {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("Unable to set a value of type %v", asn1Type)
+	}
+	if asn1Type == gosnmp.OctetString && !strings.HasPrefix(value, "\"") {
+		return gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte(value)}, nil
+	}
+	return core.ParseSnmpWalkValue(name + ": " + value)
+}
+
+// numericValue gets the value of a numeric PDU as a float64.
+func numericValue(pdu gosnmp.SnmpPDU) (float64, error) {
+	switch value := pdu.Value.(type) {
+	case int:
+		return float64(value), nil
+	case uint:
+		return float64(value), nil
+	case uint32:
+		return float64(value), nil
+	case uint64:
+		return float64(value), nil
+	}
+	return 0, fmt.Errorf("%v value %v is not numeric", pdu.Name, pdu.Value)
+}
+
+// setNumericValue sets the value of a numeric PDU, rounded to an integer.
+func setNumericValue(pdu gosnmp.SnmpPDU, value float64) (gosnmp.SnmpPDU, error) {
+	rounded := strconv.FormatFloat(value, 'f', 0, 64)
+	switch pdu.Value.(type) {
+	case int:
+		i, err := strconv.ParseInt(rounded, 10, 64)
+		pdu.Value = int(i)
+		return pdu, err
+	case uint, uint32, uint64:
+		if value < 0 {
+			rounded = "0"
+		}
+		u, err := strconv.ParseUint(rounded, 10, 64)
+		switch pdu.Value.(type) {
+		case uint:
+			pdu.Value = uint(u)
+		case uint32:
+			pdu.Value = uint32(u)
+		default:
+			pdu.Value = u
+		}
+		return pdu, err
+	}
+	return pdu, fmt.Errorf("%v value %v is not numeric", pdu.Name, pdu.Value)
+}
+
+// wait waits for a duration. It returns false if the agent is closed first.
+func wait(agent *Agent, duration time.Duration) bool {
+	if duration <= 0 {
+		select {
+		case <-agent.Done():
+			return false
+		default:
+			return true
+		}
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-agent.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,64 @@
+// Package simtest starts SNMP simulator agents for integration tests, the way
+// net/http/httptest starts HTTP servers.
+package simtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/simulator"
+)
+
+// NewAgent starts an agent on a free localhost port serving the snmpwalk and
+// snmprec files in dataDir to simulator.DefaultUser and the given users. The
+// test is failed if the agent does not start. The caller closes the agent.
+func NewAgent(t testing.TB, dataDir string, users ...simulator.User) *simulator.Agent {
+	data, err := core.LoadDataDir(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to load simulator data: %v", err)
+	}
+	return NewAgentWithConfig(t, simulator.Config{
+		Data:  data,
+		Users: append([]simulator.User{simulator.DefaultUser}, users...),
+	})
+}
+
+// NewAgentWithConfig starts an agent on a free localhost port. The test is
+// failed if the agent does not start. The caller closes the agent.
+func NewAgentWithConfig(t testing.TB, config simulator.Config) *simulator.Agent {
+	agent, err := simulator.NewAgent(config)
+	if err != nil {
+		t.Fatalf("Failed to create simulator: %v", err)
+	}
+	if err = agent.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to start simulator: %v", err)
+	}
+	return agent
+}
+
+// DeviceConfig is the plugin configuration to talk to the agent as
+// simulator.DefaultUser in a context.
+func DeviceConfig(t testing.TB, agent *simulator.Agent, contextName string) *core.DeviceConfig {
+	user := simulator.DefaultUser
+	securityParameters, err := core.NewSecurityParameters(
+		user.Name,
+		user.AuthenticationProtocol,
+		user.AuthenticationPassphrase,
+		user.PrivacyProtocol,
+		user.PrivacyPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := core.NewDeviceConfig(
+		"v3",
+		agent.Addr().IP.String(),
+		uint16(agent.Addr().Port),
+		securityParameters,
+		contextName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Timeout = 2 * time.Second
+	return config
+}
@@ -0,0 +1,25 @@
+package simtest
+
+import (
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// TestNewAgent walks the simulator with the plugin's SNMP client.
+func TestNewAgent(t *testing.T) {
+	agent := NewAgent(t, "../../../emulator/data")
+	defer agent.Close() // nolint: errcheck
+
+	client, err := core.NewSnmpClient(DeviceConfig(t, agent, "public"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := client.Walk(".1.3.6.1.2.1.33.1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("Expected 6 results, got %d: %+v", len(results), results)
+	}
+}
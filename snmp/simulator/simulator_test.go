@@ -0,0 +1,372 @@
+package simulator
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soniah/gosnmp"
+	"github.com/vapor-ware/synse-snmp-plugin/emulator"
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// upsEstimatedChargeRemaining is an OID in the emulator data. The value is 100.
+const upsEstimatedChargeRemaining = ".1.3.6.1.2.1.33.1.2.4.0"
+
+// md5DesUser is a second user with the other protocols.
+var md5DesUser = User{
+	Name:                     "md5des",
+	AuthenticationProtocol:   core.MD5,
+	AuthenticationPassphrase: "md5passphrase",
+	PrivacyProtocol:          core.DES,
+	PrivacyPassphrase:        "despassphrase",
+}
+
+// startAgent starts an agent with the emulator data on a free port.
+func startAgent(t *testing.T, config Config) *Agent {
+	if config.Data == nil {
+		data, err := core.LoadDataDir(emulator.DataDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.Data = data
+	}
+	config.Users = append(config.Users, DefaultUser, md5DesUser)
+	agent, err := NewAgent(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = agent.Listen("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	return agent
+}
+
+// deviceConfig is the plugin configuration for a user of the agent.
+func deviceConfig(t *testing.T, agent *Agent, user User) *core.DeviceConfig {
+	securityParameters, err := core.NewSecurityParameters(
+		user.Name,
+		user.AuthenticationProtocol,
+		user.AuthenticationPassphrase,
+		user.PrivacyProtocol,
+		user.PrivacyPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := core.NewDeviceConfig("v3", "127.0.0.1", uint16(agent.Addr().Port), securityParameters, "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Timeout = time.Second
+	return config
+}
+
+// communityClient is a gosnmp client for SNMPv1 or SNMPv2c.
+func communityClient(t *testing.T, agent *Agent, version gosnmp.SnmpVersion) *gosnmp.GoSNMP {
+	client := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(agent.Addr().Port),
+		Community: "public",
+		Version:   version,
+		Timeout:   time.Second,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// TestBerVarbindRoundTrip checks that varbinds decode to what was encoded.
+func TestBerVarbindRoundTrip(t *testing.T) {
+	for _, pdu := range []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("name")},
+		{Name: ".1.3.6.1.2.1.2.1.0", Type: gosnmp.Integer, Value: -129},
+		{Name: ".1.3.6.1.2.1.2.1.0", Type: gosnmp.Integer, Value: 2147483647},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.534.2.12"},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(4294967295)},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(128)},
+		{Name: ".1.3.6.1.2.1.2.2.1.5.1", Type: gosnmp.Gauge32, Value: uint(0)},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)},
+		{Name: ".1.3.6.1.2.1.4.20.1.1.10.193.3.201", Type: gosnmp.IPAddress, Value: "10.193.3.201"},
+		{Name: ".1.3.6.1.4.1.99999.4294967296.1", Type: gosnmp.NoSuchInstance},
+	} {
+		encoded, err := encodeVarbind(pdu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		varbind, err := newBerReader(encoded).enter(tagSequence)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name, _, err := varbind.expect(byte(gosnmp.ObjectIdentifier))
+		if err != nil {
+			t.Fatal(err)
+		}
+		oid, err := decodeOid(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag, content, _, err := varbind.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := decodeVarbind(oid, tag, content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(pdu, decoded) {
+			t.Fatalf("Expected %#v, got %#v", pdu, decoded)
+		}
+	}
+}
+
+// TestAgentV3 gets and walks with the plugin's SNMPv3 transport for both
+// authentication and privacy protocol pairs.
+func TestAgentV3(t *testing.T) {
+	agent := startAgent(t, Config{})
+	defer agent.Close() // nolint: errcheck
+
+	data, err := core.LoadDataDir(emulator.DataDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedWalk := 0
+	for _, pdu := range data["public"] {
+		if strings.HasPrefix(pdu.Name, ".1.3.6.1.2.1.33.") {
+			expectedWalk++
+		}
+	}
+
+	for _, user := range []User{DefaultUser, md5DesUser} {
+		config := deviceConfig(t, agent, user)
+		pdus, err := core.UDPTransport{}.Get(config, []string{upsEstimatedChargeRemaining, ".1.3.6.1.2.1.33.1.2.4.1", ".1.3.6.1.2.1.99.1.0"})
+		if err != nil {
+			t.Fatalf("%v: %v", user.Name, err)
+		}
+		expected := []gosnmp.SnmpPDU{
+			{Name: upsEstimatedChargeRemaining, Type: gosnmp.Integer, Value: 100},
+			{Name: ".1.3.6.1.2.1.33.1.2.4.1", Type: gosnmp.NoSuchInstance},
+			{Name: ".1.3.6.1.2.1.99.1.0", Type: gosnmp.NoSuchObject},
+		}
+		if len(pdus) != len(expected) {
+			t.Fatalf("%v: Expected %d PDUs, got %d", user.Name, len(expected), len(pdus))
+		}
+		for i := range expected {
+			if pdus[i].Name != expected[i].Name || pdus[i].Type != expected[i].Type ||
+				(expected[i].Value != nil && !reflect.DeepEqual(pdus[i].Value, expected[i].Value)) {
+				t.Fatalf("%v: Expected %#v, got %#v", user.Name, expected[i], pdus[i])
+			}
+		}
+
+		walked, err := core.UDPTransport{}.Walk(config, ".1.3.6.1.2.1.33")
+		if err != nil {
+			t.Fatalf("%v: %v", user.Name, err)
+		}
+		if len(walked) != expectedWalk {
+			t.Fatalf("%v: Expected %d walked PDUs, got %d", user.Name, expectedWalk, len(walked))
+		}
+	}
+}
+
+// TestAgentV3Errors checks the USM reports for bad credentials and context.
+func TestAgentV3Errors(t *testing.T) {
+	agent := startAgent(t, Config{})
+	defer agent.Close() // nolint: errcheck
+
+	for name, change := range map[string]func(*core.DeviceConfig){
+		"unknown user":    func(config *core.DeviceConfig) { config.SecurityParameters.UserName = "nobody" },
+		"wrong password":  func(config *core.DeviceConfig) { config.SecurityParameters.AuthenticationPassphrase = "wrong" },
+		"unknown context": func(config *core.DeviceConfig) { config.ContextName = "nowhere" },
+	} {
+		config := deviceConfig(t, agent, DefaultUser)
+		change(config)
+		if _, err := (core.UDPTransport{}).Get(config, []string{upsEstimatedChargeRemaining}); err == nil {
+			t.Fatalf("%v: Expected an error", name)
+		}
+	}
+}
+
+// TestAgentCommunity checks SNMPv1 and SNMPv2c get, get next and bulk walk.
+func TestAgentCommunity(t *testing.T) {
+	agent := startAgent(t, Config{})
+	defer agent.Close() // nolint: errcheck
+
+	client := communityClient(t, agent, gosnmp.Version2c)
+	defer client.Conn.Close() // nolint: errcheck
+
+	packet, err := client.Get([]string{upsEstimatedChargeRemaining, ".1.3.6.1.2.1.99.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Variables[0].Value != 100 || packet.Variables[1].Type != gosnmp.NoSuchObject {
+		t.Fatalf("Unexpected get response %+v", packet.Variables)
+	}
+
+	packet, err = client.GetNext([]string{".1.3.6.1.2.1.33.1.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Variables[0].Name != ".1.3.6.1.2.1.33.1.2.3.0" {
+		t.Fatalf("Unexpected get next response %+v", packet.Variables)
+	}
+
+	walked, err := client.BulkWalkAll(".1.3.6.1.2.1.33.1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(walked) != 6 {
+		t.Fatalf("Expected 6 walked PDUs, got %d: %+v", len(walked), walked)
+	}
+
+	v1 := communityClient(t, agent, gosnmp.Version1)
+	defer v1.Conn.Close() // nolint: errcheck
+	packet, err = v1.Get([]string{".1.3.6.1.2.1.99.1.0"})
+	if err == nil && packet.Error != gosnmp.NoSuchName {
+		t.Fatalf("Expected noSuchName, got %v", packet.Error)
+	}
+}
+
+// TestAgentSet checks SET on writable and read only objects.
+func TestAgentSet(t *testing.T) {
+	agent := startAgent(t, Config{Writable: []string{".1.3.6.1.2.1.33.1.2"}})
+	defer agent.Close() // nolint: errcheck
+
+	client := communityClient(t, agent, gosnmp.Version2c)
+	defer client.Conn.Close() // nolint: errcheck
+
+	packet, err := client.Set([]gosnmp.SnmpPDU{{Name: upsEstimatedChargeRemaining, Type: gosnmp.Integer, Value: 42}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Error != gosnmp.NoError {
+		t.Fatalf("Expected no error, got %v", packet.Error)
+	}
+	pdu, _, _ := agent.Get("public", upsEstimatedChargeRemaining)
+	if pdu.Value != 42 {
+		t.Fatalf("Expected 42, got %v", pdu.Value)
+	}
+
+	for oid, expected := range map[string]gosnmp.SNMPError{
+		".1.3.6.1.2.1.33.1.4.1.0": gosnmp.NotWritable,
+		".1.3.6.1.2.1.33.1.2.9.0": gosnmp.NoCreation,
+	} {
+		packet, err = client.Set([]gosnmp.SnmpPDU{{Name: oid, Type: gosnmp.Integer, Value: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packet.Error != expected {
+			t.Fatalf("%v: Expected %v, got %v", oid, expected, packet.Error)
+		}
+	}
+}
+
+// TestAgentModes checks that the timeout and authentication failure modes fail
+// requests until the mode is normal again.
+func TestAgentModes(t *testing.T) {
+	agent := startAgent(t, Config{})
+	defer agent.Close() // nolint: errcheck
+	config := deviceConfig(t, agent, DefaultUser)
+	config.Timeout = 200 * time.Millisecond
+
+	for _, mode := range []Mode{ModeTimeout, ModeAuthFailure} {
+		agent.SetMode(mode)
+		if _, err := (core.UDPTransport{}).Get(config, []string{upsEstimatedChargeRemaining}); err == nil {
+			t.Fatalf("Mode %v: Expected an error", mode)
+		}
+		agent.SetMode(ModeNormal)
+		if _, err := (core.UDPTransport{}).Get(config, []string{upsEstimatedChargeRemaining}); err != nil {
+			t.Fatalf("Mode %v: %v", mode, err)
+		}
+	}
+}
+
+// TestScenario runs a short scenario and checks the values and the trap.
+func TestScenario(t *testing.T) {
+	traps, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer traps.Close() // nolint: errcheck
+
+	agent := startAgent(t, Config{TrapTargets: []string{traps.LocalAddr().String()}})
+	defer agent.Close() // nolint: errcheck
+
+	scenario, err := ParseScenario([]byte(`
+name: test
+steps:
+  - after: 10ms
+    set:
+      - oid: .1.3.6.1.2.1.33.1.4.1.0
+        value: "INTEGER: battery(5)"
+      - oid: .1.3.6.1.2.1.33.1.2.1.0
+        value: "INTEGER: 3"
+      - oid: .1.3.6.1.2.1.33.1.2.3.0
+        value: "20"
+    trap:
+      oid: .1.3.6.1.2.1.33.2.1
+      varbinds:
+        - oid: .1.3.6.1.2.1.33.1.2.3.0
+          value: "20"
+  - ramp:
+      - oid: .1.3.6.1.2.1.33.1.2.4.0
+        to: 50
+        over: 50ms
+    interval: 10ms
+  - unset:
+      - .1.3.6.1.2.1.33.1.2.1.0
+    mode: timeout
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = scenario.Run(agent); err != nil {
+		t.Fatal(err)
+	}
+
+	for oid, expected := range map[string]interface{}{
+		".1.3.6.1.2.1.33.1.4.1.0":   5,
+		".1.3.6.1.2.1.33.1.2.3.0":   20,
+		upsEstimatedChargeRemaining: 50,
+	} {
+		pdu, found, _ := agent.Get("public", oid)
+		if !found || pdu.Value != expected {
+			t.Fatalf("%v: Expected %v, got %v", oid, expected, pdu.Value)
+		}
+	}
+	if _, found, _ := agent.Get("public", ".1.3.6.1.2.1.33.1.2.1.0"); found {
+		t.Fatal("Expected upsBatteryStatus to be unset")
+	}
+	if agent.Mode() != ModeTimeout {
+		t.Fatalf("Expected mode timeout, got %v", agent.Mode())
+	}
+
+	buffer := make([]byte, 65535)
+	traps.SetReadDeadline(time.Now().Add(time.Second)) // nolint: errcheck
+	n, _, err := traps.ReadFromUDP(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := decodeMessage(buffer[:n])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.pdu.tag != tagTrapV2 || len(msg.pdu.varbinds) != 3 ||
+		msg.pdu.varbinds[1].Value != ".1.3.6.1.2.1.33.2.1" || msg.pdu.varbinds[2].Value != 20 {
+		t.Fatalf("Unexpected trap %+v", msg.pdu)
+	}
+}
+
+// TestEmulatorScenarios checks that the example scenarios parse.
+func TestEmulatorScenarios(t *testing.T) {
+	for _, name := range []string{"power-loss", "flaky-agent"} {
+		if _, err := LoadScenario("../../emulator/scenarios/" + name + ".yml"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ParseScenario([]byte("name: empty\n")); err == nil {
+		t.Fatal("Expected an error for a scenario without steps")
+	}
+}
@@ -0,0 +1,85 @@
+package simulator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultUser is the SNMPv3 user of the snmpsim emulator, which the plugin
+// tests and the example configuration use.
+var DefaultUser = User{
+	Name:                     "simulator",
+	AuthenticationProtocol:   core.SHA,
+	AuthenticationPassphrase: "auctoritas",
+	PrivacyProtocol:          core.AES,
+	PrivacyPassphrase:        "privatus",
+}
+
+// usersFile is the YAML users file. The keys are the same as in the plugin
+// configuration. Example:
+//
+//	users:
+//	  - userName: simulator
+//	    authenticationProtocol: SHA
+//	    authenticationPassphrase: auctoritas
+//	    privacyProtocol: AES
+//	    privacyPassphrase: privatus
+type usersFile struct {
+	Users []struct {
+		UserName                 string `yaml:"userName"`
+		AuthenticationProtocol   string `yaml:"authenticationProtocol"`
+		AuthenticationPassphrase string `yaml:"authenticationPassphrase"`
+		PrivacyProtocol          string `yaml:"privacyProtocol"`
+		PrivacyPassphrase        string `yaml:"privacyPassphrase"`
+	} `yaml:"users"`
+}
+
+// LoadUsers loads SNMPv3 users from a YAML file. The protocols are MD5, SHA
+// or none for authentication and DES, AES or none for privacy.
+func LoadUsers(path string) ([]User, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := usersFile{}
+	if err = yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("Failed to parse users %v: %v", path, err)
+	}
+
+	var users []User
+	for _, entry := range file.Users {
+		user := User{
+			Name:                     entry.UserName,
+			AuthenticationPassphrase: entry.AuthenticationPassphrase,
+			PrivacyPassphrase:        entry.PrivacyPassphrase,
+		}
+		switch strings.ToUpper(entry.AuthenticationProtocol) {
+		case "MD5":
+			user.AuthenticationProtocol = core.MD5
+		case "SHA":
+			user.AuthenticationProtocol = core.SHA
+		case "", "NONE":
+			user.AuthenticationProtocol = core.NoAuthentication
+		default:
+			return nil, fmt.Errorf("User %v has unsupported authentication protocol [%v]",
+				entry.UserName, entry.AuthenticationProtocol)
+		}
+		switch strings.ToUpper(entry.PrivacyProtocol) {
+		case "DES":
+			user.PrivacyProtocol = core.DES
+		case "AES":
+			user.PrivacyProtocol = core.AES
+		case "", "NONE":
+			user.PrivacyProtocol = core.NoPrivacy
+		default:
+			return nil, fmt.Errorf("User %v has unsupported privacy protocol [%v]",
+				entry.UserName, entry.PrivacyProtocol)
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
@@ -0,0 +1,231 @@
+package simulator
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/md5"  // nolint: gas
+	"crypto/sha1" // nolint: gas
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// This file contains the SNMPv3 User-based Security Model, RFC 3414, with the
+// AES privacy protocol from RFC 3826. The protocols match the ones the plugin
+// supports in core.SecurityParameters.
+
+// USM statistics OIDs reported to the client on security errors.
+const (
+	usmStatsUnsupportedSecLevels = ".1.3.6.1.6.3.15.1.1.1.0"
+	usmStatsNotInTimeWindows     = ".1.3.6.1.6.3.15.1.1.2.0"
+	usmStatsUnknownUserNames     = ".1.3.6.1.6.3.15.1.1.3.0"
+	usmStatsUnknownEngineIDs     = ".1.3.6.1.6.3.15.1.1.4.0"
+	usmStatsWrongDigests         = ".1.3.6.1.6.3.15.1.1.5.0"
+	usmStatsDecryptionErrors     = ".1.3.6.1.6.3.15.1.1.6.0"
+)
+
+// USM message flags.
+const (
+	flagAuth       = 0x01
+	flagPriv       = 0x02
+	flagReportable = 0x04
+)
+
+// authParametersLength is the length of the truncated HMAC, HMAC-MD5-96 and
+// HMAC-SHA-96.
+const authParametersLength = 12
+
+// timeWindow is the USM time window in seconds.
+const timeWindow = 150
+
+// User is an SNMPv3 user of the simulator.
+type User struct {
+	Name                     string
+	AuthenticationProtocol   core.AuthenticationProtocol // Zero is no authentication.
+	AuthenticationPassphrase string
+	PrivacyProtocol          core.PrivacyProtocol // Zero is no privacy.
+	PrivacyPassphrase        string
+}
+
+// usmUser is a User with the keys localized to the agent's engine ID.
+type usmUser struct {
+	User
+	authKey []byte
+	privKey []byte
+}
+
+// newUsmUser localizes the user's keys to the engine ID.
+func newUsmUser(user User, engineID []byte) (*usmUser, error) {
+	localized := &usmUser{User: user}
+
+	var hashFunc func() hash.Hash
+	switch user.AuthenticationProtocol {
+	case core.NoAuthentication, 0:
+		localized.AuthenticationProtocol = core.NoAuthentication
+		if user.PrivacyProtocol != core.NoPrivacy && user.PrivacyProtocol != 0 {
+			return nil, fmt.Errorf("User %v has privacy without authentication", user.Name)
+		}
+		localized.PrivacyProtocol = core.NoPrivacy
+		return localized, nil
+	case core.MD5:
+		hashFunc = md5.New
+	case core.SHA:
+		hashFunc = sha1.New
+	default:
+		return nil, fmt.Errorf("User %v has unsupported authentication protocol [%v]",
+			user.Name, user.AuthenticationProtocol)
+	}
+	localized.authKey = localizeKey(hashFunc, user.AuthenticationPassphrase, engineID)
+
+	switch user.PrivacyProtocol {
+	case core.NoPrivacy, 0:
+		localized.PrivacyProtocol = core.NoPrivacy
+	case core.DES, core.AES:
+		// The privacy key is localized with the authentication hash.
+		localized.privKey = localizeKey(hashFunc, user.PrivacyPassphrase, engineID)
+	default:
+		return nil, fmt.Errorf("User %v has unsupported privacy protocol [%v]",
+			user.Name, user.PrivacyProtocol)
+	}
+	return localized, nil
+}
+
+// localizeKey is the password to key algorithm, RFC 3414 appendix A.2.
+func localizeKey(hashFunc func() hash.Hash, passphrase string, engineID []byte) []byte {
+	h := hashFunc()
+	if len(passphrase) > 0 {
+		password := []byte(passphrase)
+		block := make([]byte, 64)
+		index := 0
+		for count := 0; count < 1048576; count += 64 {
+			for i := range block {
+				block[i] = password[index%len(password)]
+				index++
+			}
+			h.Write(block) // nolint: errcheck
+		}
+	}
+	ku := h.Sum(nil)
+
+	h = hashFunc()
+	h.Write(ku)       // nolint: errcheck
+	h.Write(engineID) // nolint: errcheck
+	h.Write(ku)       // nolint: errcheck
+	return h.Sum(nil)
+}
+
+// hashFunc returns the hash for the user's authentication protocol.
+func (user *usmUser) hashFunc() func() hash.Hash {
+	if user.AuthenticationProtocol == core.MD5 {
+		return md5.New
+	}
+	return sha1.New
+}
+
+// securityFlags returns the message flags for the user's security level.
+func (user *usmUser) securityFlags() byte {
+	var flags byte
+	if user.AuthenticationProtocol != core.NoAuthentication {
+		flags |= flagAuth
+	}
+	if user.PrivacyProtocol != core.NoPrivacy {
+		flags |= flagPriv
+	}
+	return flags
+}
+
+// digest computes the truncated HMAC of a whole message with the
+// authentication parameters zeroed.
+func (user *usmUser) digest(message []byte) []byte {
+	mac := hmac.New(user.hashFunc(), user.authKey)
+	mac.Write(message) // nolint: errcheck
+	return mac.Sum(nil)[:authParametersLength]
+}
+
+// decrypt decrypts a scoped PDU. boots and time are the values from the
+// message's security parameters.
+func (user *usmUser) decrypt(ciphertext []byte, salt []byte, boots int64, time int64) ([]byte, error) {
+	if len(salt) != 8 {
+		return nil, fmt.Errorf("Bad privacy parameters length %d", len(salt))
+	}
+
+	switch user.PrivacyProtocol {
+	case core.DES:
+		if len(ciphertext)%des.BlockSize != 0 || len(ciphertext) == 0 {
+			return nil, fmt.Errorf("DES ciphertext length %d is not a multiple of the block size", len(ciphertext))
+		}
+		block, err := des.NewCipher(user.privKey[:8])
+		if err != nil {
+			return nil, err
+		}
+		iv := make([]byte, des.BlockSize)
+		for i := range iv {
+			iv[i] = user.privKey[8+i] ^ salt[i]
+		}
+		plaintext := make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+		return plaintext, nil
+
+	case core.AES:
+		block, err := aes.NewCipher(user.privKey[:16])
+		if err != nil {
+			return nil, err
+		}
+		plaintext := make([]byte, len(ciphertext))
+		cipher.NewCFBDecrypter(block, aesIV(boots, time, salt)).XORKeyStream(plaintext, ciphertext)
+		return plaintext, nil
+	}
+	return nil, fmt.Errorf("User %v has no privacy protocol", user.Name)
+}
+
+// encrypt encrypts a scoped PDU. saltCounter is unique per message. The salt
+// to send in the privacy parameters is returned.
+func (user *usmUser) encrypt(plaintext []byte, saltCounter uint64, boots int64, time int64) (ciphertext []byte, salt []byte, err error) {
+	salt = make([]byte, 8)
+
+	switch user.PrivacyProtocol {
+	case core.DES:
+		// The salt is the engine boots and a counter, RFC 3414 section 8.1.1.1.
+		binary.BigEndian.PutUint32(salt[:4], uint32(boots))
+		binary.BigEndian.PutUint32(salt[4:], uint32(saltCounter))
+		block, err := des.NewCipher(user.privKey[:8])
+		if err != nil {
+			return nil, nil, err
+		}
+		iv := make([]byte, des.BlockSize)
+		for i := range iv {
+			iv[i] = user.privKey[8+i] ^ salt[i]
+		}
+		padded := plaintext
+		if len(padded)%des.BlockSize != 0 {
+			padded = append(append([]byte{}, plaintext...), make([]byte, des.BlockSize-len(plaintext)%des.BlockSize)...)
+		}
+		ciphertext = make([]byte, len(padded))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+		return ciphertext, salt, nil
+
+	case core.AES:
+		binary.BigEndian.PutUint64(salt, saltCounter)
+		block, err := aes.NewCipher(user.privKey[:16])
+		if err != nil {
+			return nil, nil, err
+		}
+		ciphertext = make([]byte, len(plaintext))
+		cipher.NewCFBEncrypter(block, aesIV(boots, time, salt)).XORKeyStream(ciphertext, plaintext)
+		return ciphertext, salt, nil
+	}
+	return nil, nil, fmt.Errorf("User %v has no privacy protocol", user.Name)
+}
+
+// aesIV is the AES initialization vector, RFC 3826 section 3.1.2.1.
+func aesIV(boots int64, time int64, salt []byte) []byte {
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint32(iv[:4], uint32(boots))
+	binary.BigEndian.PutUint32(iv[4:8], uint32(time))
+	copy(iv[8:], salt)
+	return iv
+}
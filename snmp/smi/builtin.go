@@ -0,0 +1,145 @@
+package smi
+
+// builtinModules are the SMI modules every MIB imports from. They are reduced
+// to the OID assignments and textual conventions, since the MACRO definitions
+// in the real modules are not needed to parse other modules.
+var builtinModules = []string{
+	snmpv2SMI,
+	snmpv2TC,
+	snmpv2CONF,
+}
+
+// snmpv2SMI is SNMPv2-SMI, RFC 2578.
+const snmpv2SMI = `
+SNMPv2-SMI DEFINITIONS ::= BEGIN
+
+ccitt            OBJECT IDENTIFIER ::= { 0 }
+iso              OBJECT IDENTIFIER ::= { 1 }
+joint-iso-ccitt  OBJECT IDENTIFIER ::= { 2 }
+
+org              OBJECT IDENTIFIER ::= { iso 3 }
+dod              OBJECT IDENTIFIER ::= { org 6 }
+internet         OBJECT IDENTIFIER ::= { dod 1 }
+directory        OBJECT IDENTIFIER ::= { internet 1 }
+mgmt             OBJECT IDENTIFIER ::= { internet 2 }
+mib-2            OBJECT IDENTIFIER ::= { mgmt 1 }
+transmission     OBJECT IDENTIFIER ::= { mib-2 10 }
+experimental     OBJECT IDENTIFIER ::= { internet 3 }
+private          OBJECT IDENTIFIER ::= { internet 4 }
+enterprises      OBJECT IDENTIFIER ::= { private 1 }
+security         OBJECT IDENTIFIER ::= { internet 5 }
+snmpV2           OBJECT IDENTIFIER ::= { internet 6 }
+snmpDomains      OBJECT IDENTIFIER ::= { snmpV2 1 }
+snmpProxys       OBJECT IDENTIFIER ::= { snmpV2 2 }
+snmpModules      OBJECT IDENTIFIER ::= { snmpV2 3 }
+
+zeroDotZero OBJECT-IDENTITY
+    STATUS      current
+    DESCRIPTION "A value used for null identifiers."
+    ::= { 0 0 }
+
+Integer32 ::= INTEGER (-2147483648..2147483647)
+IpAddress ::= [APPLICATION 0] IMPLICIT OCTET STRING (SIZE (4))
+Counter32 ::= [APPLICATION 1] IMPLICIT INTEGER (0..4294967295)
+Gauge32 ::= [APPLICATION 2] IMPLICIT INTEGER (0..4294967295)
+Unsigned32 ::= [APPLICATION 2] IMPLICIT INTEGER (0..4294967295)
+TimeTicks ::= [APPLICATION 3] IMPLICIT INTEGER (0..4294967295)
+Opaque ::= [APPLICATION 4] IMPLICIT OCTET STRING
+Counter64 ::= [APPLICATION 6] IMPLICIT INTEGER (0..18446744073709551615)
+
+END
+`
+
+// snmpv2TC is SNMPv2-TC, RFC 2579.
+const snmpv2TC = `
+SNMPv2-TC DEFINITIONS ::= BEGIN
+
+DisplayString ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "255a"
+    STATUS       current
+    SYNTAX       OCTET STRING (SIZE (0..255))
+
+PhysAddress ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "1x:"
+    STATUS       current
+    SYNTAX       OCTET STRING
+
+MacAddress ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "1x:"
+    STATUS       current
+    SYNTAX       OCTET STRING (SIZE (6))
+
+TruthValue ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       INTEGER { true(1), false(2) }
+
+TestAndIncr ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       INTEGER (0..2147483647)
+
+AutonomousType ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       OBJECT IDENTIFIER
+
+InstancePointer ::= TEXTUAL-CONVENTION
+    STATUS       obsolete
+    SYNTAX       OBJECT IDENTIFIER
+
+VariablePointer ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       OBJECT IDENTIFIER
+
+RowPointer ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       OBJECT IDENTIFIER
+
+RowStatus ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       INTEGER {
+                     active(1),
+                     notInService(2),
+                     notReady(3),
+                     createAndGo(4),
+                     createAndWait(5),
+                     destroy(6)
+                 }
+
+TimeStamp ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       TimeTicks
+
+TimeInterval ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       INTEGER (0..2147483647)
+
+DateAndTime ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "2d-1d-1d,1d:1d:1d.1d,1a1d:1d"
+    STATUS       current
+    SYNTAX       OCTET STRING (SIZE (8 | 11))
+
+StorageType ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       INTEGER {
+                     other(1),
+                     volatile(2),
+                     nonVolatile(3),
+                     permanent(4),
+                     readOnly(5)
+                 }
+
+TDomain ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       OBJECT IDENTIFIER
+
+TAddress ::= TEXTUAL-CONVENTION
+    STATUS       current
+    SYNTAX       OCTET STRING (SIZE (1..255))
+
+END
+`
+
+// snmpv2CONF is SNMPv2-CONF, RFC 2580. It only defines macros.
+const snmpv2CONF = `
+SNMPv2-CONF DEFINITIONS ::= BEGIN
+END
+`
@@ -0,0 +1,130 @@
+package smi
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of a lexical token in a MIB module.
+type tokenKind int
+
+const (
+	tokenEOF        tokenKind = iota
+	tokenIdentifier           // Names and keywords. Example: upsBatteryStatus, OBJECT-TYPE
+	tokenNumber               // Decimal number, possibly negative.
+	tokenString               // Quoted string, without the quotes.
+	tokenBinary               // 'xxxx'B or 'xxxx'H string. Text includes the quotes and suffix.
+	tokenSymbol               // Punctuation. Example: ::= { } ( ) , ; .. |
+)
+
+// token is a lexical token in a MIB module.
+type token struct {
+	Kind tokenKind
+	Text string
+	Line int // Line number for error messages.
+}
+
+// String returns the token for error messages.
+func (t token) String() string {
+	if t.Kind == tokenEOF {
+		return "end of file"
+	}
+	return fmt.Sprintf("%q at line %d", t.Text, t.Line)
+}
+
+// tokenize splits MIB module text into tokens. Comments are dropped.
+func tokenize(text string) (tokens []token, err error) {
+	runes := []rune(text)
+	line := 1
+	i := 0
+
+	for i < len(runes) {
+		c := runes[i]
+
+		switch {
+		case c == '\n':
+			line++
+			i++
+
+		case unicode.IsSpace(c):
+			i++
+
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			// A comment runs to the end of the line or the next "--".
+			i += 2
+			for i < len(runes) && runes[i] != '\n' {
+				if runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+
+		case c == '"':
+			start := line
+			i++
+			var builder bytes.Buffer
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\n' {
+					line++
+				}
+				builder.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("Unterminated string starting at line %d", start)
+			}
+			i++ // Closing quote.
+			tokens = append(tokens, token{Kind: tokenString, Text: builder.String(), Line: start})
+
+		case c == '\'':
+			j := i + 1
+			for j < len(runes) && runes[j] != '\'' {
+				j++
+			}
+			if j+1 >= len(runes) {
+				return nil, fmt.Errorf("Unterminated binary or hex string at line %d", line)
+			}
+			j += 2 // Closing quote and B or H suffix.
+			tokens = append(tokens, token{Kind: tokenBinary, Text: string(runes[i:j]), Line: line})
+			i = j
+
+		case c == ':' && i+2 < len(runes) && runes[i+1] == ':' && runes[i+2] == '=':
+			tokens = append(tokens, token{Kind: tokenSymbol, Text: "::=", Line: line})
+			i += 3
+
+		case c == '.' && i+1 < len(runes) && runes[i+1] == '.':
+			tokens = append(tokens, token{Kind: tokenSymbol, Text: "..", Line: line})
+			i += 2
+
+		case strings.ContainsRune("{}()[],;|.", c):
+			tokens = append(tokens, token{Kind: tokenSymbol, Text: string(c), Line: line})
+			i++
+
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{Kind: tokenNumber, Text: string(runes[i:j]), Line: line})
+			i = j
+
+		case unicode.IsLetter(c):
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) ||
+				runes[j] == '_' || (runes[j] == '-' && !(j+1 < len(runes) && runes[j+1] == '-'))) {
+				j++
+			}
+			tokens = append(tokens, token{Kind: tokenIdentifier, Text: string(runes[i:j]), Line: line})
+			i = j
+
+		default:
+			return nil, fmt.Errorf("Unexpected character %q at line %d", c, line)
+		}
+	}
+
+	tokens = append(tokens, token{Kind: tokenEOF, Line: line})
+	return tokens, nil
+}
@@ -0,0 +1,469 @@
+package smi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	logger "github.com/Sirupsen/logrus"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// baseTypes are the SMI types that are not textual conventions.
+var baseTypes = map[string]bool{
+	"INTEGER":           true,
+	"OCTET STRING":      true,
+	"OBJECT IDENTIFIER": true,
+	"BITS":              true,
+	"SEQUENCE":          true,
+	"SEQUENCE OF":       true,
+	"CHOICE":            true,
+	"Integer32":         true,
+	"Unsigned32":        true,
+	"Counter32":         true,
+	"Counter64":         true,
+	"Gauge32":           true,
+	"TimeTicks":         true,
+	"IpAddress":         true,
+	"Opaque":            true,
+	"Counter":           true, // SMIv1
+	"Gauge":             true, // SMIv1
+	"NetworkAddress":    true, // SMIv1
+}
+
+// Mibs is a set of MIB modules with their OIDs resolved. It translates between
+// names and OIDs. It is safe for concurrent use.
+type Mibs struct {
+	mutex   sync.RWMutex
+	modules map[string]*Module
+	trie    *core.OidTrie      // Resolved nodes by OID.
+	byName  map[string][]*Node // Resolved nodes by unqualified name.
+}
+
+// Mibs is the OidResolver for the plugin.
+var _ core.OidResolver = (*Mibs)(nil)
+
+// NewMibs creates a Mibs with the builtin SMI modules loaded.
+func NewMibs() *Mibs {
+	trie, _ := core.NewOidTrie(nil) // No error without oids.
+	mibs := &Mibs{
+		modules: map[string]*Module{},
+		trie:    trie,
+		byName:  map[string][]*Node{},
+	}
+
+	for _, text := range builtinModules {
+		modules, err := Parse(text)
+		if err != nil {
+			// Builtin modules are tested. This is a programming error.
+			panic(fmt.Sprintf("Failed to parse builtin MIB module: %v", err))
+		}
+		for _, module := range modules {
+			mibs.modules[module.Name] = module
+		}
+	}
+	if err := mibs.resolve(); err != nil {
+		panic(fmt.Sprintf("Failed to resolve builtin MIB modules: %v", err))
+	}
+	return mibs
+}
+
+// LoadDir loads every MIB file in a directory. Files that fail to parse are
+// reported in the error, but the rest are still loaded.
+func (mibs *Mibs) LoadDir(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var modules []*Module
+	var errs []string
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		parsed, err := parseFile(path)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		modules = append(modules, parsed...)
+	}
+
+	if err = mibs.AddModules(modules...); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Errors loading MIBs from %v: %v", dir, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// LoadFile loads the MIB modules in a file.
+func (mibs *Mibs) LoadFile(path string) error {
+	modules, err := parseFile(path)
+	if err != nil {
+		return err
+	}
+	return mibs.AddModules(modules...)
+}
+
+// parseFile parses a MIB file.
+func parseFile(path string) ([]*Module, error) {
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	for _, module := range modules {
+		module.File = path
+	}
+	return modules, nil
+}
+
+// AddModules adds parsed modules and resolves their OIDs. Modules with the
+// name of a builtin module are skipped. Modules that were loaded before are
+// replaced.
+func (mibs *Mibs) AddModules(modules ...*Module) error {
+	mibs.mutex.Lock()
+	defer mibs.mutex.Unlock()
+
+	for _, module := range modules {
+		if existing, ok := mibs.modules[module.Name]; ok && existing.File == "" {
+			logger.Debugf("Skipping MIB module %v in %v, using the builtin module", module.Name, module.File)
+			continue
+		}
+		mibs.modules[module.Name] = module
+	}
+	return mibs.resolve()
+}
+
+// resolve computes the OID of every node in every module and rebuilds the
+// indexes. Nodes whose parents can not be found are reported in the error and
+// left unresolved.
+func (mibs *Mibs) resolve() error {
+	// Deterministic order, builtin modules first so that they own the names
+	// of the well known OIDs.
+	names := make([]string, 0, len(mibs.modules))
+	for name := range mibs.modules {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := mibs.modules[names[i]], mibs.modules[names[j]]
+		if (a.File == "") != (b.File == "") {
+			return a.File == ""
+		}
+		return a.Name < b.Name
+	})
+
+	// Reset.
+	mibs.trie, _ = core.NewOidTrie(nil)
+	mibs.byName = map[string][]*Node{}
+	var pending []*Node
+	for _, name := range names {
+		module := mibs.modules[name]
+		for _, nodeName := range module.NodeNames() {
+			node := module.Nodes[nodeName]
+			node.Oid = nil
+			pending = append(pending, node)
+		}
+	}
+
+	// Parents can be defined in any order and in any module, so keep going
+	// until nothing more resolves.
+	for progress := true; progress && len(pending) > 0; {
+		progress = false
+		var unresolved []*Node
+		for _, node := range pending {
+			if mibs.resolveNode(node) {
+				progress = true
+				mibs.index(node)
+			} else {
+				unresolved = append(unresolved, node)
+			}
+		}
+		pending = unresolved
+	}
+
+	for _, name := range names {
+		mibs.resolveSyntax(mibs.modules[name])
+	}
+
+	if len(pending) > 0 {
+		var missing []string
+		for _, node := range pending {
+			missing = append(missing, fmt.Sprintf("%v (parent %v)", node.QualifiedName(), node.parent))
+		}
+		return fmt.Errorf("Unable to resolve OIDs for %v", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// resolveNode sets the OID of a node if its parent is resolved.
+func (mibs *Mibs) resolveNode(node *Node) bool {
+	var prefix []uint64
+	if node.parent != "" {
+		parent := mibs.lookupSymbol(mibs.modules[node.Module], node.parent)
+		if parent == nil || parent.Oid == nil {
+			return false
+		}
+		prefix = parent.Oid.ToSlice
+	}
+
+	slice := make([]uint64, 0, len(prefix)+len(node.subid))
+	slice = append(slice, prefix...)
+	slice = append(slice, node.subid...)
+	if len(slice) == 0 {
+		return false
+	}
+	node.Oid, _ = core.NewOidFromSlice(slice) // No error.
+	return true
+}
+
+// index adds a resolved node to the OID trie and name index. When two nodes
+// have the same OID the first one keeps it.
+func (mibs *Mibs) index(node *Node) {
+	if _, found := mibs.trie.Lookup(node.Oid); !found {
+		_ = mibs.trie.Set(node.Oid, node) // No error for a non-nil Oid.
+	}
+	mibs.byName[node.Name] = append(mibs.byName[node.Name], node)
+}
+
+// lookupSymbol finds the node for a name as seen from a module: the module's
+// own definitions first, then its imports, then any loaded module. The last
+// is for modules with missing imports, which are common in the wild.
+func (mibs *Mibs) lookupSymbol(module *Module, name string) *Node {
+	if node, ok := module.Nodes[name]; ok {
+		return node
+	}
+	if from, ok := module.Imports[name]; ok {
+		if imported, ok := mibs.modules[from]; ok {
+			if node, ok := imported.Nodes[name]; ok {
+				return node
+			}
+		}
+	}
+	if nodes := mibs.byName[name]; len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+// lookupTextualConvention finds a textual convention as seen from a module,
+// the same way as lookupSymbol.
+func (mibs *Mibs) lookupTextualConvention(module *Module, name string) *TextualConvention {
+	if tc, ok := module.TextualConventions[name]; ok {
+		return tc
+	}
+	if from, ok := module.Imports[name]; ok {
+		if imported, ok := mibs.modules[from]; ok {
+			if tc, ok := imported.TextualConventions[name]; ok {
+				return tc
+			}
+		}
+	}
+	for _, other := range mibs.modules {
+		if tc, ok := other.TextualConventions[name]; ok {
+			return tc
+		}
+	}
+	return nil
+}
+
+// resolveSyntax links OBJECT-TYPE syntax to textual conventions, registers
+// the textual conventions with display hints for formatting and registers
+// enumerated syntax in the enumeration catalog.
+func (mibs *Mibs) resolveSyntax(module *Module) {
+	for _, tc := range module.TextualConventions {
+		if tc.DisplayHint != "" && core.LookupTextualConvention(tc.Name) == nil {
+			_ = core.RegisterTextualConvention(&core.TextualConvention{
+				Name:        tc.Name,
+				DisplayHint: tc.DisplayHint,
+			})
+		}
+		if tc.Syntax != nil {
+			registerEnumeration(module.Name+"::"+tc.Name, tc.Syntax.Enums, tc.Syntax.Type == "BITS")
+		}
+	}
+
+	for _, node := range module.Nodes {
+		if node.Syntax == nil || baseTypes[node.Syntax.Type] {
+			continue
+		}
+		tc := mibs.lookupTextualConvention(module, node.Syntax.Type)
+		if tc == nil {
+			continue
+		}
+		node.TextualConvention = tc
+		node.Syntax.Module = tc.Module
+		if len(node.Syntax.Enums) == 0 && tc.Syntax != nil {
+			node.Syntax.Enums = tc.Syntax.Enums
+		}
+	}
+
+	for _, node := range module.Nodes {
+		if node.Kind != KindObjectType || node.Syntax == nil {
+			continue
+		}
+		bits := node.Syntax.Type == "BITS"
+		if tc := node.TextualConvention; tc != nil && tc.Syntax != nil {
+			bits = tc.Syntax.Type == "BITS"
+		}
+		registerEnumeration(node.QualifiedName(), node.Syntax.Enums, bits)
+	}
+}
+
+// registerEnumeration registers enumerated INTEGER or BITS syntax in the
+// enumeration catalog. Other syntax is ignored. MIBs replace enumerations
+// registered by the MIB implementations since they are authoritative.
+func registerEnumeration(name string, enums []NamedNumber, bits bool) {
+	if len(enums) == 0 {
+		return
+	}
+	enumeration := &core.Enumeration{
+		Name:   name,
+		Bits:   bits,
+		Labels: map[int64]string{},
+	}
+	for _, enum := range enums {
+		enumeration.Labels[enum.Value] = enum.Name
+	}
+	_ = core.RegisterEnumeration(enumeration) // Name is not empty.
+}
+
+// Module returns a loaded module by name, or nil.
+func (mibs *Mibs) Module(name string) *Module {
+	mibs.mutex.RLock()
+	defer mibs.mutex.RUnlock()
+	return mibs.modules[name]
+}
+
+// ModuleNames returns the names of the loaded modules, sorted.
+func (mibs *Mibs) ModuleNames() []string {
+	mibs.mutex.RLock()
+	defer mibs.mutex.RUnlock()
+	names := make([]string, 0, len(mibs.modules))
+	for name := range mibs.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Node finds a resolved node by name. The name may be qualified with the
+// module. Examples: UPS-MIB::upsBatteryStatus, upsBatteryStatus
+func (mibs *Mibs) Node(name string) (*Node, error) {
+	mibs.mutex.RLock()
+	defer mibs.mutex.RUnlock()
+	return mibs.node(name)
+}
+
+// node is Node without the lock.
+func (mibs *Mibs) node(name string) (*Node, error) {
+	moduleName := ""
+	if split := strings.Index(name, "::"); split >= 0 {
+		moduleName, name = name[:split], name[split+2:]
+	}
+
+	if moduleName != "" {
+		module, ok := mibs.modules[moduleName]
+		if !ok {
+			return nil, fmt.Errorf("Unknown MIB module %v", moduleName)
+		}
+		node, ok := module.Nodes[name]
+		if !ok || node.Oid == nil {
+			return nil, fmt.Errorf("Unknown object %v::%v", moduleName, name)
+		}
+		return node, nil
+	}
+
+	nodes := mibs.byName[name]
+	switch len(nodes) {
+	case 0:
+		return nil, fmt.Errorf("Unknown object %v", name)
+	case 1:
+		return nodes[0], nil
+	}
+	// The same name in several modules is fine as long as they agree.
+	for _, node := range nodes[1:] {
+		if node.Oid.ToString != nodes[0].Oid.ToString {
+			return nil, fmt.Errorf("Ambiguous object %v, qualify it with the module name", name)
+		}
+	}
+	return nodes[0], nil
+}
+
+// Resolve translates a symbolic OID such as UPS-MIB::upsBatteryStatus.0 or
+// upsInputVoltage.1 to a numeric OID. Numeric OIDs are returned as is.
+func (mibs *Mibs) Resolve(name string) (*core.Oid, error) {
+	if core.IsNumericOid(name) {
+		return core.NewOid(name)
+	}
+
+	// Split off the numeric instance suffix after the object name.
+	object, suffix := name, ""
+	start := strings.Index(name, "::") + 1 // 0 if there is no module.
+	if dot := strings.Index(name[start:], "."); dot >= 0 {
+		object, suffix = name[:start+dot], name[start+dot+1:]
+	}
+
+	mibs.mutex.RLock()
+	node, err := mibs.node(object)
+	mibs.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	slice := append([]uint64{}, node.Oid.ToSlice...)
+	if suffix != "" {
+		for _, segment := range strings.Split(suffix, ".") {
+			value, err := strconv.ParseUint(segment, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid instance %v in %v", suffix, name)
+			}
+			slice = append(slice, value)
+		}
+	}
+	return core.NewOidFromSlice(slice)
+}
+
+// Lookup finds the node that defines an OID, or the closest ancestor that is
+// defined, and the remaining instance suffix. found is false if no prefix of
+// the OID is known.
+func (mibs *Mibs) Lookup(oid string) (node *Node, suffix []uint64, found bool) {
+	parsed, err := core.NewOid(oid)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	mibs.mutex.RLock()
+	length, value, found := mibs.trie.LongestPrefix(parsed)
+	mibs.mutex.RUnlock()
+	if !found {
+		return nil, nil, false
+	}
+	return value.(*Node), parsed.ToSlice[length:], true
+}
+
+// Name translates a numeric OID to a symbolic one such as
+// UPS-MIB::upsBatteryStatus.0. OIDs that are not known are returned as is.
+func (mibs *Mibs) Name(oid string) string {
+	node, suffix, found := mibs.Lookup(oid)
+	if !found {
+		return oid
+	}
+	name := node.QualifiedName()
+	for _, segment := range suffix {
+		name += "." + strconv.FormatUint(segment, 10)
+	}
+	return name
+}
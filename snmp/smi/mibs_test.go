@@ -0,0 +1,276 @@
+package smi
+
+import (
+	"testing"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// loadTestMibs loads the MIBs shipped with the plugin.
+func loadTestMibs(t *testing.T) *Mibs {
+	mibs := NewMibs()
+	err := mibs.LoadDir("../../mibs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mibs
+}
+
+// TestResolve checks name to OID translation.
+func TestResolve(t *testing.T) {
+	mibs := loadTestMibs(t)
+
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{"UPS-MIB::upsBatteryStatus", ".1.3.6.1.2.1.33.1.2.1"},
+		{"UPS-MIB::upsBatteryStatus.0", ".1.3.6.1.2.1.33.1.2.1.0"},
+		{"upsInputVoltage.1", ".1.3.6.1.2.1.33.1.3.3.1.3.1"},
+		{"UPS-MIB::upsAlarmOnBattery", ".1.3.6.1.2.1.33.1.6.3.2"},
+		{"UPS-MIB::upsFullConfigGroup", ".1.3.6.1.2.1.33.3.2.3.9"},
+		{"SNMPv2-MIB::sysUpTime.0", ".1.3.6.1.2.1.1.3.0"},
+		{"SNMPv2-MIB::coldStart", ".1.3.6.1.6.3.1.1.5.1"},
+		{"enterprises", ".1.3.6.1.4.1"},
+		{".1.3.6.1.2.1.33.1.2.1.0", "1.3.6.1.2.1.33.1.2.1.0"}, // Numeric, as is.
+	}
+	for _, c := range cases {
+		oid, err := mibs.Resolve(c.name)
+		if err != nil {
+			t.Fatalf("%v: %v", c.name, err)
+		}
+		if oid.ToString != c.expected {
+			t.Fatalf("%v: expected %v, got %v", c.name, c.expected, oid.ToString)
+		}
+	}
+
+	for _, name := range []string{"upsNoSuchThing", "NO-SUCH-MIB::upsBatteryStatus", "upsBatteryStatus.x"} {
+		if _, err := mibs.Resolve(name); err == nil {
+			t.Fatalf("%v: expected error", name)
+		}
+	}
+}
+
+// TestName checks OID to name translation.
+func TestName(t *testing.T) {
+	mibs := loadTestMibs(t)
+
+	cases := []struct {
+		oid      string
+		expected string
+	}{
+		{".1.3.6.1.2.1.33.1.2.1.0", "UPS-MIB::upsBatteryStatus.0"},
+		{".1.3.6.1.2.1.33.1.4.4.1.2.1", "UPS-MIB::upsOutputVoltage.1"},
+		{".1.3.6.1.2.1.33", "UPS-MIB::upsMIB"},
+		{"1.3.6.1.2.1.1.5.0", "SNMPv2-MIB::sysName.0"},
+		{".1.3.6.1.4.1.534.1", "SNMPv2-SMI::enterprises.534.1"},
+		{".2.99", "SNMPv2-SMI::joint-iso-ccitt.99"},
+		{"not an oid", "not an oid"},
+	}
+	for _, c := range cases {
+		actual := mibs.Name(c.oid)
+		if actual != c.expected {
+			t.Fatalf("%v: expected %v, got %v", c.oid, c.expected, actual)
+		}
+	}
+}
+
+// TestNodeDetails checks syntax, units, index and textual conventions.
+func TestNodeDetails(t *testing.T) {
+	mibs := loadTestMibs(t)
+
+	node, err := mibs.Node("UPS-MIB::upsBatteryStatus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Kind != KindObjectType || node.Access != "read-only" || node.Status != "current" {
+		t.Fatalf("Unexpected upsBatteryStatus %+v", node)
+	}
+	enumeration := core.LookupEnumeration("UPS-MIB::upsBatteryStatus")
+	if enumeration == nil || enumeration.Label(3) != "batteryLow" || enumeration.Label(5) != core.DefaultUnknownLabel {
+		t.Fatalf("Unexpected enumeration %+v", enumeration)
+	}
+	if label, ok := node.EnumLabel(3); !ok || label != "batteryLow" {
+		t.Fatalf("Expected batteryLow, got %v", label)
+	}
+	if value, ok := node.EnumValue("batteryDepleted"); !ok || value != 4 {
+		t.Fatalf("Expected 4, got %v", value)
+	}
+	if _, ok := node.EnumLabel(5); ok {
+		t.Fatal("Expected no label for 5")
+	}
+
+	node, err = mibs.Node("upsBatteryVoltage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Units != "0.1 Volt DC" {
+		t.Fatalf("Expected units [0.1 Volt DC], got [%v]", node.Units)
+	}
+	if node.TextualConvention == nil || node.TextualConvention.Name != "NonNegativeInteger" ||
+		node.TextualConvention.DisplayHint != "d" {
+		t.Fatalf("Expected NonNegativeInteger, got %+v", node.TextualConvention)
+	}
+
+	node, err = mibs.Node("upsOutputPercentLoad")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(node.Syntax.Ranges) != 1 || node.Syntax.Ranges[0] != (Range{Min: 0, Max: 200}) {
+		t.Fatalf("Expected range 0..200, got %+v", node.Syntax.Ranges)
+	}
+
+	node, err = mibs.Node("upsIdentModel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.TextualConvention == nil || node.TextualConvention.Module != "SNMPv2-TC" ||
+		len(node.Syntax.Sizes) != 1 || node.Syntax.Sizes[0].Max != 63 {
+		t.Fatalf("Expected DisplayString (SIZE (0..63)), got %+v %+v", node.Syntax, node.TextualConvention)
+	}
+
+	node, err = mibs.Node("upsInputEntry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(node.Index) != 1 || node.Index[0] != "upsInputLineIndex" {
+		t.Fatalf("Expected INDEX { upsInputLineIndex }, got %v", node.Index)
+	}
+	node, err = mibs.Node("upsInputTable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !node.IsTable() || node.Syntax.SequenceOf != "UpsInputEntry" {
+		t.Fatalf("Expected SEQUENCE OF UpsInputEntry, got %+v", node.Syntax)
+	}
+}
+
+// TestParse checks parsing of constructs not in the shipped MIBs, including
+// SMIv1 and modules with missing imports.
+func TestParse(t *testing.T) {
+	text := `
+TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS enterprises FROM RFC1155-SMI
+        OBJECT-TYPE FROM RFC-1212
+        TRAP-TYPE FROM RFC-1215;
+
+-- A comment with test OBJECT IDENTIFIER ::= { not a definition }
+test          OBJECT IDENTIFIER ::= { enterprises 99999 }
+testObjects   OBJECT IDENTIFIER ::= { test 1 }
+
+testFlags OBJECT-TYPE
+    SYNTAX  BITS { alpha(0), beta(1), gamma(7) }
+    ACCESS  read-only
+    STATUS  mandatory
+    DEFVAL  { { alpha } }
+    ::= { testObjects 1 }
+
+testSigned OBJECT-TYPE
+    SYNTAX  INTEGER (-10..10 | 'FF'H)
+    ACCESS  read-write
+    STATUS  mandatory
+    DESCRIPTION "multi
+                line"
+    ::= { testObjects 2 }
+
+testTrap TRAP-TYPE
+    ENTERPRISE test
+    VARIABLES { testSigned }
+    ::= 3
+
+TestSequence ::= SEQUENCE { a INTEGER, b OCTET STRING }
+END
+`
+	modules, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mibs := NewMibs()
+	// RFC1155-SMI is not loaded. enterprises resolves from SNMPv2-SMI.
+	err = mibs.AddModules(modules...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := mibs.Node("TEST-MIB::testFlags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Oid.ToString != ".1.3.6.1.4.1.99999.1.1" || node.Syntax.Type != "BITS" || len(node.Syntax.Enums) != 3 {
+		t.Fatalf("Unexpected testFlags %+v %+v", node, node.Syntax)
+	}
+
+	// Enumerated syntax is in the enumeration catalog.
+	enumeration := core.LookupEnumeration("TEST-MIB::testFlags")
+	if enumeration == nil || !enumeration.Bits || enumeration.Label(7) != "gamma" {
+		t.Fatalf("Expected BITS enumeration TEST-MIB::testFlags, got %+v", enumeration)
+	}
+	enumeration = core.LookupEnumeration("SNMPv2-TC::TruthValue")
+	if enumeration == nil || enumeration.Bits || enumeration.Label(2) != "false" {
+		t.Fatalf("Expected enumeration SNMPv2-TC::TruthValue, got %+v", enumeration)
+	}
+
+	node, err = mibs.Node("testSigned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Range{{-10, 10}, {255, 255}}
+	if len(node.Syntax.Ranges) != 2 || node.Syntax.Ranges[0] != expected[0] || node.Syntax.Ranges[1] != expected[1] {
+		t.Fatalf("Expected ranges %v, got %v", expected, node.Syntax.Ranges)
+	}
+
+	node, err = mibs.Node("testTrap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Oid.ToString != ".1.3.6.1.4.1.99999.0.3" {
+		t.Fatalf("Expected trap OID .1.3.6.1.4.1.99999.0.3, got %v", node.Oid.ToString)
+	}
+
+	// Unresolvable parents are reported.
+	modules, err = Parse(`BAD-MIB DEFINITIONS ::= BEGIN
+orphan OBJECT IDENTIFIER ::= { noSuchParent 1 }
+END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = mibs.AddModules(modules...); err == nil {
+		t.Fatal("Expected error for unresolved parent")
+	}
+
+	// Syntax errors are reported.
+	if _, err = Parse(`BROKEN DEFINITIONS ::= BEGIN x OBJECT-TYPE SYNTAX INTEGER {`); err == nil {
+		t.Fatal("Expected error for truncated module")
+	}
+}
+
+// TestOidResolver checks symbolic OIDs through the core resolver functions
+// used by SnmpClient and the device dumps.
+func TestOidResolver(t *testing.T) {
+	core.SetOidResolver(nil)
+	if _, err := core.ResolveOid("UPS-MIB::upsBatteryStatus.0"); err == nil {
+		t.Fatal("Expected error resolving a name without MIBs")
+	}
+	if oid, err := core.ResolveOid("1.3.6.1.2.1.33.1.2.1.0"); err != nil || oid != ".1.3.6.1.2.1.33.1.2.1.0" {
+		t.Fatalf("Expected numeric OID with leading period, got %v, %v", oid, err)
+	}
+
+	core.SetOidResolver(loadTestMibs(t))
+	defer core.SetOidResolver(nil)
+
+	oid, err := core.ResolveOid("UPS-MIB::upsBatteryStatus.0")
+	if err != nil || oid != ".1.3.6.1.2.1.33.1.2.1.0" {
+		t.Fatalf("Expected .1.3.6.1.2.1.33.1.2.1.0, got %v, %v", oid, err)
+	}
+	if name := core.OidName(".1.3.6.1.2.1.33.1.4.4.1.2.1"); name != "UPS-MIB::upsOutputVoltage.1" {
+		t.Fatalf("Expected UPS-MIB::upsOutputVoltage.1, got %v", name)
+	}
+	display := core.OidDisplay(".1.3.6.1.2.1.33.1.2.1.0")
+	if display != "UPS-MIB::upsBatteryStatus.0 (.1.3.6.1.2.1.33.1.2.1.0)" {
+		t.Fatalf("Unexpected display %v", display)
+	}
+	if _, err = core.ResolveOid("UPS-MIB::noSuchObject.0"); err == nil {
+		t.Fatal("Expected error resolving an unknown name")
+	}
+}
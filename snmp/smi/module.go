@@ -0,0 +1,178 @@
+// Package smi parses SMIv2 MIB modules (RFC 2578, 2579, 2580) and resolves
+// between symbolic names and OIDs. SMIv1 modules are parsed on a best effort
+// basis since many vendor MIBs are still written that way.
+package smi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vapor-ware/synse-snmp-plugin/snmp/core"
+)
+
+// NodeKind is the macro or assignment that defined a node.
+type NodeKind string
+
+// Node kinds.
+const (
+	KindObjectIdentifier  NodeKind = "OBJECT IDENTIFIER"
+	KindModuleIdentity    NodeKind = "MODULE-IDENTITY"
+	KindObjectIdentity    NodeKind = "OBJECT-IDENTITY"
+	KindObjectType        NodeKind = "OBJECT-TYPE"
+	KindNotificationType  NodeKind = "NOTIFICATION-TYPE"
+	KindTrapType          NodeKind = "TRAP-TYPE"
+	KindObjectGroup       NodeKind = "OBJECT-GROUP"
+	KindNotificationGroup NodeKind = "NOTIFICATION-GROUP"
+	KindModuleCompliance  NodeKind = "MODULE-COMPLIANCE"
+	KindAgentCapabilities NodeKind = "AGENT-CAPABILITIES"
+)
+
+// NamedNumber is one label of an enumerated INTEGER or a bit of BITS.
+// Example: batteryNormal(2)
+type NamedNumber struct {
+	Name  string
+	Value int64
+}
+
+// Range is one range of a SYNTAX value or size restriction. Example: (0..100)
+// A single value has Min == Max.
+type Range struct {
+	Min int64
+	Max int64
+}
+
+// Syntax is the SYNTAX of an OBJECT-TYPE or TEXTUAL-CONVENTION.
+type Syntax struct {
+	// Type is the base type or textual convention name as written in the MIB.
+	// Examples: INTEGER, OCTET STRING, DisplayString, Counter32, BITS.
+	Type string
+	// Module is the module that defines Type for textual conventions, after
+	// resolution.
+	Module string
+	// Enums are the labels of an enumerated INTEGER or the bits of BITS.
+	Enums []NamedNumber
+	// Ranges are the value restrictions. Example: INTEGER (0..100)
+	Ranges []Range
+	// Sizes are the size restrictions. Example: OCTET STRING (SIZE (0..255))
+	Sizes []Range
+	// SequenceOf is the entry type name for tables. Example: UpsInputEntry
+	SequenceOf string
+}
+
+// TextualConvention is a TEXTUAL-CONVENTION defined in a MIB module.
+type TextualConvention struct {
+	Name        string
+	Module      string
+	DisplayHint string
+	Status      string
+	Description string
+	Syntax      *Syntax
+}
+
+// Node is a named OID defined in a MIB module.
+type Node struct {
+	Name        string   // Example: upsBatteryStatus
+	Module      string   // Example: UPS-MIB
+	Kind        NodeKind // Example: OBJECT-TYPE
+	Oid         *core.Oid
+	Syntax      *Syntax  // OBJECT-TYPE only.
+	Units       string   // Example: 0.1 Volt DC
+	Access      string   // Example: read-only
+	Status      string   // Example: current
+	Description string   //
+	Index       []string // Table entries only. Names of the index objects.
+	Augments    string   // Table entries only. Name of the augmented entry.
+
+	// TextualConvention is the textual convention of the syntax, if any, after
+	// resolution. Enums from the textual convention are merged into Syntax.
+	TextualConvention *TextualConvention
+
+	// parent and subid are the OID value as written in the module, before
+	// resolution. Example: { upsBattery 1 }
+	parent string
+	subid  []uint64
+}
+
+// QualifiedName returns the name with the module. Example: UPS-MIB::upsBatteryStatus
+func (node *Node) QualifiedName() string {
+	return node.Module + "::" + node.Name
+}
+
+// IsTable returns true for SEQUENCE OF objects.
+func (node *Node) IsTable() bool {
+	return node.Syntax != nil && node.Syntax.SequenceOf != ""
+}
+
+// EnumLabel returns the label for an enumerated value. ok is false if the
+// object has no label for the value.
+func (node *Node) EnumLabel(value int64) (label string, ok bool) {
+	if node.Syntax == nil {
+		return "", false
+	}
+	for _, enum := range node.Syntax.Enums {
+		if enum.Value == value {
+			return enum.Name, true
+		}
+	}
+	return "", false
+}
+
+// EnumValue returns the value for an enumeration label. ok is false if the
+// object has no such label.
+func (node *Node) EnumValue(label string) (value int64, ok bool) {
+	if node.Syntax == nil {
+		return 0, false
+	}
+	for _, enum := range node.Syntax.Enums {
+		if enum.Name == label {
+			return enum.Value, true
+		}
+	}
+	return 0, false
+}
+
+// Module is a parsed MIB module.
+type Module struct {
+	Name string // Example: UPS-MIB
+	File string // Path the module was loaded from. Empty for builtin modules.
+	// Imports maps each imported symbol to the module it is imported from.
+	Imports map[string]string
+	// Nodes are the OID assignments in the module by name.
+	Nodes map[string]*Node
+	// TextualConventions are the textual conventions in the module by name.
+	TextualConventions map[string]*TextualConvention
+	// Types are other type assignments in the module by name, such as
+	// SEQUENCE definitions for table entries and SMIv1 style type aliases.
+	Types map[string]*Syntax
+}
+
+// newModule creates an empty Module.
+func newModule(name string) *Module {
+	return &Module{
+		Name:               name,
+		Imports:            map[string]string{},
+		Nodes:              map[string]*Node{},
+		TextualConventions: map[string]*TextualConvention{},
+		Types:              map[string]*Syntax{},
+	}
+}
+
+// addNode adds a node to the module. Duplicates are an error.
+func (module *Module) addNode(node *Node) error {
+	if _, exists := module.Nodes[node.Name]; exists {
+		return fmt.Errorf("%v: duplicate definition of %v", module.Name, node.Name)
+	}
+	node.Module = module.Name
+	module.Nodes[node.Name] = node
+	return nil
+}
+
+// NodeNames returns the names of the nodes in the module, sorted.
+func (module *Module) NodeNames() []string {
+	names := make([]string, 0, len(module.Nodes))
+	for name := range module.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
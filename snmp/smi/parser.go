@@ -0,0 +1,603 @@
+package smi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// macros are the macros that assign an OID to a name.
+var macros = map[string]NodeKind{
+	"MODULE-IDENTITY":    KindModuleIdentity,
+	"OBJECT-IDENTITY":    KindObjectIdentity,
+	"OBJECT-TYPE":        KindObjectType,
+	"NOTIFICATION-TYPE":  KindNotificationType,
+	"TRAP-TYPE":          KindTrapType,
+	"OBJECT-GROUP":       KindObjectGroup,
+	"NOTIFICATION-GROUP": KindNotificationGroup,
+	"MODULE-COMPLIANCE":  KindModuleCompliance,
+	"AGENT-CAPABILITIES": KindAgentCapabilities,
+}
+
+// parser is a recursive descent parser over the tokens of a MIB file.
+type parser struct {
+	tokens []token
+	pos    int
+	module *Module // Module currently being parsed.
+}
+
+// Parse parses the text of a MIB file. A file normally contains one module,
+// but may contain several.
+func Parse(text string) (modules []*Module, err error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	for p.peek().Kind != tokenEOF {
+		module, err := p.parseModule()
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, module)
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("No MIB module found")
+	}
+	return modules, nil
+}
+
+// peek returns the next token without consuming it.
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// peekIs returns true if the next token has the given text.
+func (p *parser) peekIs(text string) bool {
+	t := p.tokens[p.pos]
+	return t.Kind != tokenEOF && t.Kind != tokenString && t.Text == text
+}
+
+// next consumes and returns the next token. EOF is never consumed.
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.Kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// expect consumes the next token, which must have the given text.
+func (p *parser) expect(text string) error {
+	t := p.next()
+	if t.Kind == tokenEOF || t.Kind == tokenString || t.Text != text {
+		return p.errorf("expected %q, got %v", text, t)
+	}
+	return nil
+}
+
+// expectKind consumes the next token, which must be of the given kind.
+func (p *parser) expectKind(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.Kind != kind {
+		return t, p.errorf("expected %v, got %v", what, t)
+	}
+	return t, nil
+}
+
+// errorf creates an error with the module name if known.
+func (p *parser) errorf(format string, args ...interface{}) error {
+	if p.module != nil {
+		return fmt.Errorf("%v: %v", p.module.Name, fmt.Sprintf(format, args...))
+	}
+	return fmt.Errorf(format, args...)
+}
+
+// skipBalanced skips a bracketed group starting at the next token, which must
+// be { ( or [.
+func (p *parser) skipBalanced() error {
+	open := p.next()
+	closeText := map[string]string{"{": "}", "(": ")", "[": "]"}[open.Text]
+	if open.Kind != tokenSymbol || closeText == "" {
+		return p.errorf("expected bracket, got %v", open)
+	}
+	depth := 1
+	for depth > 0 {
+		t := p.next()
+		switch {
+		case t.Kind == tokenEOF:
+			return p.errorf("unbalanced %q at line %d", open.Text, open.Line)
+		case t.Kind == tokenSymbol && t.Text == open.Text:
+			depth++
+		case t.Kind == tokenSymbol && t.Text == closeText:
+			depth--
+		}
+	}
+	return nil
+}
+
+// parseModule parses one module from name DEFINITIONS ::= BEGIN to END.
+func (p *parser) parseModule() (module *Module, err error) {
+	name, err := p.expectKind(tokenIdentifier, "module name")
+	if err != nil {
+		return nil, err
+	}
+	p.module = newModule(name.Text)
+
+	// Some SMIv1 modules have an OID after the name.
+	if p.peekIs("{") {
+		if err = p.skipBalanced(); err != nil {
+			return nil, err
+		}
+	}
+	for _, keyword := range []string{"DEFINITIONS", "::=", "BEGIN"} {
+		if err = p.expect(keyword); err != nil {
+			return nil, err
+		}
+	}
+
+	for !p.peekIs("END") {
+		if p.peek().Kind == tokenEOF {
+			return nil, p.errorf("missing END")
+		}
+		if err = p.parseStatement(); err != nil {
+			return nil, err
+		}
+	}
+	p.next() // END
+
+	module, p.module = p.module, nil
+	return module, nil
+}
+
+// parseStatement parses IMPORTS, EXPORTS or one assignment in a module body.
+func (p *parser) parseStatement() (err error) {
+	t := p.next()
+	if t.Kind != tokenIdentifier {
+		return p.errorf("expected name, got %v", t)
+	}
+
+	switch t.Text {
+	case "IMPORTS":
+		return p.parseImports()
+	case "EXPORTS":
+		for !p.peekIs(";") && p.peek().Kind != tokenEOF {
+			p.next()
+		}
+		return p.expect(";")
+	}
+
+	name := t.Text
+	next := p.peek()
+
+	switch {
+	case next.Text == "MACRO":
+		// Macro definitions in the SMI modules themselves. Skip to END.
+		for !p.peekIs("END") {
+			if p.next().Kind == tokenEOF {
+				return p.errorf("missing END for MACRO %v", name)
+			}
+		}
+		p.next()
+		return nil
+
+	case next.Text == "::=":
+		p.next()
+		return p.parseTypeAssignment(name)
+
+	case next.Text == "OBJECT":
+		p.next()
+		if err = p.expect("IDENTIFIER"); err != nil {
+			return err
+		}
+		if err = p.expect("::="); err != nil {
+			return err
+		}
+		node := &Node{Name: name, Kind: KindObjectIdentifier}
+		if node.parent, node.subid, err = p.parseOidValue(); err != nil {
+			return err
+		}
+		return p.module.addNode(node)
+	}
+
+	kind, ok := macros[next.Text]
+	if !ok {
+		return p.errorf("unsupported definition of %v: %v", name, next)
+	}
+	p.next()
+	return p.parseMacro(name, kind)
+}
+
+// parseImports parses the symbols of an IMPORTS statement up to the ;.
+func (p *parser) parseImports() error {
+	symbols := []string{}
+	for !p.peekIs(";") {
+		t := p.next()
+		switch {
+		case t.Kind == tokenEOF:
+			return p.errorf("missing ; after IMPORTS")
+		case t.Kind == tokenIdentifier && t.Text == "FROM":
+			from, err := p.expectKind(tokenIdentifier, "module name")
+			if err != nil {
+				return err
+			}
+			for _, symbol := range symbols {
+				p.module.Imports[symbol] = from.Text
+			}
+			symbols = []string{}
+		case t.Kind == tokenIdentifier:
+			symbols = append(symbols, t.Text)
+		case t.Kind == tokenSymbol && t.Text == ",":
+		default:
+			return p.errorf("unexpected %v in IMPORTS", t)
+		}
+	}
+	p.next() // ;
+	if len(symbols) > 0 {
+		return p.errorf("IMPORTS %v without FROM", symbols)
+	}
+	return nil
+}
+
+// parseTypeAssignment parses what follows "Name ::=", which is either a
+// TEXTUAL-CONVENTION or a plain type.
+func (p *parser) parseTypeAssignment(name string) (err error) {
+	if !p.peekIs("TEXTUAL-CONVENTION") {
+		syntax, err := p.parseType()
+		if err != nil {
+			return err
+		}
+		p.module.Types[name] = syntax
+		return nil
+	}
+	p.next()
+
+	tc := &TextualConvention{Name: name, Module: p.module.Name}
+	for tc.Syntax == nil {
+		t := p.next()
+		switch t.Text {
+		case "DISPLAY-HINT":
+			tc.DisplayHint, err = p.parseString()
+		case "STATUS":
+			tc.Status, err = p.parseIdentifier()
+		case "DESCRIPTION":
+			tc.Description, err = p.parseString()
+		case "REFERENCE":
+			_, err = p.parseString()
+		case "SYNTAX":
+			tc.Syntax, err = p.parseType()
+		default:
+			err = p.errorf("unexpected %v in TEXTUAL-CONVENTION %v", t, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	p.module.TextualConventions[name] = tc
+	return nil
+}
+
+// parseMacro parses the clauses of a macro invocation through the OID value.
+// Unknown clauses are skipped.
+func (p *parser) parseMacro(name string, kind NodeKind) (err error) {
+	node := &Node{Name: name, Kind: kind}
+	enterprise := ""
+
+	for !p.peekIs("::=") {
+		t := p.next()
+		if t.Kind == tokenEOF {
+			return p.errorf("missing ::= for %v", name)
+		}
+
+		// Compliance and capability statements repeat clauses such as SYNTAX
+		// and DESCRIPTION for other objects. Only keep the first of each.
+		switch t.Text {
+		case "SYNTAX":
+			var syntax *Syntax
+			if syntax, err = p.parseType(); err == nil && node.Syntax == nil {
+				node.Syntax = syntax
+			}
+		case "UNITS":
+			node.Units, err = p.parseString()
+		case "MAX-ACCESS", "ACCESS":
+			var access string
+			if access, err = p.parseIdentifier(); err == nil && node.Access == "" {
+				node.Access = access
+			}
+		case "STATUS":
+			var status string
+			if status, err = p.parseIdentifier(); err == nil && node.Status == "" {
+				node.Status = status
+			}
+		case "DESCRIPTION":
+			var description string
+			if description, err = p.parseString(); err == nil && node.Description == "" {
+				node.Description = description
+			}
+		case "INDEX":
+			node.Index, err = p.parseIndex()
+		case "AUGMENTS":
+			var augments []string
+			if augments, err = p.parseIndex(); err == nil && len(augments) > 0 {
+				node.Augments = augments[0]
+			}
+		case "ENTERPRISE":
+			enterprise, err = p.parseIdentifier()
+		default:
+			if t.Kind == tokenSymbol && (t.Text == "{" || t.Text == "(") {
+				p.pos-- // Let skipBalanced see the opening bracket.
+				err = p.skipBalanced()
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	p.next() // ::=
+
+	if kind == KindTrapType {
+		// SMIv1 traps map to { enterprise 0 specific-trap } in SMIv2, RFC 3584.
+		number, err := p.expectKind(tokenNumber, "trap number")
+		if err != nil {
+			return err
+		}
+		specific, err := strconv.ParseUint(number.Text, 10, 64)
+		if err != nil {
+			return p.errorf("invalid trap number %v", number)
+		}
+		node.parent, node.subid = enterprise, []uint64{0, specific}
+		return p.module.addNode(node)
+	}
+
+	if node.parent, node.subid, err = p.parseOidValue(); err != nil {
+		return err
+	}
+	return p.module.addNode(node)
+}
+
+// parseString consumes a quoted string.
+func (p *parser) parseString() (string, error) {
+	t, err := p.expectKind(tokenString, "string")
+	return t.Text, err
+}
+
+// parseIdentifier consumes an identifier.
+func (p *parser) parseIdentifier() (string, error) {
+	t, err := p.expectKind(tokenIdentifier, "identifier")
+	return t.Text, err
+}
+
+// parseIndex parses the object names of an INDEX or AUGMENTS clause.
+func (p *parser) parseIndex() (names []string, err error) {
+	if err = p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.peekIs("}") {
+		t := p.next()
+		switch {
+		case t.Kind == tokenIdentifier && t.Text == "IMPLIED":
+		case t.Kind == tokenIdentifier:
+			names = append(names, t.Text)
+		case t.Kind == tokenSymbol && t.Text == ",":
+		default:
+			return nil, p.errorf("unexpected %v in INDEX", t)
+		}
+	}
+	p.next() // }
+	return names, nil
+}
+
+// parseOidValue parses an OID value such as { upsBattery 1 } or
+// { iso org(3) dod(6) 1 }. The result is the name of the first component, if
+// any, and the numbers that follow it.
+func (p *parser) parseOidValue() (parent string, subid []uint64, err error) {
+	if err = p.expect("{"); err != nil {
+		return "", nil, err
+	}
+	first := true
+	for !p.peekIs("}") {
+		t := p.next()
+		switch t.Kind {
+		case tokenIdentifier:
+			if p.peekIs("(") {
+				// name(number). The number is what counts.
+				p.next()
+				number, err := p.expectKind(tokenNumber, "number")
+				if err != nil {
+					return "", nil, err
+				}
+				if err = p.expect(")"); err != nil {
+					return "", nil, err
+				}
+				value, err := strconv.ParseUint(number.Text, 10, 64)
+				if err != nil {
+					return "", nil, p.errorf("invalid OID component %v", number)
+				}
+				subid = append(subid, value)
+			} else if first {
+				parent = t.Text
+			} else {
+				return "", nil, p.errorf("unexpected %v in OID value", t)
+			}
+		case tokenNumber:
+			value, err := strconv.ParseUint(t.Text, 10, 64)
+			if err != nil {
+				return "", nil, p.errorf("invalid OID component %v", t)
+			}
+			subid = append(subid, value)
+		default:
+			return "", nil, p.errorf("unexpected %v in OID value", t)
+		}
+		first = false
+	}
+	p.next() // }
+	return parent, subid, nil
+}
+
+// parseType parses a type such as INTEGER { a(1), b(2) },
+// OCTET STRING (SIZE (0..255)), DisplayString or SEQUENCE OF UpsInputEntry.
+func (p *parser) parseType() (syntax *Syntax, err error) {
+	// Tags, as in [APPLICATION 1] IMPLICIT INTEGER.
+	if p.peekIs("[") {
+		if err = p.skipBalanced(); err != nil {
+			return nil, err
+		}
+		if p.peekIs("IMPLICIT") || p.peekIs("EXPLICIT") {
+			p.next()
+		}
+	}
+
+	t, err := p.expectKind(tokenIdentifier, "type")
+	if err != nil {
+		return nil, err
+	}
+	syntax = &Syntax{Type: t.Text}
+
+	switch t.Text {
+	case "OCTET":
+		if err = p.expect("STRING"); err != nil {
+			return nil, err
+		}
+		syntax.Type = "OCTET STRING"
+	case "OBJECT":
+		if err = p.expect("IDENTIFIER"); err != nil {
+			return nil, err
+		}
+		syntax.Type = "OBJECT IDENTIFIER"
+	case "SEQUENCE":
+		if p.peekIs("OF") {
+			p.next()
+			if syntax.SequenceOf, err = p.parseIdentifier(); err != nil {
+				return nil, err
+			}
+			syntax.Type = "SEQUENCE OF"
+			return syntax, nil
+		}
+		return syntax, p.skipBalanced()
+	case "CHOICE":
+		return syntax, p.skipBalanced()
+	}
+
+	if p.peekIs("{") {
+		syntax.Enums, err = p.parseNamedNumbers()
+		return syntax, err
+	}
+	if p.peekIs("(") {
+		p.next()
+		if p.peekIs("SIZE") {
+			p.next()
+			if err = p.expect("("); err != nil {
+				return nil, err
+			}
+			if syntax.Sizes, err = p.parseRanges(); err != nil {
+				return nil, err
+			}
+			err = p.expect(")")
+		} else {
+			syntax.Ranges, err = p.parseRanges()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return syntax, nil
+}
+
+// parseNamedNumbers parses { name(number), ... } for enumerations and BITS.
+func (p *parser) parseNamedNumbers() (enums []NamedNumber, err error) {
+	if err = p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.peekIs("}") {
+		name, err := p.parseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect("("); err != nil {
+			return nil, err
+		}
+		number, err := p.expectKind(tokenNumber, "number")
+		if err != nil {
+			return nil, err
+		}
+		value, err := strconv.ParseInt(number.Text, 10, 64)
+		if err != nil {
+			return nil, p.errorf("invalid value %v for %v", number, name)
+		}
+		if err = p.expect(")"); err != nil {
+			return nil, err
+		}
+		enums = append(enums, NamedNumber{Name: name, Value: value})
+		if p.peekIs(",") {
+			p.next()
+		}
+	}
+	p.next() // }
+	return enums, nil
+}
+
+// parseRanges parses value ranges up to and including the closing ).
+// Example: 0..100 | 200
+func (p *parser) parseRanges() (ranges []Range, err error) {
+	for {
+		low, err := p.parseRangeValue()
+		if err != nil {
+			return nil, err
+		}
+		r := Range{Min: low, Max: low}
+		if p.peekIs("..") {
+			p.next()
+			if r.Max, err = p.parseRangeValue(); err != nil {
+				return nil, err
+			}
+		}
+		ranges = append(ranges, r)
+
+		if !p.peekIs("|") {
+			break
+		}
+		p.next()
+	}
+	return ranges, p.expect(")")
+}
+
+// parseRangeValue parses one end of a range. Values beyond int64 are clamped.
+func (p *parser) parseRangeValue() (int64, error) {
+	t := p.next()
+	switch t.Kind {
+	case tokenNumber:
+		value, err := strconv.ParseInt(t.Text, 10, 64)
+		if err == nil {
+			return value, nil
+		}
+		if _, err = strconv.ParseUint(t.Text, 10, 64); err == nil {
+			return math.MaxInt64, nil
+		}
+	case tokenBinary:
+		digits := strings.Trim(t.Text[:len(t.Text)-1], "'")
+		base := 16
+		if strings.HasSuffix(strings.ToUpper(t.Text), "B") {
+			base = 2
+		}
+		if digits == "" {
+			return 0, nil
+		}
+		value, err := strconv.ParseUint(digits, base, 64)
+		if err == nil {
+			if value > math.MaxInt64 {
+				return math.MaxInt64, nil
+			}
+			return int64(value), nil
+		}
+	case tokenIdentifier:
+		switch t.Text {
+		case "MIN":
+			return math.MinInt64, nil
+		case "MAX":
+			return math.MaxInt64, nil
+		}
+	}
+	return 0, p.errorf("invalid range value %v", t)
+}